@@ -0,0 +1,158 @@
+package buf
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/okneniz/cliche/node"
+)
+
+const (
+	// checkpointStep - count of runes between saved byte offsets
+	checkpointStep = 64
+
+	// RawByteBase - invalid byte X is decoded as rune RawByteBase + X
+	// in raw mode (like surrogateescape in Python),
+	// runes in this range never decoded from valid UTF-8
+	RawByteBase = rune(0xDC00)
+)
+
+// BytesBuffer - input which decode UTF-8 runes from bytes on demand,
+// without converting whole text to runes.
+//
+// Invalid bytes decoded as utf8.RuneError (U+FFFD)
+// or as RawByteBase + byte in raw mode.
+type BytesBuffer struct {
+	data       []byte
+	size       int  // count of runes
+	singleByte bool // every rune is one byte, rune index is byte offset
+	raw        bool
+
+	checkpoints  []int // byte offsets of every checkpointStep rune
+	cursorIdx    int   // index of last decoded rune
+	cursorOffset int   // byte offset of last decoded rune
+
+	lines []int // indexes of first runes of lines, calculated on demand
+}
+
+// NewBytesBuffer - make buffer which decode UTF-8 runes from data
+func NewBytesBuffer(data []byte, raw bool) *BytesBuffer {
+	b := new(BytesBuffer)
+	b.data = data
+	b.size = utf8.RuneCount(data)
+	b.singleByte = b.size == len(data)
+	b.raw = raw
+	b.checkpoints = []int{0}
+	return b
+}
+
+func (b *BytesBuffer) ReadAt(idx int) rune {
+	r, _ := b.decode(b.offsetOf(idx))
+	return r
+}
+
+func (b *BytesBuffer) Size() int {
+	return b.size
+}
+
+func (b *BytesBuffer) Position() int {
+	return b.cursorIdx
+}
+
+// Locate - return position of rune in source bytes
+func (b *BytesBuffer) Locate(idx int) node.Position {
+	if b.lines == nil {
+		b.index()
+	}
+
+	line := sort.Search(len(b.lines), func(i int) bool {
+		return b.lines[i] > idx
+	})
+
+	return node.Position{
+		Offset: b.offsetOf(idx),
+		Line:   line,
+		Column: idx - b.lines[line-1] + 1,
+	}
+}
+
+// SubString - return source bytes of runes from-to (inclusive) as string,
+// invalid bytes are kept as is
+func (b *BytesBuffer) SubString(from, to int) string {
+	return string(b.data[b.offsetOf(from):b.offsetOf(to+1)])
+}
+
+func (b *BytesBuffer) String() string {
+	return fmt.Sprintf("BytesBuffer(size=%d, bytes=%d, raw=%v)", b.size, len(b.data), b.raw)
+}
+
+func (b *BytesBuffer) decode(offset int) (rune, int) {
+	if offset >= len(b.data) {
+		return utf8.RuneError, 0
+	}
+
+	if x := b.data[offset]; x < utf8.RuneSelf {
+		return rune(x), 1
+	}
+
+	r, size := utf8.DecodeRune(b.data[offset:])
+	if r == utf8.RuneError && size == 1 && b.raw {
+		return RawByteBase + rune(b.data[offset]), 1
+	}
+
+	return r, size
+}
+
+// offsetOf - return byte offset of rune,
+// decode from nearest checkpoint or from last decoded rune
+func (b *BytesBuffer) offsetOf(idx int) int {
+	if b.singleByte {
+		return idx
+	}
+
+	cp := idx / checkpointStep
+	b.extendCheckpoints(cp)
+
+	current, offset := cp*checkpointStep, b.checkpoints[cp]
+	if b.cursorIdx <= idx && b.cursorIdx > current {
+		current, offset = b.cursorIdx, b.cursorOffset
+	}
+
+	for ; current < idx; current++ {
+		_, size := b.decode(offset)
+		offset += size
+	}
+
+	b.cursorIdx = idx
+	b.cursorOffset = offset
+
+	return offset
+}
+
+func (b *BytesBuffer) extendCheckpoints(cp int) {
+	for len(b.checkpoints) <= cp {
+		offset := b.checkpoints[len(b.checkpoints)-1]
+
+		for i := 0; i < checkpointStep; i++ {
+			_, size := b.decode(offset)
+			offset += size
+		}
+
+		b.checkpoints = append(b.checkpoints, offset)
+	}
+}
+
+func (b *BytesBuffer) index() {
+	b.lines = []int{0}
+
+	idx := 0
+	for offset := 0; offset < len(b.data); idx++ {
+		r, size := b.decode(offset)
+		offset += size
+
+		if r == '\n' {
+			b.lines = append(b.lines, idx+1)
+		}
+	}
+}
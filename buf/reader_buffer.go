@@ -0,0 +1,188 @@
+package buf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/okneniz/cliche/node"
+)
+
+// ReaderBuffer - input which read runes from io.Reader on demand
+// and keep in memory only window around current position.
+//
+// Indexes are absolute (from the begining of reader).
+// Window include history runes before current position
+// and lookahead runes after it, until reader is ended
+// the end of window is the end of input for scanner,
+// so reading of the last rune of window is reported by Overflow.
+type ReaderBuffer struct {
+	reader    *bufio.Reader
+	data      []rune
+	offset    int // absolute index of data[0]
+	position  int
+	history   int
+	lookahead int
+	eof       bool
+	err       error
+	overflow  bool // the last rune of window was read before the end of reader or runes before window were required
+
+	offsets   []int // byte offsets of runes in window
+	bytes     int   // count of read bytes
+	lines     []int // absolute indexes of first runes of lines
+	firstLine int   // number of line which start in lines[0]
+}
+
+var _ node.Window = new(ReaderBuffer)
+
+// NewReaderBuffer - make buffer which read runes from r
+func NewReaderBuffer(r io.Reader, history, lookahead int) *ReaderBuffer {
+	b := new(ReaderBuffer)
+	b.reader = bufio.NewReader(r)
+	b.data = make([]rune, 0, history+lookahead)
+	b.history = history
+	b.lookahead = lookahead
+	b.offsets = make([]int, 0, history+lookahead)
+	b.lines = []int{0}
+	b.firstLine = 1
+	return b
+}
+
+// Slide - move window to position: read runes until position + lookahead
+// and forget runes before position - history,
+// return false if position is after the end of reader
+func (b *ReaderBuffer) Slide(pos int) bool {
+	if pos < b.position {
+		panic(fmt.Sprintf("can't slide window back from %d to %d", b.position, pos))
+	}
+
+	b.position = pos
+
+	for !b.eof && b.Size() < pos+b.lookahead {
+		r, size, err := b.reader.ReadRune()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				b.err = err
+			}
+
+			b.eof = true
+			break
+		}
+
+		b.data = append(b.data, r)
+		b.offsets = append(b.offsets, b.bytes)
+		b.bytes += size
+
+		if r == '\n' {
+			b.lines = append(b.lines, b.Size())
+		}
+	}
+
+	// to know that the end of window is the end of reader
+	if !b.eof {
+		if _, err := b.reader.Peek(1); err != nil {
+			if !errors.Is(err, io.EOF) {
+				b.err = err
+			}
+
+			b.eof = true
+		}
+	}
+
+	// compact only when half of window is outdated
+	// to avoid copying on every step
+	if outdated := pos - b.history - b.offset; outdated > 0 && outdated >= len(b.data)/2 {
+		size := copy(b.data, b.data[outdated:])
+		b.data = b.data[:size]
+		b.offset += outdated
+
+		size = copy(b.offsets, b.offsets[outdated:])
+		b.offsets = b.offsets[:size]
+
+		// keep line of the first rune in window
+		line := b.lineOf(b.offset)
+		size = copy(b.lines, b.lines[line:])
+		b.lines = b.lines[:size]
+		b.firstLine += line
+	}
+
+	return b.err == nil && pos <= b.Size()
+}
+
+// ReadAt - read rune by absolute index, panic if index out of window
+func (b *ReaderBuffer) ReadAt(idx int) rune {
+	if !b.eof && idx == b.Size()-1 {
+		b.overflow = true
+	}
+
+	return b.data[idx-b.offset]
+}
+
+// Size - return absolute index of the end of window,
+// it's the end of input only after the end of reader
+func (b *ReaderBuffer) Size() int {
+	return b.offset + len(b.data)
+}
+
+// Offset - return absolute index of the first rune in window
+func (b *ReaderBuffer) Offset() int {
+	return b.offset
+}
+
+// Overflow - return true if the last rune of window was read
+// before the end of reader, so matches could be cut by the end of window,
+// or runes before the beginning of window were required (see MarkOverflow)
+func (b *ReaderBuffer) Overflow() bool {
+	return b.overflow
+}
+
+// MarkOverflow - report that runes before the beginning of window were required,
+// like by look-behinds of any length
+func (b *ReaderBuffer) MarkOverflow() {
+	b.overflow = true
+}
+
+// Position - return current position of window
+func (b *ReaderBuffer) Position() int {
+	return b.position
+}
+
+// Locate - return position of rune in source text, panic if index out of window
+func (b *ReaderBuffer) Locate(idx int) node.Position {
+	offset := b.bytes
+	if idx < b.Size() {
+		offset = b.offsets[idx-b.offset]
+	}
+
+	line := b.lineOf(idx)
+
+	return node.Position{
+		Offset: offset,
+		Line:   b.firstLine + line,
+		Column: idx - b.lines[line] + 1,
+	}
+}
+
+// lineOf - return index of line in lines which include rune
+func (b *ReaderBuffer) lineOf(idx int) int {
+	return sort.Search(len(b.lines), func(i int) bool {
+		return b.lines[i] > idx
+	}) - 1
+}
+
+// Err - return error of reading (except io.EOF)
+func (b *ReaderBuffer) Err() error {
+	return b.err
+}
+
+func (b *ReaderBuffer) String() string {
+	return fmt.Sprintf(
+		"ReaderBuffer(offset=%d, position=%d, size=%d, eof=%v)",
+		b.offset,
+		b.position,
+		b.Size(),
+		b.eof,
+	)
+}
@@ -2,7 +2,9 @@ package buf
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/okneniz/cliche/node"
 	c "github.com/okneniz/parsec/common"
 )
 
@@ -13,6 +15,10 @@ type RunesBuffer struct {
 	data     []rune
 	position int
 
+	source  string
+	offsets []int // byte offsets of runes, calculated on demand
+	lines   []int // indexes of first runes of lines, calculated on demand
+
 	// data string
 	// positions []int (stack of last positions)
 }
@@ -22,6 +28,7 @@ func NewRunesBuffer(str string) *RunesBuffer {
 	b := new(RunesBuffer)
 	b.data = []rune(str)
 	b.position = 0
+	b.source = str
 	return b
 }
 
@@ -71,6 +78,39 @@ func (b *RunesBuffer) Position() int {
 	return b.position
 }
 
+// Locate - return position of rune in source string
+func (b *RunesBuffer) Locate(idx int) node.Position {
+	if b.offsets == nil {
+		b.index()
+	}
+
+	line := sort.Search(len(b.lines), func(i int) bool {
+		return b.lines[i] > idx
+	})
+
+	return node.Position{
+		Offset: b.offsets[idx],
+		Line:   line,
+		Column: idx - b.lines[line-1] + 1,
+	}
+}
+
+func (b *RunesBuffer) index() {
+	b.offsets = make([]int, 0, len(b.data)+1)
+	b.lines = []int{0}
+
+	// the same decoding as []rune(str), invalid byte is one rune
+	for offset, r := range b.source {
+		b.offsets = append(b.offsets, offset)
+
+		if r == '\n' {
+			b.lines = append(b.lines, len(b.offsets))
+		}
+	}
+
+	b.offsets = append(b.offsets, len(b.source))
+}
+
 // IsEOF - true if buffer ended
 func (b *RunesBuffer) IsEOF() bool {
 	return b.position >= len(b.data)
@@ -48,6 +48,13 @@ func TestTreeCompaction(t *testing.T) {
 			"[0123-9]",
 			"[0-34-9]",
 		},
+		{
+			"[a-z&&[^aeiou]]",
+			"[b-df-hj-np-tv-z]",
+			"[bcdfghjklmnpqrstvwxyz]",
+			"[[a-z]&&[^aeiou]]",
+			"[b-z&&a-z&&[^aeiou]]",
+		},
 		{
 			"x+",
 			"x{1,}",
@@ -64,9 +71,20 @@ func TestTreeCompaction(t *testing.T) {
 		{
 			"x{1}",
 			"x{1,1}",
+		}, {
+			"x*+",
+			"(?>x*)",
+		}, {
+			"x++y",
+			"(?>x+)y",
 		}, {
 			"x",
 			"(?#123)x",
+		}, {
+			"(?i)[a-z]",
+			"(?i)[A-Z]",
+			"(?i)[A-Za-z]",
+			"(?i)[a-zA-Z\u017F\u212A]",
 		}, {
 			"(?i:y)",
 			"(?i)(y)(?-i)",
@@ -0,0 +1,271 @@
+package ecmascript
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// DOC - https://tc39.es/ecma262/multipage/text-processing.html#sec-regexp-regular-expression-objects
+//
+// Meaning of some ECMAScript syntax depends on flags and context of expression
+// (u and v flags, numbers and names of groups), so expressions are translated
+// to syntax of internal parser (see translator).
+
+var (
+	// DefaultParser - parser of ECMAScript expressions without flags
+	DefaultParser = &Parser{}
+
+	// \d, \w and \b are defined for ASCII
+	digit = unicodeEncoding.NewTableByPredicate(unicode.MaxASCII, isDigit)
+	word  = unicodeEncoding.NewTableByPredicate(unicode.MaxASCII, func(x rune) bool {
+		return x == '_' || isDigit(x) || isASCIILetter(x)
+	})
+
+	// white spaces and line terminators
+	space = unicodeEncoding.NewTable(
+		'\t', '\n', '\v', '\f', '\r', ' ', '\u00a0', '\u1680',
+		'\u2000', '\u2001', '\u2002', '\u2003', '\u2004', '\u2005',
+		'\u2006', '\u2007', '\u2008', '\u2009', '\u200a',
+		'\u2028', '\u2029', '\u202f', '\u205f', '\u3000', '\ufeff',
+	)
+
+	notDigit          = digit.Invert(unicode.MaxRune)
+	notWord           = word.Invert(unicode.MaxRune)
+	notSpace          = space.Invert(unicode.MaxRune)
+	anyRune           = unicodeEncoding.NewTable().Invert(unicode.MaxRune)
+	notLineTerminator = unicodeEncoding.NewTable('\n', '\r', '\u2028', '\u2029').Invert(unicode.MaxRune)
+
+	// long names of general categories
+	categoryAliases = map[string]string{
+		"Letter":                "L",
+		"Uppercase_Letter":      "Lu",
+		"Lowercase_Letter":      "Ll",
+		"Titlecase_Letter":      "Lt",
+		"Modifier_Letter":       "Lm",
+		"Other_Letter":          "Lo",
+		"Mark":                  "M",
+		"Combining_Mark":        "M",
+		"Nonspacing_Mark":       "Mn",
+		"Spacing_Mark":          "Mc",
+		"Enclosing_Mark":        "Me",
+		"Number":                "N",
+		"Decimal_Number":        "Nd",
+		"digit":                 "Nd",
+		"Letter_Number":         "Nl",
+		"Other_Number":          "No",
+		"Punctuation":           "P",
+		"punct":                 "P",
+		"Connector_Punctuation": "Pc",
+		"Dash_Punctuation":      "Pd",
+		"Open_Punctuation":      "Ps",
+		"Close_Punctuation":     "Pe",
+		"Initial_Punctuation":   "Pi",
+		"Final_Punctuation":     "Pf",
+		"Other_Punctuation":     "Po",
+		"Symbol":                "S",
+		"Math_Symbol":           "Sm",
+		"Currency_Symbol":       "Sc",
+		"Modifier_Symbol":       "Sk",
+		"Other_Symbol":          "So",
+		"Separator":             "Z",
+		"Space_Separator":       "Zs",
+		"Line_Separator":        "Zl",
+		"Paragraph_Separator":   "Zp",
+		"Other":                 "C",
+		"Control":               "Cc",
+		"cntrl":                 "Cc",
+		"Format":                "Cf",
+		"Surrogate":             "Cs",
+		"Private_Use":           "Co",
+	}
+
+	internal = parser.New(func(cfg *parser.Config) {
+		// set operations of v flag, translator wraps operands in brackets
+		cfg.Class().
+			WithOperator("&&", unicodeEncoding.Intersect).
+			WithOperator("--", unicodeEncoding.Subtract)
+
+		cfg.Class().
+			Items().
+			StringAsValue(`\d`, digit).
+			StringAsValue(`\D`, notDigit).
+			StringAsValue(`\w`, word).
+			StringAsValue(`\W`, notWord).
+			StringAsValue(`\s`, space).
+			StringAsValue(`\S`, notSpace)
+
+		cfg.Class().
+			Runes().
+			WithPrefix(`\x`, parseHexChar).
+			WithPrefix(`\`, parseEscapedRune)
+
+		cfg.NonClass().
+			Items().
+			StringAsFunc(`\A`, node.NewStartOfString).
+			StringAsFunc(`\z`, node.NewEndOfString).
+			WithPrefix(`.`, parser.TableAsClass(parser.Const(anyRune))).
+			WithPrefix(`\N`, parser.TableAsClass(parser.Const(notLineTerminator))).
+			WithPrefix(`\d`, parser.TableAsClass(parser.Const(digit))).
+			WithPrefix(`\D`, parser.TableAsClass(parser.Const(notDigit))).
+			WithPrefix(`\w`, parser.TableAsClass(parser.Const(word))).
+			WithPrefix(`\W`, parser.TableAsClass(parser.Const(notWord))).
+			WithPrefix(`\s`, parser.TableAsClass(parser.Const(space))).
+			WithPrefix(`\S`, parser.TableAsClass(parser.Const(notSpace))).
+			WithPrefix(`\x`, parser.TableAsClass(parser.RuneAsTable(parseHexChar))).
+			WithPrefix(`\k`, parseNamedReference).
+			WithPrefix(`\`, parser.TableAsClass(parser.RuneAsTable(parseEscapedRune)))
+
+		cfg.Groups().
+			Parse(parseOptions).
+			ParsePrefix("?:", parseNotCapturedGroup).
+			ParsePrefix("?<", parseNamedGroup).
+			ParsePrefix("?=", parseLookAhead).
+			ParsePrefix("?!", parseNegativeLookAhead).
+			ParsePrefix("?<=", parseLookBehind).
+			ParsePrefix("?<!", parseNegativeLookBehind)
+
+		configureProperty(cfg, unicode.Properties)
+		configureProperty(cfg, unicode.Scripts)
+		configureProperty(cfg, unicode.Categories)
+		configureProperty(cfg, map[string]*unicode.RangeTable{
+			"Any": {
+				R16: []unicode.Range16{{Lo: 0, Hi: 0xFFFF, Stride: 1}},
+				R32: []unicode.Range32{{Lo: 0x10000, Hi: unicode.MaxRune, Stride: 1}},
+			},
+			"ASCII": {
+				R16: []unicode.Range16{{Lo: 0, Hi: unicode.MaxASCII, Stride: 1}},
+			},
+		})
+
+		cfg.Quantifier().Items().StringAsValue("?", quantity.New(0, 1))
+		cfg.Quantifier().Items().StringAsValue("+", quantity.NewEndlessQuantity(1))
+		cfg.Quantifier().Items().StringAsValue("*", quantity.NewEndlessQuantity(0))
+		cfg.Quantifier().Items().StringAsValue("??", quantity.New(0, 1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("+?", quantity.NewEndlessQuantity(1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("*?", quantity.NewEndlessQuantity(0).AsLazy())
+		cfg.Quantifier().Items().WithPrefix("{", parser.OptionalSuffix(
+			parseQuantity,
+			map[rune]func(*quantity.Quantity) *quantity.Quantity{
+				'?': (*quantity.Quantity).AsLazy,
+			},
+		))
+	})
+)
+
+// Parser - parser of ECMAScript expressions with flags of regular expression literal,
+// capturing groups are parsed as node.NewSubmatch and reported as named groups "1", "2" and etc
+type Parser struct {
+	flags string
+	opts  options
+}
+
+type options struct {
+	ignoreCase bool // i
+	multiline  bool // m
+	dotAll     bool // s
+	unicode    bool // u
+	sets       bool // v
+	sticky     bool // y
+}
+
+// New - make parser for flags (like "imsu" in /.../imsu),
+// d and g flags don't change matching of expressions
+func New(flags string) (*Parser, error) {
+	p := &Parser{flags: flags}
+
+	for i, x := range flags {
+		if strings.ContainsRune(flags[:i], x) {
+			return nil, fmt.Errorf("%w: duplicate flag '%c'", ErrInvalidFlags, x)
+		}
+
+		switch x {
+		case 'd', 'g':
+		case 'i':
+			p.opts.ignoreCase = true
+		case 'm':
+			p.opts.multiline = true
+		case 's':
+			p.opts.dotAll = true
+		case 'u':
+			p.opts.unicode = true
+		case 'v':
+			p.opts.sets = true
+		case 'y':
+			p.opts.sticky = true
+		default:
+			return nil, fmt.Errorf("%w: unknown flag '%c'", ErrInvalidFlags, x)
+		}
+	}
+
+	if p.opts.unicode && p.opts.sets {
+		return nil, fmt.Errorf("%w: u and v flags are mutually exclusive", ErrInvalidFlags)
+	}
+
+	return p, nil
+}
+
+// Flags - return flags of parser
+func (p *Parser) Flags() string {
+	return p.flags
+}
+
+// Parse - parse ECMAScript expression and return node.Alternation as base type for it
+func (p *Parser) Parse(expression string) (node.Alternation, error) {
+	return parser.Translated(p.translate, internal)(expression)
+}
+
+func (p *Parser) translate(expression string) (parser.Translation, error) {
+	return translate(expression, p.opts)
+}
+
+// propertyName - name of unicode table for value of \p{...}
+func propertyName(value string) (string, bool) {
+	name, value, hasName := strings.Cut(value, "=")
+	if !hasName {
+		value = name
+	}
+
+	if x, exists := categoryAliases[value]; exists {
+		value = x
+	}
+
+	_, isCategory := unicode.Categories[value]
+	_, isScript := unicode.Scripts[value]
+	_, isProperty := unicode.Properties[value]
+
+	switch {
+	case !hasName:
+		return value, isCategory || isProperty || value == "Any" || value == "ASCII"
+	case name == "General_Category" || name == "gc":
+		return value, isCategory
+	case name == "Script" || name == "sc" || name == "Script_Extensions" || name == "scx":
+		return value, isScript
+	default:
+		return "", false
+	}
+}
+
+func configureProperty(cfg *parser.Config, props map[string]*unicode.RangeTable) {
+	for name, prop := range props {
+		tbl := unicodeEncoding.NewTableByRangeTable(prop)
+
+		positive := parser.Const(tbl)
+		negative := parser.Const(tbl.Invert(unicode.MaxRune))
+
+		cfg.NonClass().
+			Items().
+			WithPrefix(fmt.Sprintf("\\p{%s}", name), parser.TableAsClass(positive)).
+			WithPrefix(fmt.Sprintf("\\P{%s}", name), parser.TableAsClass(negative))
+
+		cfg.Class().
+			Items().
+			WithPrefix(fmt.Sprintf("\\p{%s}", name), positive).
+			WithPrefix(fmt.Sprintf("\\P{%s}", name), negative)
+	}
+}
@@ -0,0 +1,233 @@
+package ecmascript_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/ecmascript"
+	"github.com/okneniz/cliche/parser"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		flags string
+		err   error
+	}
+
+	tests := []test{
+		{flags: ""},
+		{flags: "dgimsuy"},
+		{flags: "gv"},
+		{flags: "gg", err: ecmascript.ErrInvalidFlags},
+		{flags: "x", err: ecmascript.ErrInvalidFlags},
+		{flags: "uv", err: ecmascript.ErrInvalidFlags},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := ecmascript.New(test.flags)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.flags, p.Flags())
+		})
+	}
+}
+
+func TestParser_Errors(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		flags      string
+		err        error
+	}
+
+	tests := []test{
+		{expression: "(a", err: ecmascript.ErrParenthesesImbalance},
+		{expression: "a)", err: ecmascript.ErrParenthesesImbalance},
+		{expression: "[a", err: ecmascript.ErrBracketsImbalance},
+		{expression: "a{", flags: "u", err: ecmascript.ErrLoneBracket},
+		{expression: "]", flags: "u", err: ecmascript.ErrLoneBracket},
+		{expression: "*a", err: ecmascript.ErrNothingToRepeat},
+		{expression: "a**", err: ecmascript.ErrNothingToRepeat},
+		{expression: "^*", err: ecmascript.ErrNothingToRepeat},
+		{expression: "(?<=a)*", err: ecmascript.ErrNothingToRepeat},
+		{expression: "(?=a)*", flags: "u", err: ecmascript.ErrNothingToRepeat},
+		{expression: "a{2,1}", err: ecmascript.ErrInvalidQuantifier},
+		{expression: "(?i)a", err: ecmascript.ErrInvalidGroup},
+		{expression: "(?ii:a)", err: ecmascript.ErrInvalidGroup},
+		{expression: "(?-:a)", err: ecmascript.ErrInvalidGroup},
+		{expression: "(?x:a)", err: ecmascript.ErrInvalidGroup},
+		{expression: "(?<1a>a)", err: ecmascript.ErrInvalidGroupName},
+		{expression: "(?<a>a)(?<a>b)", err: ecmascript.ErrDuplicateGroupName},
+		{expression: `(?<a>a)\k<b>`, err: ecmascript.ErrInvalidBackReference},
+		{expression: `(?<a>a)\k`, err: ecmascript.ErrInvalidBackReference},
+		{expression: `\k<a>`, flags: "u", err: ecmascript.ErrInvalidBackReference},
+		{expression: `(a)\2`, flags: "u", err: ecmascript.ErrInvalidBackReference},
+		{expression: `\i`, flags: "u", err: ecmascript.ErrInvalidEscape},
+		{expression: `\c1`, flags: "u", err: ecmascript.ErrInvalidEscape},
+		{expression: `\x4`, flags: "u", err: ecmascript.ErrInvalidEscape},
+		{expression: `\u{110000}`, flags: "u", err: ecmascript.ErrInvalidEscape},
+		{expression: `[\1]`, flags: "u", err: ecmascript.ErrInvalidEscape},
+		{expression: `a\`, err: ecmascript.ErrTrailingBackslash},
+		{expression: `[z-a]`, err: ecmascript.ErrInvalidRange},
+		{expression: `[\d-z]`, flags: "u", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[(]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `\p{Foo}`, flags: "u", err: ecmascript.ErrInvalidPropertyName},
+		{expression: `\p{Greek}`, flags: "u", err: ecmascript.ErrInvalidPropertyName},
+		{expression: `\p{gc=Greek}`, flags: "u", err: ecmascript.ErrInvalidPropertyName},
+		{expression: `[ab&&c]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[a-z--b]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[&&a]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[a&&]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[a&&b--c]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[a--bc]`, flags: "v", err: ecmascript.ErrInvalidCharacterClass},
+		{expression: `[\q{abc}]`, flags: "v", err: ecmascript.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression+"/"+test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := ecmascript.New(test.flags)
+			require.NoError(t, err)
+
+			_, err = p.Parse(test.expression)
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// errors of translated expressions refer to original expression
+func TestParser_Offsets(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		flags      string
+		offset     int
+		byteOffset int
+		snippet    string
+	}
+
+	tests := []test{
+		{expression: "a||b", offset: 2, byteOffset: 2, snippet: "a||b\n  ^"},
+		{expression: "a||b", flags: "iy", offset: 2, byteOffset: 2, snippet: "a||b\n  ^"},
+		{expression: "^a||b", flags: "m", offset: 3, byteOffset: 3, snippet: "^a||b\n   ^"},
+		{expression: `(a)|\1||`, offset: 7, byteOffset: 7, snippet: "(a)|\\1||\n       ^"},
+		{expression: "ü.||", offset: 3, byteOffset: 4, snippet: "ü.||\n   ^"},
+		{expression: "x{2,1}", offset: 1, byteOffset: 1, snippet: "x{2,1}\n ^"},
+		{expression: "ü\n(b", offset: 4, byteOffset: 5, snippet: "(b\n  ^"},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression+"/"+test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := ecmascript.New(test.flags)
+			require.NoError(t, err)
+
+			_, err = p.Parse(test.expression)
+
+			var parseErr *parser.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, test.expression, parseErr.Expression)
+			require.Equal(t, test.offset, parseErr.Offset)
+			require.Equal(t, test.byteOffset, parseErr.ByteOffset)
+			require.Equal(t, test.snippet, parseErr.Snippet())
+		})
+	}
+}
+
+func TestParser_Keys(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		same       string
+	}
+
+	tests := []test{
+		{expression: `\101`, same: `A`},
+		{expression: `\x41`, same: `A`},
+		{expression: `A`, same: `A`},
+		{expression: `😀`, same: "\U0001F600"},
+		{expression: `\cJ`, same: `\n`},
+		{expression: `\q`, same: `q`},
+		{expression: `(?<x>a)\1`, same: `(?<x>a)\k<x>`},
+		{expression: `(a)\2`, same: `(a)\x02`},
+		{expression: `a{,2}`, same: `a\{,2\}`},
+		{expression: `[\d-z]`, same: `[\d\-z]`},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := ecmascript.DefaultParser.Parse(test.same)
+			require.NoError(t, err)
+
+			actual, err := ecmascript.DefaultParser.Parse(test.expression)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.GetKey(), actual.GetKey())
+		})
+	}
+}
+
+func TestParser_ClassSetOperations(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		same       string
+	}
+
+	tests := []test{
+		{expression: `[\w--\d]`, same: `[A-Z_a-z]`},
+		{expression: `[^\w--\d]`, same: `[^A-Z_a-z]`},
+		{expression: `[[a-z]--[aeiou]]`, same: `[b-df-hj-np-tv-z]`},
+		{expression: `[[a-z]&&[^aeiou]]`, same: `[b-df-hj-np-tv-z]`},
+		{expression: `[\p{ASCII}&&\p{L}]`, same: `[A-Za-z]`},
+		{expression: `[[a-c]--b--c]`, same: `a`},
+		{expression: `[[a-z]&&[b-y]&&[c-x]]`, same: `[c-x]`},
+		{expression: `[[a-z]&&[[^a-x]--z]]`, same: `y`},
+		{expression: `[a-c[x--x]]`, same: `[a-c]`},
+	}
+
+	p, err := ecmascript.New("v")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := p.Parse(test.same)
+			require.NoError(t, err)
+
+			actual, err := p.Parse(test.expression)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.GetKey(), actual.GetKey())
+		})
+	}
+}
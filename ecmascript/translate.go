@@ -0,0 +1,996 @@
+package ecmascript
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/parser"
+)
+
+// translator - rewrite ECMAScript expression to syntax of internal parser.
+//
+// It handles the syntax which depends on flags and context of expression:
+//
+//   - ^ and $ are replaced by \A and \z without m flag
+//     and by look-arounds for line terminators with it;
+//   - . is replaced by \N (any character except line terminators) without s flag;
+//   - i, m and s modifiers (?ims-ims:...) are scoped by groups;
+//   - capturing groups are numbered and translated to (?<number>...),
+//     named groups keep names;
+//   - back references (\1, \k<name>) are resolved to \k<number> or \k<name>;
+//   - \b and \B are replaced by look-arounds for ASCII word characters;
+//   - escaped characters are replaced by \x{...};
+//   - Annex B syntax is allowed without u and v flags
+//     (legacy octal escapes, identity escapes, literal braces and brackets, etc).
+
+var (
+	ErrInvalidFlags          = errors.New("invalid flags")
+	ErrParenthesesImbalance  = errors.New("parentheses imbalance")
+	ErrBracketsImbalance     = errors.New("unterminated character class")
+	ErrLoneBracket           = errors.New("lone quantifier brackets")
+	ErrNothingToRepeat       = errors.New("nothing to repeat")
+	ErrInvalidQuantifier     = errors.New("numbers out of order in {} quantifier")
+	ErrInvalidGroup          = errors.New("invalid group")
+	ErrInvalidGroupName      = errors.New("invalid capture group name")
+	ErrDuplicateGroupName    = errors.New("duplicate capture group name")
+	ErrInvalidBackReference  = errors.New("invalid back reference")
+	ErrInvalidEscape         = errors.New("invalid escape")
+	ErrTrailingBackslash     = errors.New("\\ at end of pattern")
+	ErrInvalidRange          = errors.New("range out of order in character class")
+	ErrInvalidCharacterClass = errors.New("invalid character class")
+	ErrInvalidPropertyName   = errors.New("invalid property name")
+	ErrUnsupported           = errors.New("unsupported syntax")
+)
+
+const (
+	// characters which could be escaped with u and v flags
+	syntaxCharacters = `^$\.*+?()[]{}|/`
+
+	// characters which must be escaped in character class with v flag
+	classSetSyntaxCharacters = `(){}/|`
+
+	lineTerminators = `\x{a}\x{d}\x{2028}\x{2029}`
+	startOfLine     = `(?<![^` + lineTerminators + `])`
+	endOfLine       = `(?![^` + lineTerminators + `])`
+	wordBoundary    = `(?:(?<!\w)(?=\w)|(?<=\w)(?!\w))`
+	nonWordBoundary = `(?:(?<=\w)(?=\w)|(?<!\w)(?!\w))`
+)
+
+type translator struct {
+	src      []rune
+	pos      int
+	token    int                  // position of token which is translated
+	pieces   []parser.Translation // references are resolved after the whole expression
+	refs     []*reference
+	groups   int
+	names    map[int]string
+	hasNamed bool // \k is reference only if expression has named groups (or u flag)
+	frames   []*frame
+	quantify bool // previous atom can be quantified
+	unicode  bool // u or v flag
+	sets     bool // v flag
+	modifiers
+}
+
+// modifiers - flags which could be changed for group by (?ims-ims:...)
+type modifiers struct {
+	ignoreCase bool
+	multiline  bool
+	dotAll     bool
+}
+
+// frame - opened group
+type frame struct {
+	modifiers  // modifiers before group
+	assertion  bool
+	lookBehind bool
+}
+
+type reference struct {
+	piece  int
+	pos    int
+	number int
+	name   string
+	digits string // digits of \N, it's legacy octal escape if group doesn't exist
+}
+
+func translate(expression string, opts options) (parser.Translation, error) {
+	t := &translator{
+		src:     []rune(expression),
+		pieces:  make([]parser.Translation, 0, len(expression)),
+		names:   make(map[int]string),
+		unicode: opts.unicode || opts.sets,
+		sets:    opts.sets,
+		modifiers: modifiers{
+			ignoreCase: opts.ignoreCase,
+			multiline:  opts.multiline,
+			dotAll:     opts.dotAll,
+		},
+	}
+
+	t.hasNamed = hasNamedGroups(t.src)
+
+	for t.pos < len(t.src) {
+		if err := t.next(); err != nil {
+			return parser.Translation{}, err
+		}
+	}
+
+	if len(t.frames) > 0 {
+		return parser.Translation{}, t.errorf(ErrParenthesesImbalance, len(t.src))
+	}
+
+	translated, err := t.resolve()
+	if err != nil {
+		return parser.Translation{}, err
+	}
+
+	// groups of flags refer to the beginning and the end of expression
+	wrap := func(prefix string) {
+		translated = parser.Concat(
+			parser.NewTranslation(prefix, 0),
+			translated,
+			parser.NewTranslation(")", len(t.src)),
+		)
+	}
+
+	if opts.ignoreCase {
+		wrap("(?i:")
+	}
+
+	if opts.sticky {
+		wrap(`\A(?:`)
+	}
+
+	return translated, nil
+}
+
+func (t *translator) next() error {
+	r := t.src[t.pos]
+	t.token = t.pos
+
+	switch r {
+	case '\\':
+		return t.escape()
+	case '[':
+		return t.class()
+	case '(':
+		return t.open()
+	case ')':
+		return t.close()
+	case '*', '+', '?':
+		return t.quantifier(string(r), t.pos+1)
+	case '{':
+		return t.braces()
+	case '}', ']':
+		if t.unicode {
+			return t.errorf(ErrLoneBracket, t.pos)
+		}
+
+		t.atom(`\` + string(r))
+		t.pos++
+	case '|':
+		t.assertion("|")
+		t.pos++
+	case '^':
+		if t.multiline {
+			t.assertion(startOfLine)
+		} else {
+			t.assertion(`\A`)
+		}
+
+		t.pos++
+	case '$':
+		if t.multiline {
+			t.assertion(endOfLine)
+		} else {
+			t.assertion(`\z`)
+		}
+
+		t.pos++
+	case '.':
+		if t.dotAll {
+			t.atom(".") // any character
+		} else {
+			t.atom(`\N`) // any character except line terminators
+		}
+
+		t.pos++
+	default:
+		t.atom(string(r))
+		t.pos++
+	}
+
+	return nil
+}
+
+// quantifier - emit quantifier which ends before end and optional lazy suffix
+func (t *translator) quantifier(q string, end int) error {
+	if !t.quantify {
+		return t.errorf(ErrNothingToRepeat, t.pos)
+	}
+
+	t.pos = end
+
+	if t.peek() == '?' {
+		q += "?"
+		t.pos++
+	}
+
+	t.assertion(q)
+
+	return nil
+}
+
+// braces - {n}, {n,} or {n,m} quantifier,
+// or literal brace without u and v flags
+func (t *translator) braces() error {
+	start := t.pos
+	t.pos++
+
+	from := t.readWhile(isDigit)
+	to := from
+	comma := t.peek() == ','
+
+	if comma {
+		t.pos++
+		to = t.readWhile(isDigit)
+	}
+
+	if from == "" || t.peek() != '}' {
+		if t.unicode {
+			return t.errorf(ErrLoneBracket, start)
+		}
+
+		t.pos = start + 1
+		t.atom(`\{`)
+
+		return nil
+	}
+
+	x, errFrom := strconv.Atoi(from)
+	y, errTo := strconv.Atoi(to)
+
+	switch {
+	case errFrom != nil || to != "" && errTo != nil:
+		return t.errorf(ErrInvalidQuantifier, start)
+	case to != "" && x > y:
+		return t.errorf(ErrInvalidQuantifier, start)
+	}
+
+	q := "{" + from + "}"
+	if comma {
+		q = "{" + from + "," + to + "}"
+	}
+
+	end := t.pos + 1
+	t.pos = start
+
+	return t.quantifier(q, end)
+}
+
+func (t *translator) escape() error {
+	start := t.pos
+
+	if t.pos+1 >= len(t.src) {
+		return t.errorf(ErrTrailingBackslash, start)
+	}
+
+	r := t.src[t.pos+1]
+	t.pos += 2
+
+	switch r {
+	case 'd', 'D', 'w', 'W', 's', 'S':
+		t.atom(`\` + string(r))
+	case 'b':
+		t.assertion(wordBoundary)
+	case 'B':
+		t.assertion(nonWordBoundary)
+	case 'k':
+		if !t.unicode && !t.hasNamed {
+			t.atom("k")
+			return nil
+		}
+
+		return t.namedReference(start)
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		t.pos--
+		digits := t.readWhile(isDigit)
+		number, err := strconv.Atoi(digits)
+		if err != nil {
+			return t.errorf(ErrInvalidBackReference, start)
+		}
+
+		t.reference(&reference{pos: start, number: number, digits: digits})
+	default:
+		t.pos = start
+
+		value, err := t.characterEscape(false)
+		if err != nil {
+			return err
+		}
+
+		t.atom(value)
+	}
+
+	return nil
+}
+
+// characterEscape - escape which is the same in character class and out of it
+func (t *translator) characterEscape(inClass bool) (string, error) {
+	start := t.pos
+	r := t.src[t.pos+1]
+	t.pos += 2
+
+	switch {
+	case r == 'f':
+		return literal('\f'), nil
+	case r == 'n':
+		return literal('\n'), nil
+	case r == 'r':
+		return literal('\r'), nil
+	case r == 't':
+		return literal('\t'), nil
+	case r == 'v':
+		return literal('\v'), nil
+	case r == 'c':
+		x := t.peek()
+		if isASCIILetter(x) || inClass && !t.unicode && (isDigit(x) || x == '_') {
+			t.pos++
+			return literal(x % 32), nil
+		}
+
+		if t.unicode {
+			return "", t.errorf(ErrInvalidEscape, start)
+		}
+
+		// \ is literal, c is parsed as the next character
+		t.pos--
+		return literal('\\'), nil
+	case r == '0' && !isDigit(t.peek()):
+		return literal(0), nil
+	case isDigit(r):
+		if t.unicode {
+			return "", t.errorf(ErrInvalidEscape, start)
+		}
+
+		t.pos--
+		return t.legacyOctal(), nil
+	case r == 'x':
+		if x, ok := t.readHex(2); ok {
+			return literal(x), nil
+		}
+
+		if t.unicode {
+			return "", t.errorf(ErrInvalidEscape, start)
+		}
+
+		return literal('x'), nil
+	case r == 'u':
+		return t.unicodeEscape(start)
+	case (r == 'p' || r == 'P') && t.unicode:
+		return t.property(start, r)
+	case r == '-' && inClass && t.unicode:
+		return literal(r), nil
+	case t.unicode && !strings.ContainsRune(syntaxCharacters, r):
+		return "", t.errorf(ErrInvalidEscape, start)
+	default:
+		return literal(r), nil
+	}
+}
+
+// legacyOctal - \0 - \377 without u and v flags, \8 and \9 are digits
+func (t *translator) legacyOctal() string {
+	size := 3
+	if t.peek() >= '4' {
+		size = 2
+	}
+
+	if !isOctal(t.peek()) {
+		t.pos++
+		return literal(t.src[t.pos-1])
+	}
+
+	value := rune(0)
+	for i := 0; i < size && isOctal(t.peek()); i++ {
+		value = value*8 + t.src[t.pos] - '0'
+		t.pos++
+	}
+
+	return literal(value)
+}
+
+// unicodeEscape - \uHHHH, surrogate pair \uHHHH\uHHHH or \u{H...} with u and v flags
+func (t *translator) unicodeEscape(start int) (string, error) {
+	if t.unicode && t.peek() == '{' {
+		t.pos++
+		digits := t.readWhile(isHex)
+
+		x, err := strconv.ParseInt(digits, 16, 32)
+		if err != nil || x > unicode.MaxRune || t.peek() != '}' {
+			return "", t.errorf(ErrInvalidEscape, start)
+		}
+
+		t.pos++
+
+		return literal(rune(x)), nil
+	}
+
+	high, ok := t.readHex(4)
+	if !ok {
+		if t.unicode {
+			return "", t.errorf(ErrInvalidEscape, start)
+		}
+
+		return literal('u'), nil
+	}
+
+	if high >= 0xd800 && high <= 0xdbff && t.hasPrefix(`\u`) {
+		pos := t.pos
+		t.pos += 2
+
+		low, ok := t.readHex(4)
+		if ok && low >= 0xdc00 && low <= 0xdfff {
+			return literal((high-0xd800)<<10 + (low - 0xdc00) + 0x10000), nil
+		}
+
+		t.pos = pos
+	}
+
+	return literal(high), nil
+}
+
+// property - \p{name}, \p{name=value} or \P{...} with u and v flags
+func (t *translator) property(start int, r rune) (string, error) {
+	if t.peek() != '{' {
+		return "", t.errorf(ErrInvalidPropertyName, start)
+	}
+
+	t.pos++
+	value := t.readWhile(func(x rune) bool { return x != '}' })
+
+	if t.peek() != '}' {
+		return "", t.errorf(ErrInvalidPropertyName, start)
+	}
+
+	t.pos++
+
+	name, ok := propertyName(value)
+	if !ok {
+		return "", t.errorf(ErrInvalidPropertyName, start)
+	}
+
+	return fmt.Sprintf(`\%c{%s}`, r, name), nil
+}
+
+// namedReference - \k<name>
+func (t *translator) namedReference(start int) error {
+	if t.peek() != '<' {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	t.pos++
+	name := t.readWhile(func(x rune) bool { return x != '>' })
+
+	if t.peek() != '>' || !isName(name) {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	t.pos++
+	t.reference(&reference{pos: start, name: name})
+
+	return nil
+}
+
+func (t *translator) class() error {
+	value, err := t.classContent()
+	if err != nil {
+		return err
+	}
+
+	t.atom(value)
+
+	return nil
+}
+
+// classContent - [...] or [^...], character classes could be nested with v flag
+func (t *translator) classContent() (string, error) {
+	start := t.pos
+
+	var b strings.Builder
+
+	b.WriteString("[")
+	t.pos++
+
+	if t.peek() == '^' {
+		b.WriteString("^")
+		t.pos++
+	}
+
+	prefix := b.Len()
+
+	for t.pos < len(t.src) {
+		if t.peek() == ']' {
+			t.pos++
+
+			// empty classes
+			switch b.String() {
+			case "[":
+				return `[^\x{0}-\x{10ffff}]`, nil
+			case "[^":
+				return `[\x{0}-\x{10ffff}]`, nil
+			}
+
+			b.WriteString("]")
+			return b.String(), nil
+		}
+
+		if t.sets && (t.hasPrefix("&&") || t.hasPrefix("--")) {
+			// operator without left operand or after union, like [&&a] or [ab&&c]
+			return "", t.errorf(ErrInvalidCharacterClass, t.pos)
+		}
+
+		from, err := t.classAtom()
+		if err != nil {
+			return "", err
+		}
+
+		if t.sets && (t.hasPrefix("&&") || t.hasPrefix("--")) {
+			if b.Len() > prefix {
+				return "", t.errorf(ErrInvalidCharacterClass, t.pos)
+			}
+
+			operation, err := t.classSetOperation(from)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(operation)
+			continue
+		}
+
+		// range of characters
+		if t.peek() != '-' || t.pos+1 >= len(t.src) || t.src[t.pos+1] == ']' {
+			b.WriteString(from.value)
+			continue
+		}
+
+		pos := t.pos
+		t.pos++
+
+		to, err := t.classAtom()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case from.isRune && to.isRune && from.r > to.r:
+			return "", t.errorf(ErrInvalidRange, pos)
+		case from.isRune && to.isRune:
+			b.WriteString(from.value + "-" + to.value)
+		case t.unicode:
+			return "", t.errorf(ErrInvalidCharacterClass, pos)
+		default:
+			// \d-x is not a range without u and v flags
+			b.WriteString(from.value + literal('-') + to.value)
+		}
+	}
+
+	return "", t.errorf(ErrBracketsImbalance, start)
+}
+
+// classSetOperation - intersection (&&) or subtraction (--) of operands with v flag,
+// like [\w--\d], operands are wrapped in brackets for internal parser,
+// operators can't be mixed without nested classes and the class must end after operation
+func (t *translator) classSetOperation(first *classAtom) (string, error) {
+	operator := string(t.src[t.pos : t.pos+2])
+
+	var b strings.Builder
+
+	b.WriteString("[" + first.value + "]")
+
+	for t.hasPrefix(operator) {
+		pos := t.pos
+		t.pos += 2
+
+		if t.pos >= len(t.src) || t.peek() == ']' {
+			return "", t.errorf(ErrInvalidCharacterClass, pos)
+		}
+
+		operand, err := t.classAtom()
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(operator + "[" + operand.value + "]")
+	}
+
+	if t.pos < len(t.src) && t.peek() != ']' {
+		return "", t.errorf(ErrInvalidCharacterClass, t.pos)
+	}
+
+	return b.String(), nil
+}
+
+type classAtom struct {
+	value  string
+	r      rune
+	isRune bool
+}
+
+func (t *translator) classAtom() (*classAtom, error) {
+	r := t.src[t.pos]
+
+	switch {
+	case r == '[' && t.sets:
+		value, err := t.classContent()
+		if err != nil {
+			return nil, err
+		}
+
+		return &classAtom{value: value}, nil
+	case t.sets && strings.ContainsRune(classSetSyntaxCharacters, r):
+		return nil, t.errorf(ErrInvalidCharacterClass, t.pos)
+	case r != '\\':
+		t.pos++
+		return &classAtom{value: literal(r), r: r, isRune: true}, nil
+	}
+
+	start := t.pos
+
+	if t.pos+1 >= len(t.src) {
+		return nil, t.errorf(ErrTrailingBackslash, start)
+	}
+
+	x := t.src[t.pos+1]
+
+	switch {
+	case strings.ContainsRune("dDwWsS", x):
+		t.pos += 2
+		return &classAtom{value: `\` + string(x)}, nil
+	case x == 'b':
+		t.pos += 2
+		return &classAtom{value: literal('\b'), r: '\b', isRune: true}, nil
+	case x == 'q' && t.sets:
+		// strings in class
+		return nil, t.errorf(ErrUnsupported, start)
+	case x == 'k' && !t.unicode:
+		t.pos += 2
+		return &classAtom{value: literal('k'), r: 'k', isRune: true}, nil
+	case (x == 'B' || x == 'k') && t.unicode:
+		return nil, t.errorf(ErrInvalidEscape, start)
+	}
+
+	value, err := t.characterEscape(true)
+	if err != nil {
+		return nil, err
+	}
+
+	// properties are sets of characters
+	if strings.HasPrefix(value, `\p`) || strings.HasPrefix(value, `\P`) {
+		return &classAtom{value: value}, nil
+	}
+
+	return &classAtom{value: value, r: valueOf(value), isRune: true}, nil
+}
+
+func (t *translator) open() error {
+	start := t.pos
+
+	switch {
+	case t.hasPrefix("(?:"):
+		t.push(new(frame))
+		t.assertion("(?:")
+		t.pos += 3
+	case t.hasPrefix("(?="), t.hasPrefix("(?!"):
+		t.push(&frame{assertion: true})
+		t.assertion(string(t.src[t.pos : t.pos+3]))
+		t.pos += 3
+	case t.hasPrefix("(?<="), t.hasPrefix("(?<!"):
+		t.push(&frame{assertion: true, lookBehind: true})
+		t.assertion(string(t.src[t.pos : t.pos+4]))
+		t.pos += 4
+	case t.hasPrefix("(?<"):
+		t.pos += 3
+		name := t.readWhile(func(x rune) bool { return x != '>' })
+
+		if t.peek() != '>' || !isName(name) {
+			return t.errorf(ErrInvalidGroupName, start)
+		}
+
+		for _, x := range t.names {
+			if x == name {
+				return t.errorf(ErrDuplicateGroupName, start)
+			}
+		}
+
+		t.pos++
+		t.capture(name)
+	case t.hasPrefix("(?"):
+		return t.modify(start)
+	default:
+		t.pos++
+		t.capture("")
+	}
+
+	return nil
+}
+
+// capture - open capturing group, unnamed groups are named by its numbers
+func (t *translator) capture(name string) {
+	t.groups++
+	t.push(new(frame))
+
+	if name == "" {
+		t.assertion(fmt.Sprintf("(?<%d>", t.groups))
+		return
+	}
+
+	t.names[t.groups] = name
+	t.assertion("(?<" + name + ">")
+}
+
+// modify - (?ims-ims:...), i is passed to internal parser, m and s are handled by translator
+func (t *translator) modify(start int) error {
+	var (
+		enable  = true
+		seen    = make(map[rune]bool)
+		changed = t.modifiers
+		options string
+	)
+
+	for i := t.pos + 2; i < len(t.src); i++ {
+		r := t.src[i]
+
+		switch r {
+		case '-':
+			if !enable {
+				return t.errorf(ErrInvalidGroup, start)
+			}
+
+			enable = false
+		case 'i', 'm', 's':
+			if seen[r] {
+				return t.errorf(ErrInvalidGroup, start)
+			}
+
+			seen[r] = true
+
+			switch r {
+			case 'i':
+				changed.ignoreCase = enable
+			case 'm':
+				changed.multiline = enable
+			case 's':
+				changed.dotAll = enable
+			}
+		case ':':
+			if len(seen) == 0 {
+				return t.errorf(ErrInvalidGroup, start)
+			}
+
+			if seen['i'] {
+				options = "i"
+				if !changed.ignoreCase {
+					options = "-i"
+				}
+			}
+
+			t.push(new(frame))
+			t.modifiers = changed
+			t.assertion("(?" + options + ":")
+			t.pos = i + 1
+
+			return nil
+		default:
+			return t.errorf(ErrInvalidGroup, start)
+		}
+	}
+
+	return t.errorf(ErrParenthesesImbalance, start)
+}
+
+func (t *translator) close() error {
+	if len(t.frames) == 0 {
+		return t.errorf(ErrParenthesesImbalance, t.pos)
+	}
+
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	t.modifiers = f.modifiers
+	t.pos++
+
+	switch {
+	case f.lookBehind:
+		t.assertion(")")
+	case f.assertion && t.unicode:
+		t.assertion(")")
+	default:
+		// lookahead could be quantified without u and v flags
+		t.atom(")")
+	}
+
+	return nil
+}
+
+func (t *translator) push(f *frame) {
+	f.modifiers = t.modifiers
+	t.frames = append(t.frames, f)
+}
+
+// reference - add reference which is resolved when all groups are known
+func (t *translator) reference(ref *reference) {
+	ref.piece = len(t.pieces)
+	t.refs = append(t.refs, ref)
+	t.atom("")
+}
+
+func (t *translator) resolve() (parser.Translation, error) {
+	for _, ref := range t.refs {
+		if ref.name != "" {
+			if !t.hasName(ref.name) {
+				return parser.Translation{}, t.errorf(ErrInvalidBackReference, ref.pos)
+			}
+
+			t.pieces[ref.piece] = parser.NewTranslation(`\k<`+ref.name+`>`, ref.pos)
+			continue
+		}
+
+		switch {
+		case ref.number <= t.groups:
+			name, exists := t.names[ref.number]
+			if !exists {
+				name = strconv.Itoa(ref.number)
+			}
+
+			t.pieces[ref.piece] = parser.NewTranslation(`\k<`+name+`>`, ref.pos)
+		case t.unicode:
+			return parser.Translation{}, t.errorf(ErrInvalidBackReference, ref.pos)
+		default:
+			// \N is legacy octal escape when there are less than N groups
+			x := &translator{src: []rune(ref.digits)}
+			value := x.legacyOctal()
+
+			for _, r := range x.src[x.pos:] {
+				value += string(r)
+			}
+
+			t.pieces[ref.piece] = parser.NewTranslation(value, ref.pos)
+		}
+	}
+
+	return parser.Concat(t.pieces...), nil
+}
+
+func (t *translator) hasName(name string) bool {
+	for _, x := range t.names {
+		if x == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// atom - emit syntax which could be quantified
+func (t *translator) atom(s string) {
+	t.pieces = append(t.pieces, parser.NewTranslation(s, t.token))
+	t.quantify = true
+}
+
+// assertion - emit syntax which couldn't be quantified
+func (t *translator) assertion(s string) {
+	t.pieces = append(t.pieces, parser.NewTranslation(s, t.token))
+	t.quantify = false
+}
+
+func (t *translator) peek() rune {
+	if t.pos < len(t.src) {
+		return t.src[t.pos]
+	}
+
+	return -1
+}
+
+func (t *translator) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(t.src[t.pos:]), prefix)
+}
+
+// readHex - read exactly size hex digits
+func (t *translator) readHex(size int) (rune, bool) {
+	if t.pos+size > len(t.src) {
+		return 0, false
+	}
+
+	x, err := strconv.ParseInt(string(t.src[t.pos:t.pos+size]), 16, 32)
+	if err != nil || strings.ContainsAny(string(t.src[t.pos:t.pos+size]), "+-") {
+		return 0, false
+	}
+
+	t.pos += size
+
+	return rune(x), true
+}
+
+func (t *translator) readWhile(predicate func(rune) bool) string {
+	from := t.pos
+
+	for t.pos < len(t.src) && predicate(t.src[t.pos]) {
+		t.pos++
+	}
+
+	return string(t.src[from:t.pos])
+}
+
+func (t *translator) errorf(err error, pos int) error {
+	return parser.NewParseError(string(t.src), pos, err)
+}
+
+// hasNamedGroups - check that expression has named groups (?<name>...)
+func hasNamedGroups(src []rune) bool {
+	inClass := false
+
+	for i := 0; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+		case src[i] == '[':
+			inClass = true
+		case src[i] == ']':
+			inClass = false
+		case !inClass && strings.HasPrefix(string(src[i:]), "(?<"):
+			if i+3 < len(src) && src[i+3] != '=' && src[i+3] != '!' {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// literal - escaped character in syntax of internal parser
+func literal(r rune) string {
+	return fmt.Sprintf(`\x{%x}`, r)
+}
+
+// valueOf - character of literal
+func valueOf(s string) rune {
+	x, _ := strconv.ParseInt(s[3:len(s)-1], 16, 32)
+	return rune(x)
+}
+
+func isDigit(x rune) bool {
+	return x >= '0' && x <= '9'
+}
+
+func isOctal(x rune) bool {
+	return x >= '0' && x <= '7'
+}
+
+func isHex(x rune) bool {
+	return x >= '0' && x <= '9' ||
+		x >= 'a' && x <= 'f' ||
+		x >= 'A' && x <= 'F'
+}
+
+func isASCIILetter(x rune) bool {
+	return x >= 'a' && x <= 'z' || x >= 'A' && x <= 'Z'
+}
+
+// isName - check that name is identifier
+func isName(x string) bool {
+	if x == "" || unicode.IsDigit([]rune(x)[0]) {
+		return false
+	}
+
+	for _, r := range x {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
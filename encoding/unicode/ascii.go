@@ -0,0 +1,27 @@
+package unicode
+
+import (
+	"unicode"
+)
+
+// POSIX classes like [:alpha:] defined for ASCII (POSIX locale)
+var (
+	ASCIIAlnum  = NewTableByPredicate(unicode.MaxASCII, func(x rune) bool { return unicode.IsLetter(x) || unicode.IsDigit(x) })
+	ASCIIAlpha  = NewTableByPredicate(unicode.MaxASCII, unicode.IsLetter)
+	ASCIIBlank  = NewTableByPredicate(unicode.MaxASCII, func(x rune) bool { return x == ' ' || x == '\t' })
+	ASCIICntrl  = NewTableByPredicate(unicode.MaxASCII, unicode.IsControl)
+	ASCIIDigit  = NewTableByPredicate(unicode.MaxASCII, unicode.IsDigit)
+	ASCIIGraph  = NewTableByPredicate(unicode.MaxASCII, func(x rune) bool { return unicode.IsGraphic(x) && !unicode.IsSpace(x) })
+	ASCIILower  = NewTableByPredicate(unicode.MaxASCII, unicode.IsLower)
+	ASCIIPrint  = NewTableByPredicate(unicode.MaxASCII, unicode.IsPrint)
+	ASCIIPunct  = NewTableByPredicate(unicode.MaxASCII, func(x rune) bool { return unicode.IsPunct(x) || unicode.IsSymbol(x) })
+	ASCIISpace  = NewTableByPredicate(unicode.MaxASCII, unicode.IsSpace)
+	ASCIIUpper  = NewTableByPredicate(unicode.MaxASCII, unicode.IsUpper)
+	ASCIIXdigit = NewTableByPredicate(unicode.MaxASCII, isHex)
+)
+
+func isHex(x rune) bool {
+	return x >= '0' && x <= '9' ||
+		x >= 'a' && x <= 'f' ||
+		x >= 'A' && x <= 'F'
+}
@@ -1,6 +1,9 @@
 package unicode
 
 import (
+	"unicode"
+
+	"github.com/okneniz/cliche/interval"
 	"github.com/okneniz/cliche/node"
 )
 
@@ -15,13 +18,11 @@ func (t emptyTable) Include(x rune) bool {
 }
 
 func (t emptyTable) Invert(max rune) node.Table {
-	runes := make([]rune, 0)
-
-	for x := rune(1); x <= max; x++ {
-		runes = append(runes, x)
-	}
+	return newTable(interval.Complement(nil, 1, max))
+}
 
-	return NewTable(runes...)
+func (t emptyTable) RangeTable() *unicode.RangeTable {
+	return new(unicode.RangeTable)
 }
 
 func (t emptyTable) Empty() bool {
@@ -1,6 +1,10 @@
 package unicode
 
-import "github.com/okneniz/cliche/node"
+import (
+	"unicode"
+
+	"github.com/okneniz/cliche/node"
+)
 
 var (
 	everything = everythingTable{}
@@ -16,6 +20,13 @@ func (t everythingTable) Invert(_ rune) node.Table {
 	return empty
 }
 
+func (t everythingTable) RangeTable() *unicode.RangeTable {
+	return &unicode.RangeTable{
+		R16: []unicode.Range16{{Lo: 0, Hi: 0xFFFF, Stride: 1}},
+		R32: []unicode.Range32{{Lo: 0x10000, Hi: unicode.MaxRune, Stride: 1}},
+	}
+}
+
 func (t everythingTable) Empty() bool {
 	return false
 }
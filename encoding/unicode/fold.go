@@ -0,0 +1,34 @@
+package unicode
+
+import (
+	"sync"
+	"unicode"
+
+	"github.com/okneniz/cliche/interval"
+	"github.com/okneniz/cliche/node"
+)
+
+// foldable - sorted intervals of runes which have other runes in orbits of case folding,
+// they're found on first use, because some of them don't have case mappings (like U+0390)
+var foldable = sync.OnceValue(func() []interval.Interval {
+	return interval.ByPredicate(unicode.MaxRune, func(x rune) bool {
+		return unicode.SimpleFold(x) != x
+	})
+})
+
+// FoldTable - table of runes of tbl and all runes of their orbits of case folding
+// (see unicode.SimpleFold), like [A-Za-zſK] for [a-z]
+func FoldTable(tbl node.Table) node.Table {
+	runes := intervalsOf(tbl)
+	orbits := make([]interval.Interval, 0)
+
+	for _, x := range interval.Intersect(runes, foldable()) {
+		for r := x.Lo; r <= x.Hi; r++ {
+			for y := unicode.SimpleFold(r); y != r; y = unicode.SimpleFold(y) {
+				orbits = append(orbits, interval.Interval{Lo: y, Hi: y})
+			}
+		}
+	}
+
+	return newTable(interval.Union(runes, interval.Normalize(orbits)))
+}
@@ -0,0 +1,227 @@
+package unicode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/interval"
+	"github.com/okneniz/cliche/node"
+)
+
+// intervalTable - table of sorted intervals of runes,
+// intervals don't overlap and don't touch each other
+type intervalTable struct {
+	intervals []interval.Interval
+}
+
+// newTable - canonical table of sorted, not overlapped and not adjacent intervals,
+// like NewTable of the same runes
+func newTable(intervals []interval.Interval) node.Table {
+	size := 0
+
+	for _, x := range intervals {
+		size += int(x.Hi-x.Lo) + 1
+	}
+
+	switch {
+	case size == 0:
+		return empty
+	case size == 1:
+		return runeTable{
+			r: intervals[0].Lo,
+		}
+	case size >= unicode.MaxRune:
+		return everything
+	}
+
+	return &intervalTable{
+		intervals: intervals,
+	}
+}
+
+func (t *intervalTable) Include(x rune) bool {
+	i := sort.Search(len(t.intervals), func(i int) bool {
+		return t.intervals[i].Hi >= x
+	})
+
+	return i < len(t.intervals) && t.intervals[i].Lo <= x
+}
+
+func (t *intervalTable) Invert(max rune) node.Table {
+	return newTable(interval.Complement(t.intervals, 1, max))
+}
+
+// RangeTable - the same range table as rangetable.New of runes of table
+func (t *intervalTable) RangeTable() *unicode.RangeTable {
+	return rangeTableOf(t.intervals)
+}
+
+func (t *intervalTable) Empty() bool {
+	return len(t.intervals) == 0
+}
+
+func (t *intervalTable) String() string {
+	tbl := t.RangeTable()
+	b := new(strings.Builder)
+
+	b.WriteString("[")
+
+	if len(tbl.R16) > 0 {
+		b.WriteString("R16(")
+
+		for i, r := range tbl.R16 {
+			writeRange(b, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+
+			if i != len(tbl.R16)-1 {
+				b.WriteString(",")
+			}
+		}
+
+		b.WriteString(")")
+	}
+
+	if len(tbl.R32) > 0 {
+		if len(tbl.R16) > 0 {
+			b.WriteString(",")
+		}
+
+		b.WriteString("R32(")
+
+		for i, r := range tbl.R32 {
+			writeRange(b, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+
+			if i != len(tbl.R32)-1 {
+				b.WriteString(",")
+			}
+		}
+
+		b.WriteString(")")
+	}
+
+	b.WriteString("]")
+
+	return b.String()
+}
+
+func writeRange(b *strings.Builder, lo, hi, stride rune) {
+	b.WriteString(fmt.Sprintf("%d-%d", lo, hi))
+
+	if stride != 1 {
+		b.WriteString(fmt.Sprintf("-%d", stride))
+	}
+}
+
+// stridedRange - range of unicode.RangeTable
+type stridedRange struct {
+	lo, hi, stride rune
+}
+
+// rangeTableOf - make range table like rangetable.New (and rangetable.Merge) of runes of intervals:
+// runes are merged to the previous range while the distance between them is the same
+func rangeTableOf(intervals []interval.Interval) *unicode.RangeTable {
+	tbl := new(unicode.RangeTable)
+
+	for _, r := range strided(clip(intervals, 0, 0xFFFF)) {
+		tbl.R16 = append(tbl.R16, unicode.Range16{
+			Lo:     uint16(r.lo),
+			Hi:     uint16(r.hi),
+			Stride: uint16(r.stride),
+		})
+	}
+
+	for _, r := range strided(clip(intervals, 0x10000, unicode.MaxRune)) {
+		tbl.R32 = append(tbl.R32, unicode.Range32{
+			Lo:     uint32(r.lo),
+			Hi:     uint32(r.hi),
+			Stride: uint32(r.stride),
+		})
+	}
+
+	for i := 0; i < len(tbl.R16) && tbl.R16[i].Hi <= unicode.MaxLatin1; i++ {
+		tbl.LatinOffset = i + 1
+	}
+
+	return tbl
+}
+
+// strided - merge runes of intervals to ranges with stride,
+// consecutive runes of interval are handled by few steps instead of iteration
+func strided(intervals []interval.Interval) []stridedRange {
+	result := make([]stridedRange, 0, len(intervals))
+
+	var (
+		current stridedRange
+		started bool
+	)
+
+	push := func(x rune) {
+		switch {
+		case !started:
+			current = stridedRange{lo: x, hi: x, stride: 1}
+			started = true
+		case current.lo == current.hi || x-current.hi == current.stride:
+			current.stride = x - current.hi
+			current.hi = x
+		default:
+			result = append(result, current)
+			current = stridedRange{lo: x, hi: x, stride: 1}
+		}
+	}
+
+	for _, x := range intervals {
+		push(x.Lo)
+
+		if x.Hi > x.Lo {
+			push(x.Lo + 1)
+		}
+
+		// range has stride 1 after the second rune of interval
+		if x.Hi > x.Lo+1 {
+			current.hi = x.Hi
+		}
+	}
+
+	if started {
+		result = append(result, current)
+	}
+
+	return result
+}
+
+// clip - parts of intervals between lo and hi
+func clip(intervals []interval.Interval, lo, hi rune) []interval.Interval {
+	result := make([]interval.Interval, 0, len(intervals))
+
+	for _, x := range intervals {
+		if x.Hi < lo || x.Lo > hi {
+			continue
+		}
+
+		result = append(result, interval.Interval{Lo: max(x.Lo, lo), Hi: min(x.Hi, hi)})
+	}
+
+	return result
+}
+
+// intervalsOf - sorted and merged intervals of runes of table,
+// iterate over all runes only if table is unknown and isn't node.RangeTable
+func intervalsOf(tbl node.Table) []interval.Interval {
+	switch x := tbl.(type) {
+	case emptyTable:
+		return nil
+	case runeTable:
+		return []interval.Interval{{Lo: x.r, Hi: x.r}}
+	case everythingTable:
+		return []interval.Interval{{Lo: 0, Hi: unicode.MaxRune}}
+	case *intervalTable:
+		return x.intervals
+	case *lazyTable:
+		return intervalsOf(x.get())
+	case node.RangeTable:
+		return interval.OfRangeTable(x.RangeTable())
+	}
+
+	return interval.ByPredicate(unicode.MaxRune, tbl.Include)
+}
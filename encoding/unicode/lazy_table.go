@@ -0,0 +1,59 @@
+package unicode
+
+import (
+	"sync"
+	"unicode"
+
+	"github.com/okneniz/cliche/node"
+)
+
+// lazyTable - table which is built on first use,
+// because it takes a while to check each rune for each unicode property
+type lazyTable struct {
+	once  sync.Once
+	build func() node.Table
+	table node.Table
+}
+
+// NewLazyTable - table which is built by build on first use,
+// it's safe for concurrent use
+func NewLazyTable(build func() node.Table) node.Table {
+	return &lazyTable{build: build}
+}
+
+func (t *lazyTable) get() node.Table {
+	t.once.Do(func() {
+		t.table = t.build()
+		t.build = nil
+	})
+
+	return t.table
+}
+
+func (t *lazyTable) Include(x rune) bool {
+	return t.get().Include(x)
+}
+
+func (t *lazyTable) Invert(max rune) node.Table {
+	return NewLazyTable(func() node.Table {
+		return t.get().Invert(max)
+	})
+}
+
+func (t *lazyTable) RangeTable() *unicode.RangeTable {
+	tbl := t.get()
+
+	if x, ok := tbl.(node.RangeTable); ok {
+		return x.RangeTable()
+	}
+
+	return NewTableByPredicate(unicode.MaxRune, tbl.Include).(node.RangeTable).RangeTable()
+}
+
+func (t *lazyTable) Empty() bool {
+	return t.get().Empty()
+}
+
+func (t *lazyTable) String() string {
+	return t.get().String()
+}
@@ -2,8 +2,11 @@ package unicode
 
 import (
 	"fmt"
+	"unicode"
 
+	"github.com/okneniz/cliche/interval"
 	"github.com/okneniz/cliche/node"
+	"golang.org/x/text/unicode/rangetable"
 )
 
 type runeTable struct {
@@ -15,15 +18,11 @@ func (t runeTable) Include(x rune) bool {
 }
 
 func (t runeTable) Invert(max rune) node.Table {
-	runes := make([]rune, 0)
-
-	for x := rune(1); x <= max; x++ {
-		if t.r != x {
-			runes = append(runes, x)
-		}
-	}
+	return newTable(interval.Complement([]interval.Interval{{Lo: t.r, Hi: t.r}}, 1, max))
+}
 
-	return NewTable(runes...)
+func (t runeTable) RangeTable() *unicode.RangeTable {
+	return rangetable.New(t.r)
 }
 
 func (t runeTable) Empty() bool {
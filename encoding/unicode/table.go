@@ -3,51 +3,89 @@ package unicode
 import (
 	"unicode"
 
+	"github.com/okneniz/cliche/interval"
 	"github.com/okneniz/cliche/node" // how to remove it from deps?
 	"golang.org/x/exp/slices"
-	"golang.org/x/text/unicode/rangetable"
+)
+
+var (
+	_ node.RangeTable = empty
+	_ node.RangeTable = everything
+	_ node.RangeTable = runeTable{}
+	_ node.RangeTable = new(intervalTable)
+	_ node.RangeTable = new(lazyTable)
 )
 
 func NewTable(runes ...rune) node.Table {
 	slices.Sort(runes)
 	runes = slices.Compact(runes)
 
-	switch {
-	case len(runes) == 0:
-		return empty
-	case len(runes) == 1:
-		return runeTable{
-			r: runes[0],
+	intervals := make([]interval.Interval, 0)
+
+	for _, x := range runes {
+		if n := len(intervals); n > 0 && intervals[n-1].Hi == x-1 {
+			intervals[n-1].Hi = x
+		} else {
+			intervals = append(intervals, interval.Interval{Lo: x, Hi: x})
 		}
-	case len(runes) >= unicode.MaxRune:
-		return everything
 	}
 
-	return newRangeTable(rangetable.New(runes...))
+	return newTable(intervals)
+}
+
+// NewTableByRange - table of runes from lo to hi inclusive, empty if lo > hi
+func NewTableByRange(lo, hi rune) node.Table {
+	if lo > hi {
+		return empty
+	}
+
+	return newTable([]interval.Interval{{Lo: lo, Hi: hi}})
 }
 
+// MergeTables - the same as Union
 func MergeTables(tbls ...node.Table) node.Table {
-	runes := make([]rune, 0)
+	return Union(tbls...)
+}
 
-	for x := rune(0); x <= unicode.MaxRune; x++ {
-		for _, tbl := range tbls {
-			if tbl.Include(x) {
-				runes = append(runes, x)
-			}
-		}
+// Union - table of runes which are included in any of tables
+func Union(tbls ...node.Table) node.Table {
+	result := make([]interval.Interval, 0)
+
+	for _, tbl := range tbls {
+		result = interval.Union(result, intervalsOf(tbl))
 	}
 
-	return NewTable(runes...)
+	return newTable(result)
+}
+
+// Intersect - table of runes which are included in both tables
+func Intersect(a, b node.Table) node.Table {
+	return newTable(interval.Intersect(intervalsOf(a), intervalsOf(b)))
+}
+
+// Subtract - table of runes of a which aren't included in b
+func Subtract(a, b node.Table) node.Table {
+	return newTable(interval.Subtract(intervalsOf(a), intervalsOf(b)))
 }
 
+// NewTableByPredicate - table of runes from 0 to max which satisfy p,
+// it's built on first use (see NewLazyTable)
 func NewTableByPredicate(max rune, p func(rune) bool) node.Table {
-	runes := make([]rune, 0)
+	return NewLazyTable(func() node.Table {
+		return newTable(interval.ByPredicate(max, p))
+	})
+}
 
-	for x := rune(0); x <= max; x++ {
-		if p(x) {
-			runes = append(runes, x)
-		}
-	}
+// NewTableByRangeTable - table of runes of range table (like unicode.Properties),
+// it's built on first use from ranges of tbl without check of each rune
+func NewTableByRangeTable(tbl *unicode.RangeTable) node.Table {
+	return NewLazyTable(func() node.Table {
+		return FromRangeTable(tbl)
+	})
+}
 
-	return NewTable(runes...)
+// FromRangeTable - table of runes of range table (like NewTable of the same runes)
+// without iteration over runes
+func FromRangeTable(tbl *unicode.RangeTable) node.Table {
+	return newTable(interval.OfRangeTable(tbl))
 }
@@ -0,0 +1,219 @@
+package unicode_test
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/unicode/rangetable"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+)
+
+func TestTable_String(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		name  string
+		table node.Table
+		key   string
+	}
+
+	tests := []test{
+		{
+			name:  "empty",
+			table: unicodeEncoding.NewTable(),
+			key:   "[]",
+		},
+		{
+			name:  "rune",
+			table: unicodeEncoding.NewTable('a', 'a'),
+			key:   "[97]",
+		},
+		{
+			name:  "range",
+			table: unicodeEncoding.NewTableByRange('0', '9'),
+			key:   "[R16(48-57)]",
+		},
+		{
+			name:  "runes with stride",
+			table: unicodeEncoding.NewTable('a', 'c', 'e', 'f', 'g'),
+			key:   "[R16(97-101-2,102-103)]",
+		},
+		{
+			name:  "union of ranges and runes",
+			table: unicodeEncoding.Union(unicodeEncoding.NewTableByRange('a', 'z'), unicodeEncoding.NewTable('1', '2')),
+			key:   "[R16(49-50,97-122)]",
+		},
+		{
+			name:  "range over R16 and R32",
+			table: unicodeEncoding.NewTableByRange(0xFFFE, 0x10001),
+			key:   "[R16(65534-65535),R32(65536-65537)]",
+		},
+		{
+			name:  "inverted empty",
+			table: unicodeEncoding.NewTable().Invert(unicode.MaxRune),
+			key:   "[ALL]",
+		},
+		{
+			name:  "inverted rune",
+			table: unicodeEncoding.NewTable('a').Invert(0x7F),
+			key:   "[R16(1-96,98-127)]",
+		},
+		{
+			name:  "intersection",
+			table: unicodeEncoding.Intersect(unicodeEncoding.NewTableByRange('a', 'z'), unicodeEncoding.NewTableByRange('x', 0x7F)),
+			key:   "[R16(120-122)]",
+		},
+		{
+			name:  "subtraction",
+			table: unicodeEncoding.Subtract(unicodeEncoding.NewTableByRange('a', 'z'), unicodeEncoding.NewTable('a', 'e', 'i', 'o', 'u')),
+			key:   "[R16(98-100,102-104,106-110,112-116,118-122)]",
+		},
+		{
+			name:  "subtraction of everything",
+			table: unicodeEncoding.Subtract(unicodeEncoding.NewTableByRange('a', 'z'), unicodeEncoding.NewTable().Invert(unicode.MaxRune)),
+			key:   "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.key, tt.table.String())
+		})
+	}
+}
+
+// tables must have the same keys as tables of rangetable.New,
+// because keys of nodes are made from them
+func TestTable_RangeTable(t *testing.T) {
+	t.Parallel()
+
+	random := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 1000; i++ {
+		runes := randomRunes(random)
+
+		tbl := unicodeEncoding.NewTable(runes...)
+		if len(runes) < 2 {
+			continue
+		}
+
+		rt, ok := tbl.(node.RangeTable)
+		require.True(t, ok)
+		require.Equal(t, rangetable.New(runes...), rt.RangeTable(), "runes: %v", runes)
+
+		loaded := unicodeEncoding.FromRangeTable(rt.RangeTable())
+		require.Equal(t, tbl.String(), loaded.String())
+	}
+}
+
+func TestTable_SetOperations(t *testing.T) {
+	t.Parallel()
+
+	random := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 300; i++ {
+		xs, ys := randomRunes(random), randomRunes(random)
+		a, b := unicodeEncoding.NewTable(xs...), unicodeEncoding.NewTable(ys...)
+
+		union := unicodeEncoding.Union(a, b)
+		intersection := unicodeEncoding.Intersect(a, b)
+		subtraction := unicodeEncoding.Subtract(a, b)
+		inversion := a.Invert(0x400)
+
+		for x := rune(0); x <= 0x410; x++ {
+			require.Equal(t, a.Include(x) || b.Include(x), union.Include(x), "union, rune %d", x)
+			require.Equal(t, a.Include(x) && b.Include(x), intersection.Include(x), "intersection, rune %d", x)
+			require.Equal(t, a.Include(x) && !b.Include(x), subtraction.Include(x), "subtraction, rune %d", x)
+			require.Equal(t, x >= 1 && x <= 0x400 && !a.Include(x), inversion.Include(x), "inversion, rune %d", x)
+		}
+
+		require.Equal(t, union.String(), unicodeEncoding.Union(b, a).String())
+		require.Equal(t, intersection.String(), unicodeEncoding.Intersect(b, a).String())
+	}
+}
+
+func TestFoldTable(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t,
+		"[R16(65-90,97-122,383-8490-8107)]",
+		unicodeEncoding.FoldTable(unicodeEncoding.NewTableByRange('a', 'z')).String(),
+	)
+
+	random := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 100; i++ {
+		runes := randomRunes(random)
+		tbl := unicodeEncoding.NewTable(runes...)
+		folded := unicodeEncoding.FoldTable(tbl)
+
+		for x := rune(0); x <= 0x2200; x++ {
+			expected := tbl.Include(x)
+
+			for y := unicode.SimpleFold(x); y != x && !expected; y = unicode.SimpleFold(y) {
+				expected = tbl.Include(y)
+			}
+
+			require.Equal(t, expected, folded.Include(x), "rune %d of %v", x, runes)
+		}
+	}
+}
+
+func randomRunes(random *rand.Rand) []rune {
+	runes := make([]rune, 0)
+
+	for j := random.Intn(6); j > 0; j-- {
+		from := rune(random.Intn(0x400))
+		to := from + rune(random.Intn(20))
+		stride := rune(1 + random.Intn(3))
+
+		for x := from; x <= to; x += stride {
+			runes = append(runes, x)
+		}
+	}
+
+	return runes
+}
+
+func BenchmarkMergeTables(b *testing.B) {
+	tables := []node.Table{
+		unicodeEncoding.FromRangeTable(unicode.Greek),
+		unicodeEncoding.FromRangeTable(unicode.Cyrillic),
+		unicodeEncoding.FromRangeTable(unicode.Latin),
+		unicodeEncoding.NewTable('0', '1', '2', '3', '4', '5', '6', '7', '8', '9'),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = unicodeEncoding.MergeTables(tables...).String()
+	}
+}
+
+func BenchmarkInvert(b *testing.B) {
+	tbl := unicodeEncoding.FromRangeTable(unicode.Letter)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = tbl.Invert(unicode.MaxRune).String()
+	}
+}
+
+func BenchmarkNewTableByPredicate(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = unicodeEncoding.NewTableByPredicate(unicode.MaxRune, func(x rune) bool {
+			return 'a' <= x && x <= 'z'
+		}).String()
+	}
+}
@@ -0,0 +1,70 @@
+package cliche
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InterruptError - scanning was stopped before the end of text
+type InterruptError struct {
+	Err     error // scanner.ErrStepsLimit or error of context
+	Steps   int   // count of steps made by scanner
+	Matches int   // count of matches found before interruption
+}
+
+func (e *InterruptError) Error() string {
+	return fmt.Sprintf(
+		"scanning interrupted after %d steps with %d matches: %s",
+		e.Steps,
+		e.Matches,
+		e.Err,
+	)
+}
+
+func (e *InterruptError) Unwrap() error {
+	return e.Err
+}
+
+// ExpressionError - expression can't be added to tree: Tree.Add and Tree.AddWithPayload
+// return it for expressions which can't be parsed, Tree.AddAll for invalid ones too,
+// Err is *parser.ParseError for parsers based on parser.CustomParser and parser.Translated
+type ExpressionError struct {
+	Index      int // index of expression in arguments
+	Expression string
+	Err        error
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("expression %d: %s", e.Index, e.Err)
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// AddAllError - errors of expressions which are not added by Tree.AddAll
+type AddAllError struct {
+	Errors []*ExpressionError // in order of expressions
+}
+
+func (e *AddAllError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("%d expressions are not added:", len(e.Errors)))
+
+	for _, err := range e.Errors {
+		lines = append(lines, err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap - errors of expressions for errors.Is and errors.As
+func (e *AddAllError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
@@ -0,0 +1,235 @@
+package interval
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Interval - runes from Lo to Hi inclusive.
+//
+// Functions of package work with sorted lists of intervals
+// which don't overlap and don't touch each other (see Normalize),
+// so operations on sets of runes take O(intervals) instead of iteration over runes.
+type Interval struct {
+	Lo rune
+	Hi rune
+}
+
+// Normalize - sort intervals and merge overlapped and adjacent intervals
+func Normalize(xs []Interval) []Interval {
+	sort.Slice(xs, func(i, j int) bool {
+		return xs[i].Lo < xs[j].Lo
+	})
+
+	result := make([]Interval, 0, len(xs))
+
+	for _, x := range xs {
+		if n := len(result); n > 0 && x.Lo <= result[n-1].Hi+1 {
+			result[n-1].Hi = max(result[n-1].Hi, x.Hi)
+		} else {
+			result = append(result, x)
+		}
+	}
+
+	return result
+}
+
+// OfRangeTable - sorted and merged intervals of runes of range table,
+// ranges with stride are split to single runes
+func OfRangeTable(tbl *unicode.RangeTable) []Interval {
+	result := make([]Interval, 0, len(tbl.R16)+len(tbl.R32))
+
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			result = append(result, Interval{Lo: lo, Hi: hi})
+			return
+		}
+
+		for x := lo; x <= hi; x += stride {
+			result = append(result, Interval{Lo: x, Hi: x})
+		}
+	}
+
+	for _, r := range tbl.R16 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	for _, r := range tbl.R32 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	return Normalize(result)
+}
+
+// ByPredicate - sorted and merged intervals of runes from 0 to max which satisfy p,
+// it iterates over all runes
+func ByPredicate(max rune, p func(rune) bool) []Interval {
+	result := make([]Interval, 0)
+
+	for x := rune(0); x <= max; x++ {
+		if !p(x) {
+			continue
+		}
+
+		if n := len(result); n > 0 && result[n-1].Hi == x-1 {
+			result[n-1].Hi = x
+		} else {
+			result = append(result, Interval{Lo: x, Hi: x})
+		}
+	}
+
+	return result
+}
+
+// Union - runes of both lists
+func Union(xs, ys []Interval) []Interval {
+	result := make([]Interval, 0, len(xs)+len(ys))
+
+	add := func(x Interval) {
+		if n := len(result); n > 0 && x.Lo <= result[n-1].Hi+1 {
+			result[n-1].Hi = max(result[n-1].Hi, x.Hi)
+		} else {
+			result = append(result, x)
+		}
+	}
+
+	i, j := 0, 0
+
+	for i < len(xs) && j < len(ys) {
+		if xs[i].Lo <= ys[j].Lo {
+			add(xs[i])
+			i++
+		} else {
+			add(ys[j])
+			j++
+		}
+	}
+
+	for ; i < len(xs); i++ {
+		add(xs[i])
+	}
+
+	for ; j < len(ys); j++ {
+		add(ys[j])
+	}
+
+	return result
+}
+
+// Intersect - runes which are in both lists
+func Intersect(xs, ys []Interval) []Interval {
+	result := make([]Interval, 0)
+
+	i, j := 0, 0
+
+	for i < len(xs) && j < len(ys) {
+		lo, hi := max(xs[i].Lo, ys[j].Lo), min(xs[i].Hi, ys[j].Hi)
+
+		if lo <= hi {
+			result = append(result, Interval{Lo: lo, Hi: hi})
+		}
+
+		if xs[i].Hi < ys[j].Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return result
+}
+
+// Overlaps - lists have common rune, like Intersect without allocation
+func Overlaps(xs, ys []Interval) bool {
+	i, j := 0, 0
+
+	for i < len(xs) && j < len(ys) {
+		switch {
+		case xs[i].Hi < ys[j].Lo:
+			i++
+		case ys[j].Hi < xs[i].Lo:
+			j++
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subtract - runes of xs which aren't in ys
+func Subtract(xs, ys []Interval) []Interval {
+	result := make([]Interval, 0, len(xs))
+	j := 0
+
+	for _, x := range xs {
+		for j < len(ys) && ys[j].Hi < x.Lo {
+			j++
+		}
+
+		lo := x.Lo
+
+		for k := j; k < len(ys) && ys[k].Lo <= x.Hi; k++ {
+			if ys[k].Lo > lo {
+				result = append(result, Interval{Lo: lo, Hi: ys[k].Lo - 1})
+			}
+
+			lo = max(lo, ys[k].Hi+1)
+		}
+
+		if lo <= x.Hi {
+			result = append(result, Interval{Lo: lo, Hi: x.Hi})
+		}
+	}
+
+	return result
+}
+
+// Subset - all runes of xs are in ys, like empty Subtract without allocation
+func Subset(xs, ys []Interval) bool {
+	j := 0
+
+	for _, x := range xs {
+		for lo := x.Lo; lo <= x.Hi; {
+			for j < len(ys) && ys[j].Hi < lo {
+				j++
+			}
+
+			if j == len(ys) || ys[j].Lo > lo {
+				return false
+			}
+
+			lo = ys[j].Hi + 1
+		}
+	}
+
+	return true
+}
+
+// Complement - runes from lo to hi which aren't in xs
+func Complement(xs []Interval, lo, hi rune) []Interval {
+	result := make([]Interval, 0, len(xs)+1)
+	next := lo
+
+	for _, x := range xs {
+		if x.Hi < next {
+			continue
+		}
+
+		if x.Lo > hi {
+			break
+		}
+
+		if x.Lo > next {
+			result = append(result, Interval{Lo: next, Hi: x.Lo - 1})
+		}
+
+		next = x.Hi + 1
+	}
+
+	if next <= hi {
+		result = append(result, Interval{Lo: next, Hi: hi})
+	}
+
+	return result
+}
@@ -0,0 +1,152 @@
+package interval
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterval_Operations(t *testing.T) {
+	t.Parallel()
+
+	type example struct {
+		name   string
+		xs     []Interval
+		ys     []Interval
+		union  []Interval
+		inter  []Interval
+		sub    []Interval
+		subset bool
+	}
+
+	tests := []example{
+		{
+			name:   "disjoint",
+			xs:     []Interval{{Lo: 'a', Hi: 'c'}},
+			ys:     []Interval{{Lo: 'x', Hi: 'z'}},
+			union:  []Interval{{Lo: 'a', Hi: 'c'}, {Lo: 'x', Hi: 'z'}},
+			inter:  []Interval{},
+			sub:    []Interval{{Lo: 'a', Hi: 'c'}},
+			subset: false,
+		},
+		{
+			name:   "adjacent are merged",
+			xs:     []Interval{{Lo: 'a', Hi: 'c'}},
+			ys:     []Interval{{Lo: 'd', Hi: 'f'}},
+			union:  []Interval{{Lo: 'a', Hi: 'f'}},
+			inter:  []Interval{},
+			sub:    []Interval{{Lo: 'a', Hi: 'c'}},
+			subset: false,
+		},
+		{
+			name:   "overlapped",
+			xs:     []Interval{{Lo: 'a', Hi: 'm'}},
+			ys:     []Interval{{Lo: 'k', Hi: 'z'}},
+			union:  []Interval{{Lo: 'a', Hi: 'z'}},
+			inter:  []Interval{{Lo: 'k', Hi: 'm'}},
+			sub:    []Interval{{Lo: 'a', Hi: 'j'}},
+			subset: false,
+		},
+		{
+			name:   "hole in the middle",
+			xs:     []Interval{{Lo: 'a', Hi: 'z'}},
+			ys:     []Interval{{Lo: 'k', Hi: 'm'}, {Lo: 'x', Hi: 'x'}},
+			union:  []Interval{{Lo: 'a', Hi: 'z'}},
+			inter:  []Interval{{Lo: 'k', Hi: 'm'}, {Lo: 'x', Hi: 'x'}},
+			sub:    []Interval{{Lo: 'a', Hi: 'j'}, {Lo: 'n', Hi: 'w'}, {Lo: 'y', Hi: 'z'}},
+			subset: false,
+		},
+		{
+			name:   "subset of few intervals",
+			xs:     []Interval{{Lo: 'b', Hi: 'c'}, {Lo: 'x', Hi: 'y'}},
+			ys:     []Interval{{Lo: 'a', Hi: 'd'}, {Lo: 'w', Hi: 'z'}},
+			union:  []Interval{{Lo: 'a', Hi: 'd'}, {Lo: 'w', Hi: 'z'}},
+			inter:  []Interval{{Lo: 'b', Hi: 'c'}, {Lo: 'x', Hi: 'y'}},
+			sub:    []Interval{},
+			subset: true,
+		},
+		{
+			name:   "empty",
+			xs:     nil,
+			ys:     []Interval{{Lo: 'a', Hi: 'z'}},
+			union:  []Interval{{Lo: 'a', Hi: 'z'}},
+			inter:  []Interval{},
+			sub:    []Interval{},
+			subset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.union, Union(test.xs, test.ys))
+			require.Equal(t, test.union, Union(test.ys, test.xs))
+			require.Equal(t, test.inter, Intersect(test.xs, test.ys))
+			require.Equal(t, test.inter, Intersect(test.ys, test.xs))
+			require.Equal(t, test.sub, Subtract(test.xs, test.ys))
+			require.Equal(t, test.subset, Subset(test.xs, test.ys))
+			require.Equal(t, len(test.inter) > 0, Overlaps(test.xs, test.ys))
+			require.Equal(t, len(test.inter) > 0, Overlaps(test.ys, test.xs))
+		})
+	}
+}
+
+func TestInterval_Normalize(t *testing.T) {
+	t.Parallel()
+
+	actual := Normalize([]Interval{
+		{Lo: 'x', Hi: 'z'},
+		{Lo: 'a', Hi: 'c'},
+		{Lo: 'b', Hi: 'f'},
+		{Lo: 'g', Hi: 'g'},
+	})
+
+	require.Equal(t, []Interval{{Lo: 'a', Hi: 'g'}, {Lo: 'x', Hi: 'z'}}, actual)
+}
+
+func TestInterval_Complement(t *testing.T) {
+	t.Parallel()
+
+	xs := []Interval{{Lo: 0, Hi: 'a'}, {Lo: 'x', Hi: 'z'}}
+
+	require.Equal(t,
+		[]Interval{{Lo: 'b', Hi: 'w'}, {Lo: '{', Hi: unicode.MaxRune}},
+		Complement(xs, 0, unicode.MaxRune),
+	)
+	require.Equal(t,
+		[]Interval{{Lo: 'b', Hi: 'w'}},
+		Complement(xs, 1, 'z'),
+	)
+	require.Equal(t,
+		[]Interval{{Lo: 1, Hi: 10}},
+		Complement(nil, 1, 10),
+	)
+}
+
+func TestInterval_OfRangeTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 'a', Hi: 'c', Stride: 1},
+			{Lo: 'd', Hi: 'h', Stride: 2},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x10000, Hi: 0x10010, Stride: 1},
+		},
+	}
+
+	require.Equal(t,
+		[]Interval{{Lo: 'a', Hi: 'd'}, {Lo: 'f', Hi: 'f'}, {Lo: 'h', Hi: 'h'}, {Lo: 0x10000, Hi: 0x10010}},
+		OfRangeTable(tbl),
+	)
+
+	require.Equal(t,
+		OfRangeTable(unicode.Digit),
+		ByPredicate(unicode.MaxRune, unicode.IsDigit),
+	)
+}
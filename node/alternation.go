@@ -75,8 +75,6 @@ func (n *alternation) VisitAlternation(
 
 	for _, variant := range n.variants {
 		stop := false
-		emptVariant := true
-		lastNotEmptyTo := from
 
 		variant.Visit(
 			scanner,
@@ -84,17 +82,19 @@ func (n *alternation) VisitAlternation(
 			from,
 			to,
 			func(x Node, vFrom, vTo int, empty bool) {
-				if !empty {
-					lastNotEmptyTo = vTo
-					emptVariant = false
+				if len(x.GetNestedNodes()) > 0 {
+					return
 				}
 
-				if len(x.GetNestedNodes()) == 0 {
-					if emptVariant {
-						stop = stop || match(variant, from, from, true)
-					} else {
-						stop = stop || match(variant, from, lastNotEmptyTo, false)
-					}
+				// empty leaf ends right after the previous node of the same path,
+				// so variant is empty only when leaf starts at its beginning
+				switch {
+				case !empty:
+					stop = stop || match(variant, from, vTo, false)
+				case vFrom > from:
+					stop = stop || match(variant, from, vFrom-1, false)
+				default:
+					stop = stop || match(variant, from, from, true)
 				}
 			},
 		)
@@ -133,20 +133,10 @@ func (n *alternation) Size() (int, bool) {
 	return 0, false
 }
 
-func (n *alternation) copyVariants() []Node {
-	variants := make([]Node, len(n.variants))
-
-	for i, x := range n.variants {
-		variants[i] = x.Copy()
-	}
-
-	return variants
-}
-
 func (n *alternation) Copy() Node {
-	return NewAlternation(n.copyVariants())
+	return NewAlternation(n.variants)
 }
 
 func (n *alternation) CopyAlternation() Alternation {
-	return NewAlternation(n.copyVariants())
+	return NewAlternation(n.variants)
 }
@@ -0,0 +1,359 @@
+package node
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/okneniz/cliche/interval"
+)
+
+// RiskKind - how fast count of backtracking steps grows with size of input
+type RiskKind int
+
+const (
+	RiskPolynomial RiskKind = iota + 1
+	RiskExponential
+)
+
+func (k RiskKind) String() string {
+	switch k {
+	case RiskPolynomial:
+		return "polynomial"
+	case RiskExponential:
+		return "exponential"
+	}
+
+	return fmt.Sprintf("RiskKind(%d)", k)
+}
+
+// Risk - sub-expression which can cause catastrophic backtracking
+type Risk struct {
+	Kind          RiskKind
+	Expression    string
+	SubExpression string // offending node written like (a+)+
+	Reason        string
+}
+
+func (r Risk) Error() string {
+	return fmt.Sprintf(
+		"%s backtracking in /%s/ at %s: %s",
+		r.Kind,
+		r.Expression,
+		r.SubExpression,
+		r.Reason,
+	)
+}
+
+// Analyze - find sub-expressions with exponential or polynomial backtracking,
+// it's heuristic which compare sets of first runes of nodes,
+// so it can report false positives like (a|ab)+
+func Analyze(expression string, start Node) []Risk {
+	a := &analyzer{expression: expression}
+	a.chain(start, runeSet{}, repeats{})
+	return a.risks
+}
+
+// CheckBacktracking - validator which reject expressions with any backtracking risk
+func CheckBacktracking(expression string, start Node) error {
+	if risks := Analyze(expression, start); len(risks) > 0 {
+		return risks[0]
+	}
+
+	return nil
+}
+
+type analyzer struct {
+	expression string
+	risks      []Risk
+}
+
+// repeats - count of repetitions around chain
+type repeats struct {
+	endless int // like x+ and x{2,}
+	bounded int // like x{3} and x{2,5}
+}
+
+// chain - analyze node and its nested nodes,
+// after is set of runes which can follow the end of chain
+func (a *analyzer) chain(n Node, after runeSet, loops repeats) {
+	next := after
+
+	if nested := n.GetNestedNodes(); len(nested) > 0 {
+		next = runeSet{}
+
+		for _, x := range nested {
+			next = next.union(firstOf(x, after))
+			a.chain(x, after, loops)
+
+			a.sequence(n, x)
+		}
+	}
+
+	a.node(n, next, loops)
+}
+
+func (a *analyzer) node(n Node, next runeSet, loops repeats) {
+	switch x := n.(type) {
+	case *quantifier:
+		value := firstOf(x.value, runeSet{})
+
+		switch {
+		case !value.overlaps(next):
+		case loops.endless > 0 && x.variable():
+			a.report(RiskExponential, x, "nested repetitions of overlapping runes")
+		case loops.bounded > 0 && x.quantity.Endless():
+			// like (a+){3}, count of ways to split input grows as power of count of repetitions
+			a.report(RiskPolynomial, x, "endless repetition inside of bounded repetition of overlapping runes")
+		}
+
+		to, bounded := x.quantity.To()
+
+		if !bounded || to > 1 {
+			next = next.union(value)
+		}
+
+		switch {
+		case x.quantity.Endless():
+			loops.endless++
+		case to > 1:
+			loops.bounded++
+		}
+
+		a.chain(x.value, next, loops)
+	case Alternation:
+		if loops.endless > 0 {
+			a.alternation(x, next)
+		}
+
+		for _, variant := range x.GetVariants() {
+			a.chain(variant, next, loops)
+		}
+	case *atomicGroup:
+		// no backtracking into atomic group
+		a.chain(x.value, next, repeats{})
+	case *lookAhead, *negativeLookAhead, *lookBehind, *negativeLookBehind:
+		// zero-width assertions are checked separately from expression
+		a.chain(x.(Container).GetValue(), runeSet{}, repeats{})
+	case Container:
+		a.chain(x.GetValue(), next, loops)
+	case *condition:
+		a.chain(x.yes, next, loops)
+
+		if x.no != nil {
+			a.chain(x.no, next, loops)
+		}
+	}
+}
+
+// sequence - check repetitions of overlapping runes one after another like \d+\d+
+func (a *analyzer) sequence(prev, next Node) {
+	p, ok := prev.(*quantifier)
+	if !ok || !p.quantity.Endless() {
+		return
+	}
+
+	n, ok := next.(*quantifier)
+	if !ok || !n.quantity.Endless() {
+		return
+	}
+
+	if firstOf(p.value, runeSet{}).overlaps(firstOf(n.value, runeSet{})) {
+		a.report(RiskPolynomial, next, "adjacent repetitions of overlapping runes")
+	}
+}
+
+// alternation - check variants which can start from the same rune inside repetition
+func (a *analyzer) alternation(n Alternation, next runeSet) {
+	variants := n.GetVariants()
+	firsts := make([]runeSet, len(variants))
+
+	for i, variant := range variants {
+		firsts[i] = firstOf(variant, next)
+	}
+
+	for i := range firsts {
+		for j := i + 1; j < len(firsts); j++ {
+			if firsts[i].overlaps(firsts[j]) {
+				a.report(RiskExponential, n, "repetition of overlapping alternatives")
+				return
+			}
+		}
+	}
+}
+
+func (a *analyzer) report(kind RiskKind, n Node, reason string) {
+	sub := subExpression(n)
+
+	for _, x := range a.risks {
+		if x.Kind == kind && x.SubExpression == sub {
+			return
+		}
+	}
+
+	a.risks = append(a.risks, Risk{
+		Kind:          kind,
+		Expression:    a.expression,
+		SubExpression: sub,
+		Reason:        reason,
+	})
+}
+
+// risky - dialect to write offending nodes, analyzer doesn't know dialect of expression,
+// so predefined tables are written as first ranges of classes like [0-9A-Z_a-z…]
+var risky = &Dialect{
+	Escapes:    meta,
+	NamedGroup: "?<",
+	Options: map[ScanOption]rune{
+		ScanOptionCaseInsensetive: 'i',
+		ScanOptionMultiline:       'm',
+	},
+	Backtracking: true,
+}
+
+// subExpression - write node without its continuations,
+// key of node is returned if node can't be written
+func subExpression(n Node) string {
+	f := &formatter{dialect: risky, classLimit: 4}
+
+	if err := f.node(n); err != nil {
+		return n.GetKey()
+	}
+
+	return f.b.String()
+}
+
+// variable - quantifier can repeat value different count of times
+func (n *quantifier) variable() bool {
+	to, ok := n.quantity.To()
+	return !ok || to != n.quantity.From()
+}
+
+// firstOf - return set of runes which can be read first by chain,
+// after is set of runes which can follow the end of chain
+func firstOf(n Node, after runeSet) runeSet {
+	next := after
+
+	if nested := n.GetNestedNodes(); len(nested) > 0 {
+		next = runeSet{}
+
+		for _, x := range nested {
+			next = next.union(firstOf(x, after))
+		}
+	}
+
+	switch x := n.(type) {
+	case *class:
+		return runeSet{tables: []Table{x.table}}
+	case *negativeClass:
+		return runeSet{negated: []Table{x.table}}
+	case *dot, *referenceNode, *nameReferenceNode, *condition:
+		return runeSet{any: true}
+	case *quantifier:
+		value := firstOf(x.value, next)
+
+		if x.quantity.Optional() {
+			return value.union(next)
+		}
+
+		return value
+	case Alternation:
+		set := runeSet{}
+
+		for _, variant := range x.GetVariants() {
+			set = set.union(firstOf(variant, next))
+		}
+
+		return set
+	case *lookAhead, *negativeLookAhead, *lookBehind, *negativeLookBehind:
+		return next
+	case Container:
+		return firstOf(x.GetValue(), next)
+	}
+
+	// anchors, comments, options switchers and other zero-width nodes
+	return next
+}
+
+// runeSet - union of tables and negated tables
+type runeSet struct {
+	any     bool
+	tables  []Table
+	negated []Table
+}
+
+func (s runeSet) union(other runeSet) runeSet {
+	return runeSet{
+		any:     s.any || other.any,
+		tables:  append(s.tables[:len(s.tables):len(s.tables)], other.tables...),
+		negated: append(s.negated[:len(s.negated):len(s.negated)], other.negated...),
+	}
+}
+
+func (s runeSet) empty() bool {
+	return !s.any && len(s.tables) == 0 && len(s.negated) == 0
+}
+
+func (s runeSet) overlaps(other runeSet) bool {
+	if s.empty() || other.empty() {
+		return false
+	}
+
+	if s.any || other.any {
+		return true
+	}
+
+	xs, xsNegated := intervalsOfTables(s.tables), intervalsOfTables(s.negated)
+	ys, ysNegated := intervalsOfTables(other.tables), intervalsOfTables(other.negated)
+
+	for _, x := range xs {
+		for _, y := range ys {
+			if interval.Overlaps(x, y) {
+				return true
+			}
+		}
+
+		for _, y := range ysNegated {
+			if !interval.Subset(x, y) {
+				return true
+			}
+		}
+	}
+
+	for _, x := range xsNegated {
+		for _, y := range ys {
+			if !interval.Subset(y, x) {
+				return true
+			}
+		}
+
+		for _, y := range ysNegated {
+			if !interval.Subset(everyRune, interval.Union(x, y)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var everyRune = []interval.Interval{{Lo: 0, Hi: unicode.MaxRune}}
+
+func intervalsOfTables(tables []Table) [][]interval.Interval {
+	result := make([][]interval.Interval, len(tables))
+
+	for i, tbl := range tables {
+		result[i] = intervalsOf(tbl)
+	}
+
+	return result
+}
+
+// intervalsOf - return sorted and merged intervals of runes of table,
+// iterate over all runes only if table isn't RangeTable
+func intervalsOf(tbl Table) []interval.Interval {
+	if x, ok := tbl.(RangeTable); ok {
+		return interval.OfRangeTable(x.RangeTable())
+	}
+
+	return interval.ByPredicate(unicode.MaxRune, tbl.Include)
+}
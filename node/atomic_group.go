@@ -28,6 +28,51 @@ func (n *atomicGroup) GetValue() Node {
 func (n *atomicGroup) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	pos := scanner.Position()
 	groupsPos := scanner.GroupsPosition()
+	lazyPos := scanner.LazyPosition()
+
+	// find the preferred match of the first matched variant
+	// (the longest one or with less sizes of lazy quantifiers),
+	// because engine can't backtrack into the group after it
+	var (
+		matched  Node
+		count    int
+		best     int
+		end      int
+		endEmpty bool
+		lazy     []int
+	)
+
+	n.value.VisitAlternation(
+		scanner,
+		input,
+		from,
+		to,
+		func(variant Node, vFrom, vTo int, empty bool) bool {
+			if matched != nil && matched != variant {
+				return true // stop on next variant
+			}
+
+			sizes := lazySizes(scanner, lazyPos)
+			z := CompareLazy(sizes, lazy)
+
+			if matched == nil || z > 0 || (z == 0 && (endEmpty || (!empty && vTo > end))) {
+				matched, best, end, endEmpty, lazy = variant, count, vTo, empty, sizes
+			}
+
+			count++
+			return false
+		},
+	)
+
+	scanner.Rewind(pos)
+	scanner.RewindGroups(groupsPos)
+	scanner.RewindLazy(lazyPos)
+
+	if matched == nil {
+		return
+	}
+
+	count = 0
 
 	n.value.VisitAlternation(
 		scanner,
@@ -35,6 +80,11 @@ func (n *atomicGroup) Visit(scanner Scanner, input Input, from, to int, match Ca
 		from,
 		to,
 		func(variant Node, vFrom, vTo int, empty bool) bool {
+			if count != best {
+				count++
+				return false
+			}
+
 			match(n, from, vTo, empty)
 
 			// throws away all backtracking positions
@@ -43,11 +93,25 @@ func (n *atomicGroup) Visit(scanner Scanner, input Input, from, to int, match Ca
 			nextFrom := nextFor(vTo, empty)
 			n.base.VisitNested(scanner, input, nextFrom, to, match)
 
-			return true // stop on first variant
+			return true
 		},
 	)
 
 	scanner.Rewind(pos)
+	scanner.RewindLazy(lazyPos)
+}
+
+// lazySizes - return sizes of lazy quantifiers matched after position
+func lazySizes(scanner Scanner, pos int) []int {
+	sizes := make([]int, 0)
+
+	for i := pos; i < scanner.LazyPosition(); i++ {
+		if x, ok := scanner.GetLazy(i); ok {
+			sizes = append(sizes, x)
+		}
+	}
+
+	return sizes
 }
 
 // TODO : move to groupBase
@@ -62,5 +126,5 @@ func (n *atomicGroup) Size() (int, bool) {
 }
 
 func (n *atomicGroup) Copy() Node {
-	return NewAtomicGroup(n.value.CopyAlternation())
+	return NewAtomicGroup(n.value)
 }
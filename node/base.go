@@ -6,7 +6,7 @@ import (
 
 type base struct {
 	key         string
-	expressions structs.Set[string]
+	expressions structs.Set[Expression]
 	nested      map[string]Node
 }
 
@@ -14,7 +14,7 @@ func newBase(key string) *base {
 	n := new(base)
 	n.key = key
 	n.nested = make(map[string]Node)
-	n.expressions = structs.NewMapSet[string]()
+	n.expressions = structs.NewMapSet[Expression]()
 	return n
 }
 
@@ -26,11 +26,11 @@ func (n *base) GetNestedNodes() map[string]Node {
 	return n.nested
 }
 
-func (n *base) GetExpressions() structs.Set[string] {
+func (n *base) GetExpressions() structs.Set[Expression] {
 	return n.expressions
 }
 
-func (n *base) AddExpression(exp string) {
+func (n *base) AddExpression(exp Expression) {
 	n.expressions.Add(exp)
 }
 
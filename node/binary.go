@@ -0,0 +1,556 @@
+package node
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+
+	"github.com/okneniz/cliche/quantity"
+)
+
+// BinaryVersion - version of format of MarshalNodes,
+// it must be changed with any change of format or keys of nodes
+const BinaryVersion = 1
+
+// binaryMagic - prefix of serialized nodes
+const binaryMagic = "cliche"
+
+var (
+	// ErrNoBinary - node can't be serialized,
+	// like condition with custom predicate or class with table which is not RangeTable
+	ErrNoBinary = errors.New("node can't be serialized")
+	// ErrBinaryVersion - nodes are serialized by another version of format
+	ErrBinaryVersion = errors.New("unsupported version of serialized nodes")
+)
+
+// MarshalNodes - write nodes with nested nodes, tables, quantities and expressions
+// to w in versioned binary format (see UnmarshalNodes).
+//
+// Payloads of expressions are encoded by encoding/gob,
+// so their types must be registered by gob.Register.
+func MarshalNodes(w io.Writer, nodes []Node) error {
+	records := make([]*record, 0, len(nodes))
+
+	for _, n := range nodes {
+		r, err := newRecord(n)
+		if err != nil {
+			return err
+		}
+
+		records = append(records, r)
+	}
+
+	header := binary.AppendUvarint([]byte(binaryMagic), BinaryVersion)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	return gob.NewEncoder(w).Encode(records)
+}
+
+// UnmarshalNodes - read nodes written by MarshalNodes,
+// newTable must return table with the same key (String) as serialized table has.
+func UnmarshalNodes(
+	r io.Reader,
+	newTable func(*unicode.RangeTable) Table,
+) ([]Node, error) {
+	input := bufio.NewReader(r)
+
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(input, magic); err != nil {
+		return nil, err
+	}
+
+	if string(magic) != binaryMagic {
+		return nil, fmt.Errorf("%w: unknown prefix %q", ErrBinaryVersion, magic)
+	}
+
+	version, err := binary.ReadUvarint(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != BinaryVersion {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrBinaryVersion, version, BinaryVersion)
+	}
+
+	records := make([]*record, 0)
+	if err := gob.NewDecoder(input).Decode(&records); err != nil {
+		return nil, err
+	}
+
+	d := &decoder{newTable: newTable}
+	nodes := make([]Node, 0, len(records))
+
+	for _, x := range records {
+		n, err := d.node(x)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+type recordType uint8
+
+const (
+	recordAlternation recordType = iota + 1
+	recordAtomicGroup
+	recordClass
+	recordNegativeClass
+	recordComment
+	recordCondition
+	recordDot
+	recordEndOfLine
+	recordEndOfString
+	recordEndOfStringAndNewLine
+	recordGroup
+	recordKeep
+	recordLookAhead
+	recordLookBehind
+	recordNamedGroup
+	recordNameReference
+	recordNegativeLookAhead
+	recordNegativeLookBehind
+	recordNonWordBoundary
+	recordNotCapturedGroup
+	recordOptionsSwitcher
+	recordQuantifier
+	recordReference
+	recordStartOfLine
+	recordStartOfString
+	recordSubmatch
+	recordWordBoundary
+)
+
+// record - serialized node, only fields of its type are filled
+type record struct {
+	Type        recordType
+	Key         string // to check that node is restored with the same key
+	Expressions []Expression
+	Nested      []*record
+
+	Value    *record   // value of container or branch "yes" of condition
+	No       *record   // branch "no" of condition
+	Variants []*record // variants of alternation
+
+	Table     *unicode.RangeTable
+	Folded    bool // table of class is closed under case folding
+	Quantity  *quantityRecord
+	Predicate *predicateRecord
+	Index     int
+	Parent    int
+	Name      string
+	AnyLength bool // look-behind of any length
+	Enable    []ScanOption
+	Disable   []ScanOption
+}
+
+type quantityRecord struct {
+	From       int
+	To         int
+	Endless    bool
+	Lazy       bool
+	Possessive bool
+}
+
+type predicateRecord struct {
+	Key   string
+	Named bool
+}
+
+func newRecord(n Node) (*record, error) {
+	r := &record{
+		Key:         n.GetKey(),
+		Expressions: n.GetExpressions().Slice(),
+		Nested:      make([]*record, 0, len(n.GetNestedNodes())),
+	}
+
+	if err := r.fill(n); err != nil {
+		return nil, err
+	}
+
+	for _, nested := range n.GetNestedNodes() {
+		x, err := newRecord(nested)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Nested = append(r.Nested, x)
+	}
+
+	return r, nil
+}
+
+// fill - fill type and fields of node
+func (r *record) fill(n Node) error {
+	var err error
+
+	switch x := n.(type) {
+	case *alternation:
+		r.Type = recordAlternation
+		r.Variants, err = newRecords(x.variants)
+	case *atomicGroup:
+		r.Type = recordAtomicGroup
+		r.Value, err = newRecord(x.value)
+	case *class:
+		r.Type = recordClass
+		r.Folded = x.folded
+		r.Table, err = tableRecord(x.table)
+	case *negativeClass:
+		r.Type = recordNegativeClass
+		r.Folded = x.folded
+		r.Table, err = tableRecord(x.table)
+	case *comment:
+		r.Type = recordComment
+		r.Name = x.text
+	case *condition:
+		r.Type = recordCondition
+		err = r.fillCondition(x)
+	case *dot:
+		r.Type = recordDot
+	case *endOfLine:
+		r.Type = recordEndOfLine
+	case *endOfString:
+		r.Type = recordEndOfString
+	case *endOfStringAndNewLine:
+		r.Type = recordEndOfStringAndNewLine
+	case *group:
+		r.Type = recordGroup
+		r.Value, err = newRecord(x.value)
+	case *keep:
+		r.Type = recordKeep
+	case *lookAhead:
+		r.Type = recordLookAhead
+		r.Value, err = newRecord(x.value)
+	case *lookBehind:
+		r.Type = recordLookBehind
+		r.AnyLength = x.subExpressionSize == anyLength
+		r.Value, err = newRecord(x.value)
+	case *namedGroup:
+		r.Type = recordNamedGroup
+		r.Name = x.name
+		r.Value, err = newRecord(x.value)
+	case *nameReferenceNode:
+		r.Type = recordNameReference
+		r.Name = x.name
+	case *negativeLookAhead:
+		r.Type = recordNegativeLookAhead
+		r.Value, err = newRecord(x.value)
+	case *negativeLookBehind:
+		r.Type = recordNegativeLookBehind
+		r.AnyLength = x.subExpressionSize == anyLength
+		r.Value, err = newRecord(x.value)
+	case *nonWordBoundary:
+		r.Type = recordNonWordBoundary
+	case *notCapturedGroup:
+		r.Type = recordNotCapturedGroup
+		r.Value, err = newRecord(x.value)
+	case *optionsSwitcher:
+		r.Type = recordOptionsSwitcher
+		r.Enable = x.enable
+		r.Disable = x.disable
+	case *quantifier:
+		r.Type = recordQuantifier
+		r.Quantity = newQuantityRecord(x.quantity)
+		r.Value, err = newRecord(x.value)
+	case *referenceNode:
+		r.Type = recordReference
+		r.Index = x.index
+	case *startOfLine:
+		r.Type = recordStartOfLine
+	case *startOfString:
+		r.Type = recordStartOfString
+	case *submatch:
+		r.Type = recordSubmatch
+		r.Index = x.index
+		r.Parent = x.parent
+		r.Value, err = newRecord(x.value)
+	case *wordBoundary:
+		r.Type = recordWordBoundary
+	default:
+		return fmt.Errorf("%w: %s", ErrNoBinary, n.GetKey())
+	}
+
+	return err
+}
+
+func (r *record) fillCondition(n *condition) error {
+	if n.cond.kind == customPredicate {
+		return fmt.Errorf("%w: custom predicate of %s", ErrNoBinary, n.GetKey())
+	}
+
+	r.Predicate = &predicateRecord{
+		Key:   n.cond.key,
+		Named: n.cond.kind == namedGroupPredicate,
+	}
+
+	var err error
+
+	r.Value, err = newRecord(n.yes)
+	if err != nil {
+		return err
+	}
+
+	if n.no != nil {
+		r.No, err = newRecord(n.no)
+	}
+
+	return err
+}
+
+func newRecords(nodes []Node) ([]*record, error) {
+	records := make([]*record, 0, len(nodes))
+
+	for _, n := range nodes {
+		r, err := newRecord(n)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+func tableRecord(table Table) (*unicode.RangeTable, error) {
+	rt, ok := table.(RangeTable)
+	if !ok {
+		return nil, fmt.Errorf("%w: table %s", ErrNoBinary, table)
+	}
+
+	return rt.RangeTable(), nil
+}
+
+func newQuantityRecord(q *quantity.Quantity) *quantityRecord {
+	to, _ := q.To()
+
+	return &quantityRecord{
+		From:       q.From(),
+		To:         to,
+		Endless:    q.Endless(),
+		Lazy:       q.Lazy(),
+		Possessive: q.Possessive(),
+	}
+}
+
+type decoder struct {
+	newTable func(*unicode.RangeTable) Table
+}
+
+func (d *decoder) node(r *record) (Node, error) {
+	n, err := d.value(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.GetKey() != r.Key {
+		return nil, fmt.Errorf(
+			"%w: node %s is restored as %s",
+			ErrBinaryVersion,
+			r.Key,
+			n.GetKey(),
+		)
+	}
+
+	for _, x := range r.Nested {
+		nested, err := d.node(x)
+		if err != nil {
+			return nil, err
+		}
+
+		n.GetNestedNodes()[nested.GetKey()] = nested
+	}
+
+	for _, x := range r.Expressions {
+		n.AddExpression(x)
+	}
+
+	return n, nil
+}
+
+// value - make node without nested nodes and expressions
+func (d *decoder) value(r *record) (Node, error) {
+	switch r.Type {
+	case recordAlternation:
+		variants, err := d.nodes(r.Variants)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAlternation(variants), nil
+	case recordClass:
+		if r.Folded {
+			return NewFoldedClass(d.newTable(r.Table)), nil
+		}
+
+		return NewClass(d.newTable(r.Table)), nil
+	case recordNegativeClass:
+		if r.Folded {
+			return NewFoldedNegativeClass(d.newTable(r.Table)), nil
+		}
+
+		return NewNegativeClass(d.newTable(r.Table)), nil
+	case recordComment:
+		return NewComment(r.Name), nil
+	case recordCondition:
+		return d.condition(r)
+	case recordDot:
+		return NewDot(), nil
+	case recordEndOfLine:
+		return NewEndOfLine(), nil
+	case recordEndOfString:
+		return NewEndOfString(), nil
+	case recordEndOfStringAndNewLine:
+		return NewEndOfStringAndNewLine(), nil
+	case recordKeep:
+		return NewKeep(), nil
+	case recordNameReference:
+		return NewForNameReference(r.Name), nil
+	case recordNonWordBoundary:
+		return NewNonWordBoundary(), nil
+	case recordOptionsSwitcher:
+		return NewOptionsSwitcher(r.Enable, r.Disable), nil
+	case recordReference:
+		return NodeForReference(r.Index), nil
+	case recordStartOfLine:
+		return NewStartOfLine(), nil
+	case recordStartOfString:
+		return NewStartOfString(), nil
+	case recordWordBoundary:
+		return NewWordBoundary(), nil
+	case recordQuantifier:
+		value, err := d.node(r.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewQuantifier(r.Quantity.quantity(), value), nil
+	}
+
+	return d.container(r)
+}
+
+// container - make node with alternation as value
+func (d *decoder) container(r *record) (Node, error) {
+	if r.Value == nil || r.Value.Type != recordAlternation {
+		return nil, fmt.Errorf("%w: unknown node %s", ErrBinaryVersion, r.Key)
+	}
+
+	value, err := d.node(r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	alt := value.(Alternation)
+
+	switch r.Type {
+	case recordAtomicGroup:
+		return NewAtomicGroup(alt), nil
+	case recordGroup:
+		return NewGroup(alt), nil
+	case recordLookAhead:
+		return NewLookAhead(alt), nil
+	case recordLookBehind:
+		if r.AnyLength {
+			return NewLookBehindOfAnyLength(alt), nil
+		}
+
+		return NewLookBehind(alt)
+	case recordNamedGroup:
+		return NewNamedGroup(r.Name, alt), nil
+	case recordNegativeLookAhead:
+		return NewNegativeLookAhead(alt), nil
+	case recordNegativeLookBehind:
+		if r.AnyLength {
+			return NewNegativeLookBehindOfAnyLength(alt), nil
+		}
+
+		return NewNegativeLookBehind(alt)
+	case recordNotCapturedGroup:
+		return NewNotCapturedGroup(alt), nil
+	case recordSubmatch:
+		return NewSubmatch(r.Index, r.Parent, alt), nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown node %s", ErrBinaryVersion, r.Key)
+}
+
+func (d *decoder) condition(r *record) (Node, error) {
+	if r.Predicate == nil || r.Value == nil {
+		return nil, fmt.Errorf("%w: unknown node %s", ErrBinaryVersion, r.Key)
+	}
+
+	var cond *Predicate
+
+	if r.Predicate.Named {
+		cond = NewNamedGroupPredicate(r.Predicate.Key)
+	} else {
+		index, err := strconv.Atoi(r.Predicate.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBinaryVersion, err)
+		}
+
+		cond = NewGroupPredicate(index)
+	}
+
+	yes, err := d.node(r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.No == nil {
+		return NewGuard(cond, yes), nil
+	}
+
+	no, err := d.node(r.No)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCondition(cond, yes, no), nil
+}
+
+func (d *decoder) nodes(records []*record) ([]Node, error) {
+	nodes := make([]Node, 0, len(records))
+
+	for _, r := range records {
+		n, err := d.node(r)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+func (r *quantityRecord) quantity() *quantity.Quantity {
+	var q *quantity.Quantity
+
+	if r.Endless {
+		q = quantity.NewEndlessQuantity(r.From)
+	} else {
+		q = quantity.New(r.From, r.To)
+	}
+
+	if r.Lazy {
+		q = q.AsLazy()
+	}
+
+	if r.Possessive {
+		q = q.AsPossessive()
+	}
+
+	return q
+}
@@ -1,11 +1,8 @@
 package node
 
-import (
-	"unicode"
-)
-
 type class struct {
-	table Table
+	table  Table
+	folded bool // table is closed under case folding
 	*base
 }
 
@@ -16,6 +13,15 @@ func NewClass(table Table) Node {
 	}
 }
 
+// NewFoldedClass - class with table which includes all runes of orbits
+// of case folding of its runes (see FoldCase), it doesn't fold runes on scanning,
+// the key is the same as key of NewClass of the same table
+func NewFoldedClass(table Table) Node {
+	n := NewClass(table).(*class)
+	n.folded = true
+	return n
+}
+
 func (n *class) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	if from >= input.Size() {
 		return
@@ -24,8 +30,8 @@ func (n *class) Visit(scanner Scanner, input Input, from, to int, match Callback
 	x := input.ReadAt(from)
 	matched := false
 
-	if scanner.OptionsInclude(ScanOptionCaseInsensetive) {
-		matched = n.table.Include(unicode.ToUpper(x)) || n.table.Include(unicode.ToLower(x))
+	if !n.folded && scanner.OptionsInclude(ScanOptionCaseInsensetive) {
+		matched = includeFold(n.table, x)
 	} else {
 		matched = n.table.Include(x)
 	}
@@ -47,5 +53,9 @@ func (n *class) Size() (int, bool) {
 }
 
 func (n *class) Copy() Node {
+	if n.folded {
+		return NewFoldedClass(n.table)
+	}
+
 	return NewClass(n.table)
 }
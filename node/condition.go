@@ -1,6 +1,9 @@
 package node
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 type condition struct {
 	cond *Predicate
@@ -12,8 +15,19 @@ type condition struct {
 type Predicate struct {
 	key string
 	fun func(scanner Scanner) bool
+	// kind - reference to group of predicates which can be serialized
+	kind predicateKind
 }
 
+type predicateKind uint8
+
+const (
+	customPredicate predicateKind = iota
+	groupPredicate
+	namedGroupPredicate
+)
+
+// NewPredicate - custom predicate, nodes with it can't be serialized (see MarshalNodes)
 func NewPredicate(key string, f func(scanner Scanner) bool) *Predicate {
 	return &Predicate{
 		key: key,
@@ -21,6 +35,30 @@ func NewPredicate(key string, f func(scanner Scanner) bool) *Predicate {
 	}
 }
 
+// NewGroupPredicate - predicate is true if group is matched, like (?(1)...)
+func NewGroupPredicate(index int) *Predicate {
+	return &Predicate{
+		key: strconv.Itoa(index),
+		fun: func(s Scanner) bool {
+			_, matched := s.GetGroup(index)
+			return matched
+		},
+		kind: groupPredicate,
+	}
+}
+
+// NewNamedGroupPredicate - predicate is true if named group is matched, like (?(<name>)...)
+func NewNamedGroupPredicate(name string) *Predicate {
+	return &Predicate{
+		key: name,
+		fun: func(s Scanner) bool {
+			_, matched := s.GetNamedGroup(name)
+			return matched
+		},
+		kind: namedGroupPredicate,
+	}
+}
+
 func NewGuard(cond *Predicate, yes Node) Node {
 	return &condition{
 		cond: cond,
@@ -54,32 +92,44 @@ func NewCondition(cond *Predicate, yes Node, no Node) Node {
 func (n *condition) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	pos := scanner.Position()
 
+	branch := n.no
 	if n.cond.fun(scanner) {
-		n.yes.Visit(
-			scanner,
-			input,
-			from,
-			to,
-			func(x Node, f, t int, empty bool) {
-				if len(x.GetNestedNodes()) == 0 {
-					match(n, f, t, empty)
-				}
-			},
-		)
-	} else if n.no != nil {
-		n.no.Visit(
-			scanner,
-			input,
-			from,
-			to,
-			func(x Node, f, t int, empty bool) {
-				if len(x.GetNestedNodes()) == 0 {
-					match(n, f, t, empty)
-				}
-			},
-		)
+		branch = n.yes
+	}
+
+	// guard without matched condition is empty
+	if branch == nil {
+		match(n, from, from, true)
+		n.base.VisitNested(scanner, input, from, to, match)
+		scanner.Rewind(pos)
+
+		return
 	}
 
+	branch.Visit(
+		scanner,
+		input,
+		from,
+		to,
+		func(x Node, vFrom, vTo int, empty bool) {
+			if len(x.GetNestedNodes()) > 0 {
+				return
+			}
+
+			// the same rules of empty leafs as for variants of alternation
+			switch {
+			case !empty:
+			case vFrom > from:
+				vTo, empty = vFrom-1, false
+			default:
+				vTo = from
+			}
+
+			match(n, from, vTo, empty)
+			n.base.VisitNested(scanner, input, nextFor(vTo, empty), to, match)
+		},
+	)
+
 	scanner.Rewind(pos)
 }
 
@@ -0,0 +1,22 @@
+package node
+
+// Expression - source of regular expression
+// with optional payload attached by user (rule id, metadata and etc).
+// Payload must be comparable, because expressions stored in sets.
+type Expression struct {
+	Source  string
+	Payload any
+}
+
+func NewExpression(source string) Expression {
+	return Expression{
+		Source: source,
+	}
+}
+
+func NewExpressionWithPayload(source string, payload any) Expression {
+	return Expression{
+		Source:  source,
+		Payload: payload,
+	}
+}
@@ -0,0 +1,223 @@
+package node
+
+import (
+	"unicode"
+
+	"golang.org/x/exp/slices"
+)
+
+// includeFold - table includes x or any other rune of its case folding orbit
+// (see unicode.SimpleFold), like 'k', 'K' and 'K' (Kelvin sign)
+func includeFold(table Table, x rune) bool {
+	if table.Include(x) {
+		return true
+	}
+
+	for r := unicode.SimpleFold(x); r != x; r = unicode.SimpleFold(r) {
+		if table.Include(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equalFold - runes are equal under simple case folding, like 's', 'S' and 'ſ'
+func equalFold(x, y rune) bool {
+	if x == y {
+		return true
+	}
+
+	for r := unicode.SimpleFold(x); r != x; r = unicode.SimpleFold(r) {
+		if r == y {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FoldCase - return expression where tables of classes which are matched with enabled
+// ScanOptionCaseInsensetive (after options switchers like (?i)) are replaced by tables
+// closed under case folding (see NewFoldedClass), so classes don't fold runes on scanning
+// and [a-z] under (?i) is the same node as [A-Za-z] under (?i).
+//
+// fold must return table with runes of table and all runes of their orbits,
+// expression without options switchers which enable this option is returned as is.
+func FoldCase(alt Alternation, fold func(Table) Table) Alternation {
+	if !enablesCaseInsensetive(alt) {
+		return alt
+	}
+
+	f := &folder{fold: fold}
+	x, _ := f.chain(alt, false)
+
+	return x.(Alternation)
+}
+
+func enablesCaseInsensetive(n Node) bool {
+	enables := false
+
+	Walk(n, func(x Node) {
+		if s, ok := x.(*optionsSwitcher); ok && slices.Contains(s.enable, ScanOptionCaseInsensetive) {
+			enables = true
+		}
+	})
+
+	return enables
+}
+
+// folder - rebuild nodes with folded tables, on is true when
+// ScanOptionCaseInsensetive is enabled for sure before node,
+// options are kept after the end of groups like on scanning
+type folder struct {
+	fold func(Table) Table
+}
+
+// chain - rebuild node and its nested nodes with expressions,
+// return true if option is enabled after each of nested nodes
+func (f *folder) chain(n Node, on bool) (Node, bool) {
+	x, on := f.node(n, on)
+
+	for _, exp := range n.GetExpressions().Slice() {
+		x.AddExpression(exp)
+	}
+
+	nested := n.GetNestedNodes()
+	if len(nested) == 0 {
+		return x, on
+	}
+
+	end := true
+
+	for _, child := range nested {
+		y, childEnd := f.chain(child, on)
+		x.GetNestedNodes()[y.GetKey()] = y
+		end = end && childEnd
+	}
+
+	return x, end
+}
+
+// node - rebuild node without nested nodes and expressions
+func (f *folder) node(n Node, on bool) (Node, bool) {
+	switch x := n.(type) {
+	case *class:
+		if on {
+			return NewFoldedClass(f.fold(x.table)), on
+		}
+	case *negativeClass:
+		if on {
+			return NewFoldedNegativeClass(f.fold(x.table)), on
+		}
+	case *optionsSwitcher:
+		if slices.Contains(x.enable, ScanOptionCaseInsensetive) {
+			on = true
+		}
+
+		if slices.Contains(x.disable, ScanOptionCaseInsensetive) {
+			on = false
+		}
+	case *quantifier:
+		value, end := f.chain(x.value, on)
+
+		// the next repetitions start with option of the end of previous one
+		if end != on {
+			value, end = f.chain(x.value, false)
+		}
+
+		if x.quantity.From() == 0 {
+			end = end && on
+		}
+
+		return NewQuantifier(x.quantity, value), end
+	case Alternation:
+		return f.alternation(x, on)
+	case *group:
+		alt, end := f.alternation(x.value, on)
+		return NewGroup(alt), end
+	case *notCapturedGroup:
+		alt, end := f.alternation(x.value, on)
+		return NewNotCapturedGroup(alt), end
+	case *namedGroup:
+		alt, end := f.alternation(x.value, on)
+		return NewNamedGroup(x.name, alt), end
+	case *submatch:
+		alt, end := f.alternation(x.value, on)
+		return NewSubmatch(x.index, x.parent, alt), end
+	case *lookAhead:
+		alt, end := f.alternation(x.value, on)
+		return NewLookAhead(alt), end
+	case *condition:
+		return f.condition(x, on)
+	case *atomicGroup:
+		alt, end := f.alternation(x.value, on)
+		return NewAtomicGroup(alt), on && end
+	case *negativeLookAhead:
+		alt, end := f.alternation(x.value, on)
+		return NewNegativeLookAhead(alt), on && end
+	case *lookBehind:
+		return f.lookBehind(x, on)
+	case *negativeLookBehind:
+		return f.negativeLookBehind(x, on)
+	}
+
+	return n.Copy(), on
+}
+
+// alternation - rebuild variants, return true if option is enabled after each of them
+func (f *folder) alternation(alt Alternation, on bool) (Alternation, bool) {
+	variants := make([]Node, 0, len(alt.GetVariants()))
+	end := true
+
+	for _, variant := range alt.GetVariants() {
+		x, variantEnd := f.chain(variant, on)
+		variants = append(variants, x)
+		end = end && variantEnd
+	}
+
+	return NewAlternation(variants), end
+}
+
+func (f *folder) condition(n *condition, on bool) (Node, bool) {
+	yes, end := f.chain(n.yes, on)
+
+	if n.no == nil {
+		return NewGuard(n.cond, yes), on && end
+	}
+
+	no, noEnd := f.chain(n.no, on)
+
+	return NewCondition(n.cond, yes, no), end && noEnd
+}
+
+// lookBehind - rebuild look-behind, folded tables don't change its size
+func (f *folder) lookBehind(n *lookBehind, on bool) (Node, bool) {
+	alt, end := f.alternation(n.value, on)
+
+	if n.subExpressionSize == anyLength {
+		return NewLookBehindOfAnyLength(alt), on && end
+	}
+
+	x, err := NewLookBehind(alt)
+	if err != nil {
+		return n.Copy(), on && end
+	}
+
+	return x, on && end
+}
+
+func (f *folder) negativeLookBehind(n *negativeLookBehind, on bool) (Node, bool) {
+	alt, end := f.alternation(n.value, on)
+
+	if n.subExpressionSize == anyLength {
+		return NewNegativeLookBehindOfAnyLength(alt), on && end
+	}
+
+	x, err := NewNegativeLookBehind(alt)
+	if err != nil {
+		return n.Copy(), on && end
+	}
+
+	return x, on && end
+}
@@ -0,0 +1,450 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/interval"
+)
+
+// ErrNoFormat - node can't be written in syntax of dialect
+var ErrNoFormat = errors.New("node can't be formatted in dialect")
+
+// meta - characters which must be escaped out of classes
+const meta = `\.+*?()[]{}|^$`
+
+// classMeta - characters which must be escaped in classes
+const classMeta = `\[]^-&`
+
+// Dialect - syntax of regular expressions to format nodes (see Dialect.Format)
+type Dialect struct {
+	// Escapes - meta characters which could be escaped by backslash,
+	// other meta characters are written by hex codes like \x28
+	Escapes string
+	// NamedGroup - prefix of named groups, like ?< or ?P<
+	NamedGroup string
+	// Options - flags of options switchers, like 'i' for ScanOptionCaseInsensetive
+	Options map[ScanOption]rune
+	// Tables - escapes of predefined tables by keys of tables, like \d
+	Tables map[string]string
+	// Backtracking - look-arounds, back references, conditions
+	// and possessive quantifiers are supported
+	Backtracking bool
+}
+
+// Format - write node with its nested nodes in syntax of dialect,
+// parsing of result by parser of dialect returns nodes with the same keys.
+//
+// Nodes with several nested nodes (merged expressions of tree)
+// are written as alternations of continuations.
+func (d *Dialect) Format(n Node) (string, error) {
+	f := &formatter{dialect: d}
+
+	if alt, ok := n.(Alternation); ok && len(alt.GetNestedNodes()) == 0 {
+		if err := f.variants(alt); err != nil {
+			return "", err
+		}
+	} else if err := f.chain(n); err != nil {
+		return "", err
+	}
+
+	return f.b.String(), nil
+}
+
+type formatter struct {
+	dialect *Dialect
+	b       strings.Builder
+	// afterReference - back reference is written last,
+	// digits must be escaped to not be read as part of it
+	afterReference bool
+	// classLimit - count of ranges of classes which are written,
+	// other ranges are cut to "…", zero means all ranges
+	classLimit int
+}
+
+// chain - write node and its continuations
+func (f *formatter) chain(n Node) error {
+	if err := f.node(n); err != nil {
+		return err
+	}
+
+	nested := n.GetNestedNodes()
+
+	switch len(nested) {
+	case 0:
+		return nil
+	case 1:
+		for _, next := range nested {
+			return f.chain(next)
+		}
+	}
+
+	keys := make([]string, 0, len(nested))
+	for key := range nested {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	f.write("(?:")
+
+	for i, key := range keys {
+		if i > 0 {
+			f.write("|")
+		}
+
+		if err := f.chain(nested[key]); err != nil {
+			return err
+		}
+	}
+
+	f.write(")")
+
+	// one of expressions ends at this node
+	if n.IsLeaf() {
+		f.write("?")
+	}
+
+	return nil
+}
+
+// node - write node without nested nodes
+func (f *formatter) node(n Node) error {
+	switch x := n.(type) {
+	case *class:
+		return f.class(x.table)
+	case *negativeClass:
+		return f.negativeClass(x.table)
+	case *dot:
+		f.write(".")
+	case *startOfLine:
+		f.write("^")
+	case *endOfLine:
+		f.write("$")
+	case *startOfString:
+		f.write(`\A`)
+	case *endOfString:
+		f.write(`\z`)
+	case *endOfStringAndNewLine:
+		f.write(`\Z`)
+	case *wordBoundary:
+		f.write(`\b`)
+	case *nonWordBoundary:
+		f.write(`\B`)
+	case *keep:
+		f.write(`\K`)
+	case *comment:
+		f.write("(?#" + x.text + ")")
+	case *optionsSwitcher:
+		return f.options(x)
+	case Alternation:
+		return f.group("(?:", x)
+	case *notCapturedGroup:
+		return f.group("(?:", x.value)
+	case *group:
+		return f.group("(", x.value)
+	case *submatch:
+		return f.group("(", x.value)
+	case *namedGroup:
+		return f.group("("+f.dialect.NamedGroup+x.name+">", x.value)
+	case *atomicGroup:
+		return f.group("(?>", x.value)
+	case *quantifier:
+		return f.quantifier(x)
+	case *lookAhead:
+		return f.assertion("(?=", x.value)
+	case *negativeLookAhead:
+		return f.assertion("(?!", x.value)
+	case *lookBehind:
+		return f.assertion("(?<=", x.value)
+	case *negativeLookBehind:
+		return f.assertion("(?<!", x.value)
+	case *referenceNode:
+		if !f.dialect.Backtracking {
+			return fmt.Errorf("%w: %s", ErrNoFormat, n.GetKey())
+		}
+
+		f.write(`\` + strconv.Itoa(x.index))
+		f.afterReference = true
+	case *nameReferenceNode:
+		if !f.dialect.Backtracking {
+			return fmt.Errorf("%w: %s", ErrNoFormat, n.GetKey())
+		}
+
+		f.write(`\k<` + x.name + ">")
+	case *condition:
+		return f.condition(x)
+	default:
+		return fmt.Errorf("%w: %s", ErrNoFormat, n.GetKey())
+	}
+
+	return nil
+}
+
+func (f *formatter) write(s string) {
+	f.b.WriteString(s)
+	f.afterReference = false
+}
+
+func (f *formatter) group(prefix string, alt Alternation) error {
+	f.write(prefix)
+
+	if err := f.variants(alt); err != nil {
+		return err
+	}
+
+	f.write(")")
+
+	return nil
+}
+
+func (f *formatter) assertion(prefix string, alt Alternation) error {
+	if !f.dialect.Backtracking {
+		return fmt.Errorf("%w: %s", ErrNoFormat, prefix+alt.GetKey()+")")
+	}
+
+	return f.group(prefix, alt)
+}
+
+func (f *formatter) variants(alt Alternation) error {
+	for i, variant := range alt.GetVariants() {
+		if i > 0 {
+			f.write("|")
+		}
+
+		if err := f.chain(variant); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *formatter) quantifier(n *quantifier) error {
+	if n.quantity.Possessive() && !f.dialect.Backtracking {
+		return fmt.Errorf("%w: %s", ErrNoFormat, n.GetKey())
+	}
+
+	// quantifier repeats only one node
+	if len(n.value.GetNestedNodes()) > 0 {
+		f.write("(?:")
+
+		if err := f.chain(n.value); err != nil {
+			return err
+		}
+
+		f.write(")")
+	} else if err := f.node(n.value); err != nil {
+		return err
+	}
+
+	f.write(n.quantity.String())
+
+	return nil
+}
+
+func (f *formatter) condition(n *condition) error {
+	if !f.dialect.Backtracking {
+		return fmt.Errorf("%w: %s", ErrNoFormat, n.GetKey())
+	}
+
+	// predicate of named group is a name, otherwise it's an index
+	if _, err := strconv.Atoi(n.cond.key); err == nil {
+		f.write("(?(" + n.cond.key + ")")
+	} else {
+		f.write("(?(<" + n.cond.key + ">)")
+	}
+
+	if err := f.chain(n.yes); err != nil {
+		return err
+	}
+
+	if n.no != nil {
+		f.write("|")
+
+		if err := f.chain(n.no); err != nil {
+			return err
+		}
+	}
+
+	f.write(")")
+
+	return nil
+}
+
+func (f *formatter) options(n *optionsSwitcher) error {
+	flags := func(opts []ScanOption) (string, error) {
+		var b strings.Builder
+
+		for _, opt := range opts {
+			flag, exists := f.dialect.Options[opt]
+			if !exists {
+				return "", fmt.Errorf("%w: %s", ErrNoFormat, n.GetKey())
+			}
+
+			b.WriteRune(flag)
+		}
+
+		return b.String(), nil
+	}
+
+	enable, err := flags(n.enable)
+	if err != nil {
+		return err
+	}
+
+	disable, err := flags(n.disable)
+	if err != nil {
+		return err
+	}
+
+	if disable != "" {
+		disable = "-" + disable
+	}
+
+	f.write("(?" + enable + disable + ")")
+
+	return nil
+}
+
+func (f *formatter) class(table Table) error {
+	if table.Empty() {
+		return fmt.Errorf("%w: empty class", ErrNoFormat)
+	}
+
+	if x, exists := f.dialect.Tables[table.String()]; exists {
+		f.write(x)
+		return nil
+	}
+
+	intervals := intervalsOf(table)
+
+	if len(intervals) == 1 && intervals[0].Lo == intervals[0].Hi {
+		f.literal(intervals[0].Lo)
+		return nil
+	}
+
+	f.write("[" + f.classItems(intervals) + "]")
+
+	return nil
+}
+
+func (f *formatter) negativeClass(table Table) error {
+	if x, exists := f.dialect.Tables[table.String()]; exists {
+		f.write("[^" + x + "]")
+		return nil
+	}
+
+	intervals := intervalsOf(table)
+	if len(intervals) == 0 {
+		return fmt.Errorf("%w: empty class", ErrNoFormat)
+	}
+
+	f.write("[^" + f.classItems(intervals) + "]")
+
+	return nil
+}
+
+// literal - write rune out of class
+func (f *formatter) literal(r rune) {
+	if f.afterReference && r >= '0' && r <= '9' {
+		f.write(hexEscape(r))
+		return
+	}
+
+	if strings.ContainsRune(meta, r) {
+		if strings.ContainsRune(f.dialect.Escapes, r) {
+			f.write(`\` + string(r))
+		} else {
+			f.write(hexEscape(r))
+		}
+
+		return
+	}
+
+	f.write(escapeRune(r))
+}
+
+// classItems - write sorted intervals as items of class
+func (f *formatter) classItems(intervals []interval.Interval) string {
+	var b strings.Builder
+
+	item := func(r rune) string {
+		if strings.ContainsRune(classMeta, r) {
+			return hexEscape(r)
+		}
+
+		return escapeRune(r)
+	}
+
+	// ends of ranges can't be written by escapes like \n
+	end := func(r rune) string {
+		if r <= unicode.MaxLatin1 && !unicode.IsPrint(r) {
+			return hexEscape(r)
+		}
+
+		return item(r)
+	}
+
+	for i, x := range intervals {
+		if f.classLimit > 0 && i == f.classLimit {
+			b.WriteString("…")
+			break
+		}
+
+		lo, hi := x.Lo, x.Hi
+
+		switch {
+		case lo == hi:
+			b.WriteString(item(lo))
+		case lo+1 == hi:
+			b.WriteString(item(lo))
+			b.WriteString(item(hi))
+		default:
+			b.WriteString(end(lo))
+			b.WriteRune('-')
+			b.WriteString(end(hi))
+		}
+	}
+
+	return b.String()
+}
+
+// escapeRune - write rune as is if it's printable,
+// otherwise by escape sequence which is supported by most of dialects
+func escapeRune(r rune) string {
+	switch r {
+	case '\a':
+		return `\a`
+	case '\f':
+		return `\f`
+	case '\t':
+		return `\t`
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\v':
+		return `\v`
+	}
+
+	switch {
+	case unicode.IsPrint(r):
+		return string(r)
+	case r <= unicode.MaxLatin1:
+		return hexEscape(r)
+	case r >= 0xD800 && r <= 0xDFFF:
+		// surrogates can't be encoded to UTF-8
+		return fmt.Sprintf(`\u%04X`, r)
+	}
+
+	return string(r)
+}
+
+func hexEscape(r rune) string {
+	return fmt.Sprintf(`\x%02X`, r)
+}
@@ -55,5 +55,5 @@ func (n *group) Size() (int, bool) {
 }
 
 func (n *group) Copy() Node {
-	return NewGroup(n.value.CopyAlternation())
+	return NewGroup(n.value)
 }
@@ -24,20 +24,18 @@ func (n *lookAhead) GetValue() Node {
 
 func (n *lookAhead) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	pos := scanner.Position()
-	holesPos := scanner.HolesPosition()
 
 	n.value.VisitAlternation(
 		scanner,
 		input,
 		from,
 		to,
-		func(variant Node, vFrom, vTo int, _ bool) bool {
+		func(_ Node, _, _ int, _ bool) bool {
 			scanner.Rewind(pos)
 
-			scanner.MarkAsHole(vFrom, vTo)
-			match(n, vFrom, vTo, true)
-			scanner.RewindHoles(holesPos)
-
+			// look-ahead doesn't consume characters,
+			// so its match is empty (even at the end of container)
+			match(n, from, from, true)
 			n.base.VisitNested(scanner, input, from, to, match)
 			scanner.Rewind(pos)
 			return false
@@ -50,5 +48,5 @@ func (n *lookAhead) Size() (int, bool) {
 }
 
 func (n *lookAhead) Copy() Node {
-	return NewLookAhead(n.value.CopyAlternation())
+	return NewLookAhead(n.value)
 }
@@ -8,6 +8,8 @@ type lookBehind struct {
 	*base
 }
 
+var _ Container = new(lookBehind)
+
 func NewLookBehind(alt Alternation) (Node, error) {
 	size, fixedSize := alt.Size()
 	if !fixedSize {
@@ -21,14 +23,42 @@ func NewLookBehind(alt Alternation) (Node, error) {
 	}, nil
 }
 
+// NewLookBehindOfAnyLength - look-behind with subexpression of any size (like in ECMAScript)
+func NewLookBehindOfAnyLength(alt Alternation) Node {
+	size, fixedSize := alt.Size()
+	if !fixedSize {
+		size = anyLength
+	}
+
+	return &lookBehind{
+		value:             alt,
+		subExpressionSize: size,
+		base:              newBase(fmt.Sprintf("(?<=%s)", alt.GetKey())),
+	}
+}
+
+func (n *lookBehind) GetValue() Node {
+	return n.value
+}
+
 func (n *lookBehind) Visit(scanner Scanner, input Input, from, to int, match Callback) {
+	pos := scanner.Position()
+
+	if n.subExpressionSize == anyLength {
+		if matchBehind(scanner, input, n.value, from, to) {
+			match(n, from, from, true)
+			n.base.VisitNested(scanner, input, from, to, match)
+			scanner.Rewind(pos)
+		}
+
+		return
+	}
+
 	// TODO : what about anchors?
 	if from < n.subExpressionSize {
 		return
 	}
 
-	pos := scanner.Position()
-
 	n.value.VisitAlternation(
 		scanner,
 		input,
@@ -52,6 +82,54 @@ func (n *lookBehind) Size() (int, bool) {
 }
 
 func (n *lookBehind) Copy() Node {
-	x, _ := NewLookBehind(n.value.CopyAlternation())
+	if n.subExpressionSize == anyLength {
+		return NewLookBehindOfAnyLength(n.value)
+	}
+
+	x, _ := NewLookBehind(n.value)
 	return x
 }
+
+// anyLength - size of subexpression of look-behind which is not fixed
+const anyLength = -1
+
+// matchBehind - check that subexpression match text which ends right before position,
+// try all starts from the nearest to the farthest
+func matchBehind(scanner Scanner, input Input, alt Alternation, from, to int) bool {
+	pos := scanner.Position()
+	first := 0
+
+	w, isWindow := input.(Window)
+	if isWindow {
+		first = w.Offset()
+	}
+
+	matched := false
+
+	for start := from; start >= first && !matched; start-- {
+		alt.VisitAlternation(
+			scanner,
+			input,
+			start,
+			to,
+			func(_ Node, vFrom, vTo int, empty bool) bool {
+				if empty {
+					matched = vFrom == from
+				} else {
+					matched = vTo == from-1
+				}
+
+				return matched
+			},
+		)
+
+		scanner.Rewind(pos)
+	}
+
+	// subexpression could match text before window, which is already forgotten
+	if !matched && isWindow && first > 0 {
+		w.MarkOverflow()
+	}
+
+	return matched
+}
@@ -34,7 +34,12 @@ func (n *namedGroup) Visit(scanner Scanner, input Input, from, to int, match Cal
 			pos := scanner.Position()
 			groupsPos := scanner.NamedGroupsPosition()
 
-			scanner.MatchNamedGroup(n.name, from, vTo)
+			if empty {
+				scanner.MatchNamedGroup(n.name, from, from-1)
+			} else {
+				scanner.MatchNamedGroup(n.name, from, vTo)
+			}
+
 			match(n, from, vTo, empty)
 
 			nextFrom := nextFor(vTo, empty)
@@ -59,5 +64,5 @@ func (n *namedGroup) Size() (int, bool) {
 }
 
 func (n *namedGroup) Copy() Node {
-	return NewNamedGroup(n.name, n.value.CopyAlternation())
+	return NewNamedGroup(n.name, n.value)
 }
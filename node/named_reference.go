@@ -17,12 +17,13 @@ func NewForNameReference(name string) Node {
 }
 
 func (n *nameReferenceNode) Visit(scanner Scanner, input Input, from, to int, match Callback) {
-	if from >= input.Size() {
+	matchSpan, exists := scanner.GetNamedGroup(n.name)
+
+	// reference to empty group matches empty string even at the end of input
+	if from >= input.Size() && !(exists && matchSpan.Empty()) {
 		return
 	}
 
-	matchSpan, exists := scanner.GetNamedGroup(n.name)
-
 	// https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Regular_expressions/Backreference
 	//
 	// If the referenced capturing group is unmatched (for example, because it belongs to an unmatched alternative in a disjunction),
@@ -49,7 +50,15 @@ func (n *nameReferenceNode) Visit(scanner Scanner, input Input, from, to int, ma
 			expected := input.ReadAt(prev)
 			actual := input.ReadAt(current)
 
-			if expected != actual {
+			matched := false
+
+			if scanner.OptionsInclude(ScanOptionCaseInsensetive) {
+				matched = equalFold(expected, actual)
+			} else {
+				matched = expected == actual
+			}
+
+			if !matched {
 				scanner.Rewind(pos)
 				return
 			}
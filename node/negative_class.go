@@ -1,11 +1,8 @@
 package node
 
-import (
-	"unicode"
-)
-
 type negativeClass struct {
-	table Table
+	table  Table
+	folded bool // table is closed under case folding
 	*base
 }
 
@@ -16,6 +13,15 @@ func NewNegativeClass(table Table) Node {
 	}
 }
 
+// NewFoldedNegativeClass - negative class with table which includes all runes of orbits
+// of case folding of its runes (see FoldCase), it doesn't fold runes on scanning,
+// the key is the same as key of NewNegativeClass of the same table
+func NewFoldedNegativeClass(table Table) Node {
+	n := NewNegativeClass(table).(*negativeClass)
+	n.folded = true
+	return n
+}
+
 func (n *negativeClass) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	if from >= input.Size() {
 		return
@@ -24,8 +30,8 @@ func (n *negativeClass) Visit(scanner Scanner, input Input, from, to int, match
 	x := input.ReadAt(from)
 	matched := false
 
-	if scanner.OptionsInclude(ScanOptionCaseInsensetive) {
-		matched = n.table.Include(unicode.ToUpper(x)) || n.table.Include(unicode.ToLower(x))
+	if !n.folded && scanner.OptionsInclude(ScanOptionCaseInsensetive) {
+		matched = includeFold(n.table, x)
 	} else {
 		matched = n.table.Include(x)
 	}
@@ -49,5 +55,9 @@ func (n *negativeClass) Size() (int, bool) {
 }
 
 func (n *negativeClass) Copy() Node {
+	if n.folded {
+		return NewFoldedNegativeClass(n.table)
+	}
+
 	return NewNegativeClass(n.table)
 }
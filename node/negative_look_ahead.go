@@ -49,5 +49,5 @@ func (n *negativeLookAhead) Size() (int, bool) {
 }
 
 func (n *negativeLookAhead) Copy() Node {
-	return NewNegativeLookAhead(n.value.CopyAlternation())
+	return NewNegativeLookAhead(n.value)
 }
@@ -8,6 +8,8 @@ type negativeLookBehind struct {
 	*base
 }
 
+var _ Container = new(negativeLookBehind)
+
 func NewNegativeLookBehind(alt Alternation) (Node, error) {
 	size, fixedSize := alt.Size()
 	if !fixedSize {
@@ -21,10 +23,38 @@ func NewNegativeLookBehind(alt Alternation) (Node, error) {
 	}, nil
 }
 
+// NewNegativeLookBehindOfAnyLength - negative look-behind with subexpression of any size (like in ECMAScript)
+func NewNegativeLookBehindOfAnyLength(alt Alternation) Node {
+	size, fixedSize := alt.Size()
+	if !fixedSize {
+		size = anyLength
+	}
+
+	return &negativeLookBehind{
+		subExpressionSize: size,
+		value:             alt,
+		base:              newBase(fmt.Sprintf("(?<!%s)", alt.GetKey())),
+	}
+}
+
+func (n *negativeLookBehind) GetValue() Node {
+	return n.value
+}
+
 func (n *negativeLookBehind) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	// TODO : what about anchors?
 	pos := scanner.Position()
 
+	if n.subExpressionSize == anyLength {
+		if !matchBehind(scanner, input, n.value, from, to) {
+			match(n, from, from, true)
+			n.base.VisitNested(scanner, input, from, to, match)
+			scanner.Rewind(pos)
+		}
+
+		return
+	}
+
 	if from < n.subExpressionSize {
 		match(n, from, from, true)
 		n.base.VisitNested(scanner, input, from, to, match)
@@ -60,6 +90,10 @@ func (n *negativeLookBehind) Size() (int, bool) {
 }
 
 func (n *negativeLookBehind) Copy() Node {
-	x, _ := NewNegativeLookBehind(n.value.CopyAlternation())
+	if n.subExpressionSize == anyLength {
+		return NewNegativeLookBehindOfAnyLength(n.value)
+	}
+
+	x, _ := NewNegativeLookBehind(n.value)
 	return x
 }
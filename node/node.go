@@ -1,14 +1,16 @@
 package node
 
 import (
+	"unicode"
+
 	"github.com/okneniz/cliche/quantity"
 	"github.com/okneniz/cliche/structs"
 )
 
 type Node interface {
 	GetKey() string
-	GetExpressions() structs.Set[string]
-	AddExpression(string)
+	GetExpressions() structs.Set[Expression]
+	AddExpression(Expression)
 	GetNestedNodes() map[string]Node
 	IsLeaf() bool
 
@@ -29,6 +31,8 @@ type Node interface {
 
 	// TODO : what about anchors, is it endless or zero sized?
 
+	// Copy - return node with the same key without nested nodes and expressions,
+	// values of containers are shared (they are not changed after parsing)
 	Copy() Node
 }
 
@@ -64,6 +68,13 @@ type Table interface {
 	String() string
 }
 
+// RangeTable - table which can return its runes as unicode.RangeTable,
+// it's used to compare tables without iteration over all runes
+type RangeTable interface {
+	Table
+	RangeTable() *unicode.RangeTable
+}
+
 type Scanner interface {
 	Position() int
 	Rewind(pos int)
@@ -73,6 +84,7 @@ type Scanner interface {
 	GetGroup(idx int) (quantity.Interface, bool)
 	RewindGroups(pos int)
 
+	// MatchNamedGroup - save named group, to < from means empty group
 	MatchNamedGroup(name string, from int, to int)
 	NamedGroupsPosition() int
 	GetNamedGroup(name string) (quantity.Interface, bool)
@@ -82,6 +94,12 @@ type Scanner interface {
 	HolesPosition() int
 	RewindHoles(pos int)
 
+	// MatchLazy - save count of runes matched by lazy quantifier
+	MatchLazy(size int)
+	LazyPosition() int
+	GetLazy(pos int) (int, bool)
+	RewindLazy(pos int)
+
 	OptionsInclude(opt ScanOption) bool
 	OptionsEnable(opt ScanOption)
 	OptionsDisable(opt ScanOption)
@@ -95,6 +113,15 @@ const (
 	ScanOptionCaseInsensetive    ScanOption = 1
 	ScanOptionMultiline          ScanOption = 2
 	ScanOptionDisableNamedGroups ScanOption = 3
+	// ScanOptionLocations - report byte offsets, lines and columns of matches,
+	// works only for inputs which implement Locator
+	ScanOptionLocations ScanOption = 4
+	// ScanOptionRawBytes - decode invalid UTF-8 of bytes input as raw bytes
+	// (runes U+DC80-U+DCFF) instead of U+FFFD
+	ScanOptionRawBytes ScanOption = 5
+	// ScanOptionSubmatchesHistory - save each match of submatch (see NewSubmatch)
+	// as named group "step:index:parent" too, it's required to choose submatches by POSIX rules
+	ScanOptionSubmatchesHistory ScanOption = 6
 )
 
 type Input interface {
@@ -103,16 +130,50 @@ type Input interface {
 	Position() int
 }
 
+// Window - input which keep in memory only part of text around current position
+type Window interface {
+	Input
+	// Offset - return absolute index of the first rune in memory
+	Offset() int
+	// Slide - move window to position, return false if position is out of input
+	Slide(pos int) bool
+	// Overflow - return true if the end of window was reached before the end of input
+	// or runes before the beginning of window were required
+	Overflow() bool
+	// MarkOverflow - report that runes before the beginning of window were required
+	MarkOverflow()
+}
+
+// Position - position of rune in source text
+type Position struct {
+	Offset int // offset in bytes
+	Line   int // number of line, starts from 1
+	Column int // number of rune in line, starts from 1
+}
+
+// Locator - input which know positions of runes in source text
+type Locator interface {
+	// Locate - return position of rune by index,
+	// index equal to size of input means the end of text
+	Locate(idx int) Position
+}
+
 type Output interface {
+	// Yield - save match, locator is nil if locations aren't required
 	Yield(
 		n Node,
 		subString string,
 		sp quantity.Interface,
 		groups []quantity.Interface,
 		namedGroups map[string]quantity.Interface,
+		locator Locator,
 	)
 
 	LastPosOf(n Node) (int, bool)
+	// Flush - called by scanner after scanning of each root node
+	Flush()
+	// Done - scanner stop scanning when output is done
+	Done() bool
 	String() string
 }
 
@@ -126,6 +187,61 @@ func Traverse(n Node, f func(Node) bool) {
 	}
 }
 
+// Walk - call f for node, nested nodes and nodes inside of
+// containers, alternations and conditions
+func Walk(n Node, f func(Node)) {
+	Traverse(n, func(x Node) bool {
+		f(x)
+
+		switch v := x.(type) {
+		case Alternation:
+			for _, variant := range v.GetVariants() {
+				Walk(variant, f)
+			}
+		case Container:
+			Walk(v.GetValue(), f)
+		case *condition:
+			Walk(v.yes, f)
+
+			if v.no != nil {
+				Walk(v.no, f)
+			}
+		}
+
+		return false
+	})
+}
+
+// AnyLengthLookBehindSize - count of runes before current position
+// which are available for look-behinds of any length in windows,
+// look-behinds which need more runes report overflow of window
+const AnyLengthLookBehindSize = 1024
+
+// LookBehindSize - return max count of runes before current position
+// which can be read by look-behinds of expression
+func LookBehindSize(n Node) int {
+	size := 0
+
+	Walk(n, func(x Node) {
+		switch v := x.(type) {
+		case *lookBehind:
+			size = max(size, lookBehindSize(v.subExpressionSize))
+		case *negativeLookBehind:
+			size = max(size, lookBehindSize(v.subExpressionSize))
+		}
+	})
+
+	return size
+}
+
+func lookBehindSize(subExpressionSize int) int {
+	if subExpressionSize == anyLength {
+		return AnyLengthLookBehindSize
+	}
+
+	return subExpressionSize
+}
+
 func nextFor(pos int, empty bool) int {
 	if empty {
 		return pos
@@ -53,5 +53,5 @@ func (n *notCapturedGroup) Size() (int, bool) {
 }
 
 func (n *notCapturedGroup) Copy() Node {
-	return NewNotCapturedGroup(n.value.CopyAlternation())
+	return NewNotCapturedGroup(n.value)
 }
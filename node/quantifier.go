@@ -24,6 +24,12 @@ func NewQuantifier(q *quantity.Quantity, value Node) Node {
 	return n
 }
 
+// NewPossessiveQuantifier - return atomic group with quantifier, like (?>x*) for x*+,
+// so both forms are unified to the same node
+func NewPossessiveQuantifier(q *quantity.Quantity, value Node) Node {
+	return NewAtomicGroup(NewAlternation([]Node{NewQuantifier(q.AsGreedy(), value)}))
+}
+
 func (n *quantifier) GetValue() Node {
 	return n.value
 }
@@ -31,22 +37,52 @@ func (n *quantifier) GetValue() Node {
 func (n *quantifier) Visit(scanner Scanner, input Input, from, to int, match Callback) {
 	start := scanner.Position()
 
+	lazyStart := scanner.LazyPosition()
+
 	n.recursiveVisit(1, scanner, input, from, to, func(value Node, mFrom, mTo int, empty bool) {
 		pos := scanner.Position()
+		lazyPos := scanner.LazyPosition()
+
+		if n.quantity.Lazy() {
+			scanner.MatchLazy(mTo - from + 1)
+		}
+
 		match(n, from, mTo, empty)
 		nextFrom := nextFor(mTo, empty)
 		n.base.VisitNested(scanner, input, nextFrom, to, match)
 		scanner.Rewind(pos)
+		scanner.RewindLazy(lazyPos)
 	})
 
 	scanner.Rewind(start)
+	scanner.RewindLazy(lazyStart)
 
 	// for zero matches like .? or .* or .{0,X}
 	if n.quantity.Optional() {
+		if n.quantity.Lazy() {
+			scanner.MatchLazy(0)
+		}
+
 		match(n, from, from, true)
 		n.base.VisitNested(scanner, input, from, to, match)
 		scanner.Rewind(start)
+		scanner.RewindLazy(lazyStart)
+	}
+}
+
+// CompareLazy - compare sizes of lazy quantifiers one by one,
+// return 1 if first sizes are less (and preferred by lazy quantifiers)
+func CompareLazy(l1, l2 []int) int {
+	for i := 0; i < len(l1) && i < len(l2); i++ {
+		switch {
+		case l1[i] < l2[i]:
+			return 1
+		case l1[i] > l2[i]:
+			return -1
+		}
 	}
+
+	return 0
 }
 
 // TODO :rewrite without recursion, if it possible
@@ -59,6 +95,19 @@ func (n *quantifier) recursiveVisit(
 ) {
 	// TODO : maybe return n, ignore match?
 	n.value.Visit(scanner, input, from, to, func(m Node, mFrom, mTo int, empty bool) {
+		if n.quantity.Gt(count) && empty {
+			// next iterations can match only the same empty string,
+			// so they are skipped to avoid endless loop,
+			// but they complete the minimal count of iterations
+			if count > 1 {
+				match(m, mFrom, mFrom-1, false)
+			} else {
+				match(m, mFrom, mTo, empty)
+			}
+
+			return
+		}
+
 		if n.quantity.Gt(count) {
 			if n.quantity.Include(count) {
 				match(m, mFrom, mTo, empty)
@@ -70,13 +119,15 @@ func (n *quantifier) recursiveVisit(
 }
 
 // TODO : return list of sizes?
-// TODO : add tests to fail on parsing not fixed size quantificators in look behind assertions
 func (n *quantifier) Size() (int, bool) {
-	// TODO : fix it
-	// TODO : size * quantity
+	count, bounded := n.quantity.To()
+	if !bounded || count != n.quantity.From() {
+		return 0, false
+	}
+
 	if size, fixedSize := n.value.Size(); fixedSize {
 		if nestedSize, fixedSize := n.base.NestedSize(); fixedSize {
-			return size + nestedSize, true
+			return size*count + nestedSize, true
 		}
 	}
 
@@ -84,5 +135,5 @@ func (n *quantifier) Size() (int, bool) {
 }
 
 func (n *quantifier) Copy() Node {
-	return NewQuantifier(n.quantity, n.value.Copy())
+	return NewQuantifier(n.quantity, n.value)
 }
@@ -2,7 +2,6 @@ package node
 
 import (
 	"fmt"
-	"unicode"
 )
 
 // back reference \1, \2 or \9
@@ -55,7 +54,7 @@ func (n *referenceNode) Visit(scanner Scanner, input Input, from, to int, match
 			matched := false
 
 			if scanner.OptionsInclude(ScanOptionCaseInsensetive) {
-				matched = unicode.ToUpper(expected) == unicode.ToUpper(actual)
+				matched = equalFold(expected, actual)
 			} else {
 				matched = expected == actual
 			}
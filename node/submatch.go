@@ -0,0 +1,86 @@
+package node
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// submatch - numbered group of POSIX expression (parenthesized subexpression),
+// it's saved as named group with its index, so it can be used by back references.
+//
+// With ScanOptionSubmatchesHistory each match is saved as named group
+// "step:index:parent" too, where step is position in history of named groups
+// and parent is index of enclosing submatch (or zero),
+// to choose submatches by POSIX rules after scanning.
+type submatch struct {
+	index  int
+	parent int
+	name   string
+	value  Alternation
+	*base
+}
+
+var _ Container = new(submatch)
+
+// NewSubmatch - return numbered group of POSIX expression,
+// parent is index of enclosing submatch or zero
+func NewSubmatch(index, parent int, alt Alternation) Node {
+	return &submatch{
+		index:  index,
+		parent: parent,
+		name:   strconv.Itoa(index),
+		value:  alt,
+		base:   newBase(fmt.Sprintf("(%d:%s)", index, alt.GetKey())),
+	}
+}
+
+func (n *submatch) GetValue() Node {
+	return n.value
+}
+
+func (n *submatch) Visit(scanner Scanner, input Input, from, to int, match Callback) {
+	n.value.VisitAlternation(
+		scanner,
+		input,
+		from,
+		to,
+		func(_ Node, vFrom, vTo int, empty bool) bool {
+			pos := scanner.Position()
+			groupsPos := scanner.NamedGroupsPosition()
+
+			end := vTo
+			if empty {
+				end = from - 1
+			}
+
+			if scanner.OptionsInclude(ScanOptionSubmatchesHistory) {
+				step := fmt.Sprintf("%d:%d:%d", groupsPos, n.index, n.parent)
+				scanner.MatchNamedGroup(step, from, end)
+			}
+
+			scanner.MatchNamedGroup(n.name, from, end)
+
+			match(n, from, vTo, empty)
+			nextFrom := nextFor(vTo, empty)
+			n.base.VisitNested(scanner, input, nextFrom, to, match)
+			scanner.Rewind(pos)
+			scanner.RewindNamedGroups(groupsPos)
+
+			return false
+		},
+	)
+}
+
+func (n *submatch) Size() (int, bool) {
+	if size, fixedSize := n.value.Size(); fixedSize {
+		if nestedSize, fixedSize := n.base.NestedSize(); fixedSize {
+			return size + nestedSize, true
+		}
+	}
+
+	return 0, false
+}
+
+func (n *submatch) Copy() Node {
+	return NewSubmatch(n.index, n.parent, n.value)
+}
@@ -0,0 +1,231 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+
+	"github.com/okneniz/cliche/interval"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// ErrNoSyntax - node has no equivalent in regexp/syntax,
+// like \K, look-arounds, back references, atomic groups and conditions
+var ErrNoSyntax = errors.New("node has no equivalent in regexp/syntax")
+
+// ToSyntax - convert node with its nested nodes to regexp/syntax tree,
+// it's best-effort conversion for RE2 compatible nodes:
+//
+//   - capturing groups are numbered in order of conversion;
+//   - . is converted to any character except new line (without scan options);
+//   - \b and \B keep their nodes semantic only for ASCII input,
+//     because word characters of regexp/syntax are ASCII only;
+//   - nodes with several nested nodes (merged expressions of tree)
+//     are converted to alternations of continuations.
+func ToSyntax(n Node) (*syntax.Regexp, error) {
+	c := new(syntaxConverter)
+	return c.chain(n)
+}
+
+type syntaxConverter struct {
+	groups int
+}
+
+// chain - convert node and its continuations
+func (c *syntaxConverter) chain(n Node) (*syntax.Regexp, error) {
+	current, err := c.node(n)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := n.GetNestedNodes()
+	if len(nested) == 0 {
+		return current, nil
+	}
+
+	keys := make([]string, 0, len(nested))
+	for key := range nested {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	variants := make([]*syntax.Regexp, 0, len(keys)+1)
+
+	for _, key := range keys {
+		next, err := c.chain(nested[key])
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, next)
+	}
+
+	// one of expressions ends at this node
+	if n.IsLeaf() {
+		variants = append(variants, &syntax.Regexp{Op: syntax.OpEmptyMatch})
+	}
+
+	return concatSyntax(current, alternateSyntax(variants...)), nil
+}
+
+// node - convert node without nested nodes
+func (c *syntaxConverter) node(n Node) (*syntax.Regexp, error) {
+	switch x := n.(type) {
+	case *class:
+		return classSyntax(intervalsOf(x.table)), nil
+	case *negativeClass:
+		return classSyntax(interval.Complement(intervalsOf(x.table), 0, unicode.MaxRune)), nil
+	case *dot:
+		return &syntax.Regexp{Op: syntax.OpAnyCharNotNL}, nil
+	case *startOfLine:
+		return &syntax.Regexp{Op: syntax.OpBeginLine}, nil
+	case *endOfLine:
+		return &syntax.Regexp{Op: syntax.OpEndLine}, nil
+	case *startOfString:
+		return &syntax.Regexp{Op: syntax.OpBeginText}, nil
+	case *endOfString:
+		return &syntax.Regexp{Op: syntax.OpEndText}, nil
+	case *wordBoundary:
+		return &syntax.Regexp{Op: syntax.OpWordBoundary}, nil
+	case *nonWordBoundary:
+		return &syntax.Regexp{Op: syntax.OpNoWordBoundary}, nil
+	case *comment:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}, nil
+	case Alternation:
+		return c.alternation(x)
+	case *notCapturedGroup:
+		return c.alternation(x.value)
+	case *group:
+		return c.capture("", x.value)
+	case *namedGroup:
+		return c.capture(x.name, x.value)
+	case *submatch:
+		return c.capture("", x.value)
+	case *quantifier:
+		return c.quantifier(x.quantity, x.value)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoSyntax, n.GetKey())
+}
+
+func (c *syntaxConverter) alternation(alt Alternation) (*syntax.Regexp, error) {
+	variants := make([]*syntax.Regexp, 0, len(alt.GetVariants()))
+
+	for _, variant := range alt.GetVariants() {
+		re, err := c.chain(variant)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, re)
+	}
+
+	return alternateSyntax(variants...), nil
+}
+
+func (c *syntaxConverter) capture(name string, alt Alternation) (*syntax.Regexp, error) {
+	c.groups++
+	index := c.groups
+
+	value, err := c.alternation(alt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syntax.Regexp{
+		Op:   syntax.OpCapture,
+		Cap:  index,
+		Name: name,
+		Sub:  []*syntax.Regexp{value},
+	}, nil
+}
+
+func (c *syntaxConverter) quantifier(q *quantity.Quantity, value Node) (*syntax.Regexp, error) {
+	sub, err := c.chain(value)
+	if err != nil {
+		return nil, err
+	}
+
+	re := &syntax.Regexp{
+		Op:  syntax.OpRepeat,
+		Min: q.From(),
+		Max: -1,
+		Sub: []*syntax.Regexp{sub},
+	}
+
+	if to, ok := q.To(); ok {
+		re.Max = to
+	}
+
+	switch {
+	case re.Min == 0 && re.Max == -1:
+		re.Op = syntax.OpStar
+	case re.Min == 1 && re.Max == -1:
+		re.Op = syntax.OpPlus
+	case re.Min == 0 && re.Max == 1:
+		re.Op = syntax.OpQuest
+	}
+
+	if q.Lazy() {
+		re.Flags |= syntax.NonGreedy
+	}
+
+	return re, nil
+}
+
+func concatSyntax(xs ...*syntax.Regexp) *syntax.Regexp {
+	subs := make([]*syntax.Regexp, 0, len(xs))
+
+	for _, x := range xs {
+		switch x.Op {
+		case syntax.OpEmptyMatch:
+		case syntax.OpConcat:
+			subs = append(subs, x.Sub...)
+		default:
+			subs = append(subs, x)
+		}
+	}
+
+	switch len(subs) {
+	case 0:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	case 1:
+		return subs[0]
+	}
+
+	return &syntax.Regexp{Op: syntax.OpConcat, Sub: subs}
+}
+
+func alternateSyntax(xs ...*syntax.Regexp) *syntax.Regexp {
+	if len(xs) == 1 {
+		return xs[0]
+	}
+
+	return &syntax.Regexp{Op: syntax.OpAlternate, Sub: xs}
+}
+
+// classSyntax - convert sorted intervals to literal or class
+func classSyntax(intervals []interval.Interval) *syntax.Regexp {
+	ranges := make([]rune, 0, len(intervals)*2)
+
+	for _, x := range intervals {
+		ranges = append(ranges, x.Lo, x.Hi)
+	}
+
+	switch {
+	case len(ranges) == 0:
+		return &syntax.Regexp{Op: syntax.OpNoMatch}
+	case len(ranges) == 2 && ranges[0] == ranges[1]:
+		return &syntax.Regexp{Op: syntax.OpLiteral, Rune: ranges[:1]}
+	case len(ranges) == 2 && ranges[0] == 0 && ranges[1] == unicode.MaxRune:
+		return &syntax.Regexp{Op: syntax.OpAnyChar}
+	case len(ranges) == 4 && ranges[0] == 0 && ranges[1] == '\n'-1 &&
+		ranges[2] == '\n'+1 && ranges[3] == unicode.MaxRune:
+		return &syntax.Regexp{Op: syntax.OpAnyCharNotNL}
+	}
+
+	return &syntax.Regexp{Op: syntax.OpCharClass, Rune: ranges}
+}
@@ -2,7 +2,8 @@ package node
 
 import "fmt"
 
-type validator func(string, Node) error
+// Validator - check parsed expression before adding to tree
+type Validator func(expression string, start Node) error
 
 func checkEmptyClasses(expression string, start Node) error {
 	err := fmt.Errorf("empty char-class: /%s/", expression)
@@ -36,7 +37,7 @@ func checkEmptyClasses(expression string, start Node) error {
 	return nil
 }
 
-var defaultValidators = []validator{
+var defaultValidators = []Validator{
 	checkEmptyClasses,
 }
 
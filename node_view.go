@@ -20,10 +20,13 @@ type nodeView struct {
 
 func newView(n node.Node) *nodeView {
 	v := &nodeView{
-		Key:         n.GetKey(),
-		NodeType:    fmt.Sprintf("%T", n),
-		Address:     fmt.Sprintf("node=%p nested=%p expressions=%p", n, n.GetNestedNodes(), n.GetExpressions()),
-		Expressions: n.GetExpressions().Slice(),
+		Key:      n.GetKey(),
+		NodeType: fmt.Sprintf("%T", n),
+		Address:  fmt.Sprintf("node=%p nested=%p expressions=%p", n, n.GetNestedNodes(), n.GetExpressions()),
+	}
+
+	for _, x := range n.GetExpressions().Slice() {
+		v.Expressions = append(v.Expressions, x.Source)
 	}
 
 	if c, ok := n.(node.Container); ok {
@@ -633,13 +633,7 @@ func parseCondition(
 			)
 		}
 
-		return node.NewPredicate(
-			strconv.Itoa(int(index)),
-			func(s node.Scanner) bool {
-				_, matched := s.GetGroup(int(index))
-				return matched
-			},
-		), nil
+		return node.NewGroupPredicate(int(index)), nil
 	})
 
 	parseNameReference := c.Try(func(
@@ -656,16 +650,7 @@ func parseCondition(
 			)
 		}
 
-		str := string(name)
-
-		return node.NewPredicate(
-			str,
-			func(s node.Scanner) bool {
-				_, matched := s.GetNamedGroup(str)
-				return matched
-			},
-		), nil
-
+		return node.NewNamedGroupPredicate(string(name)), nil
 	})
 
 	parseReference := func(
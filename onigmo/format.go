@@ -0,0 +1,36 @@
+package onigmo
+
+import (
+	"sync"
+
+	"github.com/okneniz/cliche/node"
+)
+
+// dialect - made on first use, because keys of tables are known only after build of tables
+var dialect = sync.OnceValue(func() *node.Dialect {
+	return &node.Dialect{
+		Escapes:    `.?+*^$[|`,
+		NamedGroup: "?<",
+		Options: map[node.ScanOption]rune{
+			node.ScanOptionCaseInsensetive: 'i',
+			node.ScanOptionMultiline:       'm',
+		},
+		Tables: map[string]string{
+			digit.String():     `\d`,
+			notDigit.String():  `\D`,
+			word.String():      `\w`,
+			notWord.String():   `\W`,
+			space.String():     `\s`,
+			notSpace.String():  `\S`,
+			xdigit.String():    `\h`,
+			notXdigit.String(): `\H`,
+		},
+		Backtracking: true,
+	}
+})
+
+// Format - write node (like result of Parser.Parse or unified node of tree)
+// in Onigmo syntax, parsing of result returns nodes with the same keys
+func Format(n node.Node) (string, error) {
+	return dialect().Format(n)
+}
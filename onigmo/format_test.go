@@ -0,0 +1,144 @@
+package onigmo_test
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/onigmo"
+	tests "github.com/okneniz/cliche/testing"
+	ohsnap "github.com/okneniz/oh-snap"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		formatted  string
+	}
+
+	tests := []test{
+		{expression: `abc`, formatted: `abc`},
+		{expression: `a|b|cd`, formatted: `a|b|cd`},
+		{expression: `[a-z]+`, formatted: `[a-z]+`},
+		{expression: `[^a-z0-9_]`, formatted: `[^0-9_a-z]`},
+		{expression: `[0-9]\d\D\w\W\s\S\h\H`, formatted: `[0-9]\d\D\w\W\s\S\h\H`},
+		{expression: `[^\d]`, formatted: `[^\d]`},
+		{expression: `\.\?\+\*\^\$\[\|`, formatted: `\.\?\+\*\^\$\[\|`},
+		{expression: `[.?*(]`, formatted: `[(*.?]`},
+		{expression: `[\]\[\^]`, formatted: `[\x5B\x5D\x5E]`},
+		{expression: `[-a]`, formatted: `[\x2Da]`},
+		{expression: `[&a]`, formatted: `[\x26a]`},
+		{expression: `\x5C\x28\x29\x7B\x7D`, formatted: `\x5C\x28\x29\x7B\x7D`},
+		{expression: `[\x00-\x1f]\n\t[\n\t]\x7f`, formatted: `[\x00-\x1F]\n\t[\t\n]\x7F`},
+		{expression: `é😀[é-ü]`, formatted: `é😀[é-ü]`},
+		{expression: `a{2}b{2,}c{2,5}d*?e+?f??g{,3}`, formatted: `a{2}b{2,}c{2,5}d*?e+?f??g{0,3}`},
+		{expression: `(?:ab)+c`, formatted: `(?:ab)+c`},
+		{expression: `a++`, formatted: `(?>a+)`},
+		{expression: `(a)(?<x>b)(?:c|d)(?>e)`, formatted: `(a)(?<x>b)(?:c|d)(?>e)`},
+		{expression: `(?#comment)a`, formatted: `(?#comment)a`},
+		{expression: `\A^a$\z\Z\ba\B\K`, formatted: `\A^a$\z\Z\ba\B\K`},
+		{expression: `(?i)a(?-i)b(?m).`, formatted: `(?i)a(?-i)b(?m).`},
+		{expression: `(?i:a)`, formatted: `(?i)(a)(?-i)`},
+		{expression: `(a)\1[0]`, formatted: `(a)\1\x30`},
+		{expression: `(?<x>a)\k<x>`, formatted: `(?<x>a)\k<x>`},
+		{expression: `(a)(?(1)b|c)(?(1)d)`, formatted: `(a)(?(1)b|c)(?(1)d)`},
+		{expression: `(?<x>a)(?(<x>)b|c)`, formatted: `(?<x>a)(?(<x>)b|c)`},
+		{expression: `a(?=b)(?!c)(?<=d)(?<!e)`, formatted: `a(?=b)(?!c)(?<=d)(?<!e)`},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := onigmo.Parser.Parse(test.expression)
+			require.NoError(t, err)
+
+			formatted, err := onigmo.Format(raw)
+			require.NoError(t, err)
+			require.Equal(t, test.formatted, formatted)
+
+			parsed, err := onigmo.Parser.Parse(formatted)
+			require.NoError(t, err)
+			require.Equal(t, raw.GetKey(), parsed.GetKey())
+		})
+	}
+}
+
+func TestFormat_Merged(t *testing.T) {
+	t.Parallel()
+
+	// abc, abd and ab merged like in tree
+	parse := func(expression string) node.Node {
+		raw, err := onigmo.Parser.Parse(expression)
+		require.NoError(t, err)
+
+		return raw.GetVariants()[0]
+	}
+
+	merged := parse("abc")
+	last := func(n node.Node) node.Node {
+		for _, x := range n.GetNestedNodes() {
+			return x
+		}
+
+		return nil
+	}
+
+	b := last(merged)
+	d := last(last(parse("abd")))
+
+	b.GetNestedNodes()[d.GetKey()] = d
+	b.AddExpression(node.NewExpression("ab"))
+
+	formatted, err := onigmo.Format(merged)
+	require.NoError(t, err)
+	require.Equal(t, `ab(?:d|c)?`, formatted) // ordered by keys of nodes
+}
+
+func TestFormat_Properties(t *testing.T) {
+	t.Parallel()
+
+	const iterations = 1000
+
+	seed := time.Now().UnixNano()
+	t.Logf("seed: %v", seed)
+
+	rnd := rand.New(rand.NewPCG(0, uint64(seed)))
+	arb := tests.ArbitraryRegexp(rnd, 3, 7)
+
+	ohsnap.Check(t, iterations, arb, func(reg tests.TestRegexp) bool {
+		raw, err := onigmo.Parser.Parse(reg.Expression)
+		if err != nil {
+			t.Error(err)
+			return false
+		}
+
+		formatted, err := onigmo.Format(raw)
+		if err != nil {
+			t.Error(err)
+			return false
+		}
+
+		parsed, err := onigmo.Parser.Parse(formatted)
+		if err != nil {
+			t.Log("formatted", formatted)
+			t.Error(err)
+			return false
+		}
+
+		if raw.GetKey() != parsed.GetKey() {
+			t.Log("expression", reg.Expression)
+			t.Log("formatted", formatted)
+			return false
+		}
+
+		return true
+	})
+}
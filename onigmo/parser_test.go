@@ -0,0 +1,126 @@
+package onigmo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/onigmo"
+	"github.com/okneniz/cliche/parser"
+)
+
+func TestParser_Errors(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		offset     int
+		byteOffset int
+		line       int
+		column     int
+		unexpected string
+		expected   []string
+		snippet    string
+	}
+
+	tests := []test{
+		{
+			expression: "",
+			offset:     0,
+			byteOffset: 0,
+			line:       1,
+			column:     1,
+			unexpected: "end of expression",
+			expected:   []string{"group", "class", "character"},
+			snippet:    "\n^",
+		},
+		{
+			expression: "|a",
+			offset:     0,
+			byteOffset: 0,
+			line:       1,
+			column:     1,
+			unexpected: "'|'",
+			expected:   []string{"group", "class", "character"},
+			snippet:    "|a\n^",
+		},
+		{
+			expression: "a||b",
+			offset:     2,
+			byteOffset: 2,
+			line:       1,
+			column:     3,
+			unexpected: "'|'",
+			expected:   []string{"group", "class", "character"},
+			snippet:    "a||b\n  ^",
+		},
+		{
+			expression: "ü|",
+			offset:     2,
+			byteOffset: 3,
+			line:       1,
+			column:     3,
+			unexpected: "end of expression",
+			expected:   []string{"group", "class", "character"},
+			snippet:    "ü|\n  ^",
+		},
+		{
+			expression: "(?x)a\n\t||b",
+			offset:     8,
+			byteOffset: 8,
+			line:       2,
+			column:     3,
+			unexpected: "'|'",
+			expected:   []string{"group", "class", "character"},
+			snippet:    "\t||b\n\t ^",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := onigmo.Parser.Parse(test.expression)
+
+			var parseErr *parser.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, test.expression, parseErr.Expression)
+			require.Equal(t, test.offset, parseErr.Offset)
+			require.Equal(t, test.byteOffset, parseErr.ByteOffset)
+			require.Equal(t, test.line, parseErr.Line)
+			require.Equal(t, test.column, parseErr.Column)
+			require.Equal(t, test.unexpected, parseErr.Unexpected())
+			require.Equal(t, test.expected, parseErr.Expected)
+			require.Equal(t, test.snippet, parseErr.Snippet())
+			require.Error(t, parseErr.Unwrap())
+		})
+	}
+}
+
+func BenchmarkParser_Classes(b *testing.B) {
+	expressions := []string{
+		`[a-z0-9_]+`,
+		`[^a-z]`,
+		`[\p{Greek}\p{Cyrillic}a-z]`,
+		`[^\p{L}\d]`,
+		`[[:alpha:][:digit:]&]`,
+	}
+
+	// tables of properties are built on first use
+	for _, expression := range expressions {
+		_, err := onigmo.Parser.Parse(expression)
+		require.NoError(b, err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, expression := range expressions {
+			_, err := onigmo.Parser.Parse(expression)
+			require.NoError(b, err)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package parser
 import (
 	"github.com/okneniz/cliche/encoding/unicode"
 	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/quantity"
 	c "github.com/okneniz/parsec/common"
+	"golang.org/x/exp/maps"
 )
 
 type ParserBuilder[S any] func(except ...rune) c.Combinator[rune, int, S]
@@ -66,3 +68,29 @@ func NumberAsRune(
 		}
 	}
 }
+
+// OptionalSuffix - parse quantity with optional suffix which modify it,
+// like '?' of lazy quantifier {1,3}? or '+' of possessive quantifier {1,3}+
+func OptionalSuffix(
+	makeParser ParserBuilder[*quantity.Quantity],
+	suffixes map[rune]func(*quantity.Quantity) *quantity.Quantity,
+) ParserBuilder[*quantity.Quantity] {
+	return func(except ...rune) c.Combinator[rune, int, *quantity.Quantity] {
+		parse := makeParser(except...)
+		parseSuffix := c.Try(c.OneOf[rune, int]("expected quantifier suffix", maps.Keys(suffixes)...))
+
+		return func(buf c.Buffer[rune, int]) (*quantity.Quantity, c.Error[int]) {
+			q, err := parse(buf)
+			if err != nil {
+				return nil, err
+			}
+
+			suffix, err := parseSuffix(buf)
+			if err != nil {
+				return q, nil
+			}
+
+			return suffixes[suffix](q), nil
+		}
+	}
+}
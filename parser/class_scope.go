@@ -10,8 +10,15 @@ import (
 )
 
 type ClassScope struct {
-	runes *Scope[rune]
-	items *Scope[node.Table]
+	runes     *Scope[rune]
+	items     *Scope[node.Table]
+	operators []*classOperator
+}
+
+// classOperator - binary operator of character class, like && in [a-z&&[^aeiou]]
+type classOperator struct {
+	token string
+	apply func(a, b node.Table) node.Table
 }
 
 var (
@@ -29,6 +36,22 @@ func (scope *ClassScope) Items() *Scope[node.Table] {
 	return scope.items
 }
 
+// WithOperator - add binary operator of character class (like && for intersection in Onigmo),
+// operators are applied from left to right to unions of items between them
+// and have lower precedence than union: [a-z&&[^aeiou]] is [a-z] && [[^aeiou]],
+// empty operands are skipped: [a-z&&] is [a-z]
+func (scope *ClassScope) WithOperator(
+	token string,
+	apply func(a, b node.Table) node.Table,
+) *ClassScope {
+	scope.operators = append(scope.operators, &classOperator{
+		token: token,
+		apply: apply,
+	})
+
+	return scope
+}
+
 func (scope *ClassScope) makeParser() c.Combinator[rune, int, node.Node] {
 	parseTable := scope.makeTableParser()
 
@@ -120,14 +143,64 @@ func (scope *ClassScope) makeTableParser() c.Combinator[rune, int, node.Table] {
 		)
 	}
 
-	parseSequenceOfTables := c.Try(c.Many(0, c.Try(parseTable)))
+	parseOperator := scope.makeOperatorParser()
 
-	parseClass = c.Cast(
-		parseSequenceOfTables,
-		func(tables []node.Table) (node.Table, error) {
-			return unicodePkg.MergeTables(tables...), nil
-		},
-	)
+	// items of class until operator
+	parseOperand := func(buf c.Buffer[rune, int]) (node.Table, c.Error[int]) {
+		pos := buf.Position()
+
+		if _, err := parseOperator(buf); err == nil {
+			if seekErr := buf.Seek(pos); seekErr != nil {
+				return nil, c.NewParseError(buf.Position(), seekErr.Error())
+			}
+
+			return nil, c.NewParseError(pos, "expected character class item instead of operator")
+		}
+
+		return parseTable(buf)
+	}
+
+	parseSequenceOfTables := c.Try(c.Many(0, c.Try(parseOperand)))
+
+	parseClass = func(buf c.Buffer[rune, int]) (node.Table, c.Error[int]) {
+		tables, err := parseSequenceOfTables(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		var result node.Table
+
+		if len(tables) > 0 {
+			result = unicodePkg.Union(tables...)
+		}
+
+		for {
+			operator, opErr := parseOperator(buf)
+			if opErr != nil {
+				break
+			}
+
+			tables, err = parseSequenceOfTables(buf)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case len(tables) == 0:
+				continue
+			case result == nil:
+				result = unicodePkg.Union(tables...)
+			default:
+				result = operator.apply(result, unicodePkg.Union(tables...))
+			}
+		}
+
+		if result == nil {
+			return unicodePkg.NewTable(), nil
+		}
+
+		return result, nil
+	}
 
 	parseLeftSquare := c.Try(c.Eq[rune, int](
 		"expected left square as begining of character sub class",
@@ -152,7 +225,7 @@ func (scope *ClassScope) makeTableParser() c.Combinator[rune, int, node.Table] {
 			isNegative = false
 		}
 
-		tables, err := parseSequenceOfTables(buf)
+		table, err := parseClass(buf)
 		if err != nil {
 			return nil, err
 		}
@@ -162,7 +235,6 @@ func (scope *ClassScope) makeTableParser() c.Combinator[rune, int, node.Table] {
 			return nil, err
 		}
 
-		table := unicodePkg.MergeTables(tables...)
 		if isNegative {
 			// TODO : надо как-то опционально выставлять max для разных движков
 			return table.Invert(unicode.MaxRune), nil
@@ -190,6 +262,8 @@ func (scope *ClassScope) makeRangeOrCharParser(
 
 	parseAnyRune := c.Try(c.NoneOf[rune, int](errMessage, except...))
 
+	parseOperator := scope.makeOperatorParser()
+
 	parseRune := c.Choice(
 		errMessage,
 		parsePredefinedRune,
@@ -222,6 +296,18 @@ func (scope *ClassScope) makeRangeOrCharParser(
 			return unicodePkg.NewTable(from), nil
 		}
 
+		// operator after minus, like [a-&&b]
+		if _, err = parseOperator(buf); err == nil {
+			if seekErr := buf.Seek(pos); seekErr != nil {
+				return nil, c.NewParseError(
+					buf.Position(),
+					seekErr.Error(),
+				)
+			}
+
+			return unicodePkg.NewTable(from), nil
+		}
+
 		to, err := parseRune(buf)
 		if err != nil {
 			if seekErr := buf.Seek(pos); seekErr != nil {
@@ -252,8 +338,36 @@ func (scope *ClassScope) makeRangeOrCharParser(
 		// 	)
 		// }
 
-		return unicodePkg.NewTableByPredicate(unicode.MaxRune, func(x rune) bool {
-			return from <= x && x <= to
-		}), nil
+		return unicodePkg.NewTableByRange(from, to), nil
 	}
 }
+
+// makeOperatorParser - parse token of any operator of class
+func (scope *ClassScope) makeOperatorParser() c.Combinator[rune, int, *classOperator] {
+	return func(buf c.Buffer[rune, int]) (*classOperator, c.Error[int]) {
+		pos := buf.Position()
+
+		for _, operator := range scope.operators {
+			if readToken(buf, operator.token) {
+				return operator, nil
+			}
+
+			if err := buf.Seek(pos); err != nil {
+				return nil, c.NewParseError(buf.Position(), err.Error())
+			}
+		}
+
+		return nil, c.NewParseError(pos, "expected operator of character class")
+	}
+}
+
+func readToken(buf c.Buffer[rune, int], token string) bool {
+	for _, x := range token {
+		r, err := buf.Read(true)
+		if err != nil || r != x {
+			return false
+		}
+	}
+
+	return true
+}
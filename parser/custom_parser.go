@@ -3,7 +3,6 @@ package parser
 import (
 	"golang.org/x/exp/slices"
 
-	"github.com/okneniz/cliche/buf"
 	"github.com/okneniz/cliche/node"
 	c "github.com/okneniz/parsec/common"
 )
@@ -29,17 +28,28 @@ func New(opts ...Option[*Config]) *CustomParser {
 	return p
 }
 
+// Parse - parse expression, return *ParseError if it can't be parsed to the end
 func (p *CustomParser) Parse(str string) (node.Alternation, error) {
-	buffer := buf.NewRunesBuffer(str)
+	buffer := newTracker(str)
 
 	alt, err := p.parse(buffer)
 	if err != nil {
-		return nil, err
+		return nil, buffer.error(str, err)
+	}
+
+	if !buffer.IsEOF() {
+		position := buffer.Position()
+		buffer.expect(position, "end of expression")
+
+		return nil, buffer.error(
+			str,
+			c.NewParseError(position, "expected end of expression"),
+		)
 	}
 
 	node.Traverse(alt, func(x node.Node) bool {
 		if len(x.GetNestedNodes()) == 0 {
-			x.AddExpression(str)
+			x.AddExpression(node.NewExpression(str))
 		}
 
 		return false
@@ -51,15 +61,15 @@ func (p *CustomParser) Parse(str string) (node.Alternation, error) {
 func (p *CustomParser) makeAlternationParser(
 	except ...rune,
 ) c.Combinator[rune, int, node.Alternation] {
-	parseBar := c.Eq[rune, int]("expected '|' as alternation separator", '|')
+	parseBar := expect("'|'", c.Eq[rune, int]("expected '|' as alternation separator", '|'))
 	parseLeftParens := c.Eq[rune, int]("expected left parens as begining of group", '(')
-	parseRightParens := c.Eq[rune, int]("expected right parent as ending of group", ')')
+	parseRightParens := expect("')'", c.Eq[rune, int]("expected right parent as ending of group", ')'))
 
-	parseClass := c.Try(p.config.class.makeParser())
-	parseNonClass := c.Try(p.config.nonClass.makeParser(
+	parseClass := expect("class", c.Try(p.config.class.makeParser()))
+	parseNonClass := expect("character", c.Try(p.config.nonClass.makeParser(
 		"expected non class item",
 		except...,
-	))
+	)))
 
 	var (
 		parseGroup c.Combinator[rune, int, node.Node]
@@ -127,13 +137,13 @@ func (p *CustomParser) makeAlternationParser(
 		append(except, ')')...,
 	)
 
-	parseGroup = c.Try(
+	parseGroup = expect("group", c.Try(
 		c.Between(
 			parseLeftParens,
 			parseGroupValue,
 			parseRightParens,
 		),
-	)
+	))
 
 	return parseAlternation
 }
@@ -142,10 +152,10 @@ func (p *CustomParser) makeOptionalQuantifierParser(
 	parseExpression c.Combinator[rune, int, node.Node],
 	except ...rune,
 ) c.Combinator[rune, int, node.Node] {
-	parseQuantity := c.Try(p.config.quantity.makeParser(
+	parseQuantity := expect("quantifier", c.Try(p.config.quantity.makeParser(
 		"expected quantifier",
 		except...,
-	))
+	)))
 
 	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
 		expression, err := parseExpression(buf)
@@ -158,6 +168,10 @@ func (p *CustomParser) makeOptionalQuantifierParser(
 			return expression, nil
 		}
 
+		if quantity.Possessive() {
+			return node.NewPossessiveQuantifier(quantity, expression), nil
+		}
+
 		return node.NewQuantifier(quantity, expression), nil
 	}
 }
@@ -167,7 +181,16 @@ func (p *CustomParser) makeChainParser(
 ) c.Combinator[rune, int, node.Node] {
 	makeChain := c.Const[rune, int, c.BinaryOp[node.Node]](
 		func(current, next node.Node) node.Node {
-			current.GetNestedNodes()[next.GetKey()] = next
+			// some items are parsed as chain of nodes,
+			// like (?i:foo) -> (?i)(?:foo)(?-i), so next node follows the last one
+			last := current
+			for len(last.GetNestedNodes()) == 1 {
+				for _, x := range last.GetNestedNodes() {
+					last = x
+				}
+			}
+
+			last.GetNestedNodes()[next.GetKey()] = next
 			return current
 		},
 	)
@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okneniz/cliche/buf"
+	c "github.com/okneniz/parsec/common"
+)
+
+// ParseError - expression can't be parsed, position of error is the furthest position
+// where parser expected something else (see Expected)
+type ParseError struct {
+	Expression string
+	Offset     int      // index of rune in expression
+	ByteOffset int      // offset of rune in bytes of expression
+	Line       int      // number of line, starts from 1
+	Column     int      // number of rune in line, starts from 1
+	Expected   []string // tokens which could be parsed at offset, empty for errors of translators
+	Err        error    // error of parser combinators or translator of expression
+}
+
+// NewParseError - return error of expression at offset of rune,
+// like errors which are found by translators of expressions before parsing
+func NewParseError(expression string, offset int, err error) *ParseError {
+	loc := buf.NewRunesBuffer(expression).Locate(offset)
+
+	return &ParseError{
+		Expression: expression,
+		Offset:     offset,
+		ByteOffset: loc.Offset,
+		Line:       loc.Line,
+		Column:     loc.Column,
+		Err:        err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	if len(e.Expected) == 0 {
+		return fmt.Sprintf("can't parse /%s/ at %d: %s", e.Expression, e.Offset, e.Err)
+	}
+
+	return fmt.Sprintf(
+		"can't parse /%s/ at %d: unexpected %s, expected %s",
+		e.Expression,
+		e.Offset,
+		e.Unexpected(),
+		strings.Join(e.Expected, ", "),
+	)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Unexpected - quoted rune at offset or "end of expression"
+func (e *ParseError) Unexpected() string {
+	if e.ByteOffset >= len(e.Expression) {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("%q", []rune(e.Expression[e.ByteOffset:])[0])
+}
+
+// Snippet - line of expression with caret under the rune at offset, like:
+//
+//	a||b
+//	  ^
+func (e *ParseError) Snippet() string {
+	lines := strings.Split(e.Expression, "\n")
+	line := []rune(lines[e.Line-1])
+
+	// keep tabs to align caret in editors and terminals
+	indent := make([]rune, 0, e.Column-1)
+	for _, r := range line[:e.Column-1] {
+		if r == '\t' {
+			indent = append(indent, '\t')
+		} else {
+			indent = append(indent, ' ')
+		}
+	}
+
+	return string(line) + "\n" + string(indent) + "^"
+}
+
+// tracker - buffer which remembers tokens expected at the furthest position
+type tracker struct {
+	*buf.RunesBuffer
+
+	position int
+	expected []string
+}
+
+func newTracker(str string) *tracker {
+	t := new(tracker)
+	t.RunesBuffer = buf.NewRunesBuffer(str)
+	t.position = -1
+	return t
+}
+
+func (t *tracker) expect(position int, token string) {
+	switch {
+	case position > t.position:
+		t.position = position
+		t.expected = []string{token}
+	case position == t.position:
+		for _, x := range t.expected {
+			if x == token {
+				return
+			}
+		}
+
+		t.expected = append(t.expected, token)
+	}
+}
+
+func (t *tracker) error(expression string, err error) *ParseError {
+	position := t.position
+	if position < 0 {
+		position = t.Position()
+	}
+
+	loc := t.Locate(position)
+
+	return &ParseError{
+		Expression: expression,
+		Offset:     position,
+		ByteOffset: loc.Offset,
+		Line:       loc.Line,
+		Column:     loc.Column,
+		Expected:   t.expected,
+		Err:        err,
+	}
+}
+
+// expect - remember token as expected at position where parse failed
+func expect[T any](
+	token string,
+	parse c.Combinator[rune, int, T],
+) c.Combinator[rune, int, T] {
+	return func(buffer c.Buffer[rune, int]) (T, c.Error[int]) {
+		position := buffer.Position()
+
+		x, err := parse(buffer)
+		if err != nil {
+			if t, ok := buffer.(*tracker); ok {
+				t.expect(position, token)
+			}
+		}
+
+		return x, err
+	}
+}
@@ -26,7 +26,7 @@ func Quantifier[T any, P any, S any](
 		start := buffer.Position()
 		result := make([]S, 0, to-from)
 
-		for i := 0; i <= to; i++ {
+		for i := 0; i < to; i++ {
 			pos := buffer.Position()
 
 			n, err := f(buffer)
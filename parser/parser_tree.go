@@ -54,7 +54,10 @@ func makeParserTree[T any](
 		current := root.children
 		start := buf.Position()
 
-		var parserWithLongestPrefix c.Combinator[rune, int, T]
+		var (
+			parserWithLongestPrefix c.Combinator[rune, int, T]
+			endOfLongestPrefix      int
+		)
 
 		for len(current) > 0 {
 			pos := buf.Position()
@@ -85,12 +88,21 @@ func makeParserTree[T any](
 
 			if next.parser != nil {
 				parserWithLongestPrefix = next.parser
+				endOfLongestPrefix = buf.Position()
 			}
 
 			current = next.children
 		}
 
 		if parserWithLongestPrefix != nil {
+			// longer prefix without parser could be partially read
+			if seekErr := buf.Seek(endOfLongestPrefix); seekErr != nil {
+				return null, c.NewParseError(
+					endOfLongestPrefix,
+					seekErr.Error(),
+				)
+			}
+
 			return parserWithLongestPrefix(buf)
 		}
 
@@ -148,7 +160,10 @@ func makeGroupsParserTree(
 		current := root.children
 		start := buf.Position()
 
-		var parserWithLongestPrefix c.Combinator[rune, int, node.Node]
+		var (
+			parserWithLongestPrefix c.Combinator[rune, int, node.Node]
+			endOfLongestPrefix      int
+		)
 
 		for len(current) > 0 {
 			pos := buf.Position()
@@ -179,12 +194,21 @@ func makeGroupsParserTree(
 
 			if next.parser != nil {
 				parserWithLongestPrefix = next.parser
+				endOfLongestPrefix = buf.Position()
 			}
 
 			current = next.children
 		}
 
 		if parserWithLongestPrefix != nil {
+			// longer prefix without parser could be partially read
+			if seekErr := buf.Seek(endOfLongestPrefix); seekErr != nil {
+				return nil, c.NewParseError(
+					endOfLongestPrefix,
+					seekErr.Error(),
+				)
+			}
+
 			return parserWithLongestPrefix(buf)
 		}
 
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/okneniz/cliche/node"
+)
+
+// Translation - expression translated to syntax of internal parser
+// with offsets of its runes in original expression
+type Translation struct {
+	Expression string
+	Offsets    []int // offset in original expression for each rune of translated expression
+}
+
+// NewTranslation - translation of token at offset of original expression,
+// all runes of translation refer to the token
+func NewTranslation(text string, offset int) Translation {
+	offsets := make([]int, 0, len(text))
+	for range text {
+		offsets = append(offsets, offset)
+	}
+
+	return Translation{
+		Expression: text,
+		Offsets:    offsets,
+	}
+}
+
+// Concat - join translations of tokens
+func Concat(xs ...Translation) Translation {
+	size := 0
+	for _, x := range xs {
+		size += len(x.Offsets)
+	}
+
+	var b strings.Builder
+
+	offsets := make([]int, 0, size)
+
+	for _, x := range xs {
+		b.WriteString(x.Expression)
+		offsets = append(offsets, x.Offsets...)
+	}
+
+	return Translation{
+		Expression: b.String(),
+		Offsets:    offsets,
+	}
+}
+
+// Translated - return parser of expressions which are translated
+// to syntax of internal parser before parsing,
+// leafs of parsed expressions keep original expression instead of translated,
+// errors of parser refer to original expression
+func Translated(
+	translate func(string) (Translation, error),
+	internal *CustomParser,
+) func(string) (node.Alternation, error) {
+	return func(expression string) (node.Alternation, error) {
+		translation, err := translate(expression)
+		if err != nil {
+			return nil, err
+		}
+
+		translated := translation.Expression
+
+		alt, err := internal.Parse(translated)
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				return nil, parseErr.translated(expression, translation.Offsets)
+			}
+
+			return nil, err
+		}
+
+		node.Traverse(alt, func(x node.Node) bool {
+			if len(x.GetNestedNodes()) == 0 {
+				x.GetExpressions().Delete(node.NewExpression(translated))
+				x.AddExpression(node.NewExpression(expression))
+			}
+
+			return false
+		})
+
+		return alt, nil
+	}
+}
+
+// translated - move error from translated expression to original expression,
+// end of translated expression is the end of original expression
+func (e *ParseError) translated(expression string, offsets []int) *ParseError {
+	offset := utf8.RuneCountInString(expression)
+	if e.Offset < len(offsets) {
+		offset = offsets[e.Offset]
+	}
+
+	err := NewParseError(expression, offset, e.Err)
+	err.Expected = e.Expected
+
+	return err
+}
@@ -0,0 +1,571 @@
+package pcre
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/quantity"
+	c "github.com/okneniz/parsec/common"
+)
+
+// combinators of internal syntax, see translator for details
+
+// parseEscapedRune - parse any character except letters and digits after '\'
+func parseEscapedRune(_ ...rune) c.Combinator[rune, int, rune] {
+	return c.NoneOf[rune, int](
+		"expected escaped character",
+		[]rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")...,
+	)
+}
+
+// parseHexChar - parse {hhh..} or hh after \x
+func parseHexChar(except ...rune) c.Combinator[rune, int, rune] {
+	parseLeftBrace := c.Eq[rune, int]("expected '{' as begining of hex number", '{')
+	parseRightBrace := c.Eq[rune, int]("expected '}' as ending of hex number", '}')
+
+	parseLong := c.Try(c.Between(parseLeftBrace, parseHexNumber(1, 8)(except...), parseRightBrace))
+	parseShort := parseHexNumber(1, 2)(except...)
+
+	return func(buf c.Buffer[rune, int]) (rune, c.Error[int]) {
+		pos := buf.Position()
+
+		x, err := parseLong(buf)
+		if err != nil {
+			x, err = parseShort(buf)
+			if err != nil {
+				return -1, err
+			}
+		}
+
+		if x > unicode.MaxRune {
+			return -1, c.NewParseError(
+				pos,
+				fmt.Sprintf("character code point is too large: %x", x),
+			)
+		}
+
+		return rune(x), nil
+	}
+}
+
+func parseHexNumber(from, to int) parser.ParserBuilder[int] {
+	return func(_ ...rune) c.Combinator[rune, int, int] {
+		parse, err := parser.Quantifier(
+			"expected hex number, for example 12f or 1B",
+			from, to,
+			c.OneOf[rune, int](
+				"expected at least one symbol of hex number, for example '0123456789abcdefABCDEF'",
+				[]rune("0123456789abcdefABCDEF")...,
+			),
+		)
+		if err != nil {
+			panic(err.Error()) // TODO : remove panic
+		}
+
+		return func(buf c.Buffer[rune, int]) (int, c.Error[int]) {
+			pos := buf.Position()
+
+			runes, err := parse(buf)
+			if err != nil {
+				return -1, err
+			}
+
+			str := strings.ToLower(string(runes))
+
+			num, castErr := strconv.ParseInt(str, 16, 64)
+			if castErr != nil {
+				return -1, c.NewParseError(
+					pos,
+					fmt.Sprintf(
+						"invalid hex number: %s",
+						castErr.Error(),
+					),
+				)
+			}
+
+			return int(num), nil
+		}
+	}
+}
+
+// parseNamedReference - parse <name> after \k,
+// numbered groups are referenced by its numbers as names
+func parseNamedReference(except ...rune) c.Combinator[rune, int, node.Node] {
+	parseName := parseName('>', except...)
+	parseLeftAngle := c.Eq[rune, int]("expected '<' as begining of name", '<')
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		_, err := parseLeftAngle(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := parseName(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return node.NewForNameReference(name), nil
+	}
+}
+
+// parseName - parse name which is ended by end character
+func parseName(end rune, except ...rune) c.Combinator[rune, int, string] {
+	except = append(except, end)
+
+	parseEnd := c.Eq[rune, int](
+		fmt.Sprintf("expected '%c' as ending of name", end),
+		end,
+	)
+
+	parse := c.SkipAfter(
+		parseEnd,
+		c.Some(
+			10,
+			"expected name",
+			c.Try(c.NoneOf[rune, int]("expected character of name", except...)),
+		),
+	)
+
+	return func(buf c.Buffer[rune, int]) (string, c.Error[int]) {
+		name, err := parse(buf)
+		if err != nil {
+			return "", err
+		}
+
+		return string(name), nil
+	}
+}
+
+func makeOptionsParser(
+	opts map[rune]node.ScanOption,
+	except ...rune,
+) c.Combinator[rune, int, []node.ScanOption] {
+	parseRune := c.NoneOf[rune, int](
+		"expected option flag",
+		except...,
+	)
+
+	flags := make([]string, 0, len(opts))
+	for k := range opts {
+		flags = append(flags, string(k))
+	}
+
+	errMessage := fmt.Sprintf(
+		"expected one of option flag:  %v",
+		strings.Join(flags, ", "),
+	)
+
+	return c.Many(
+		1,
+		c.Try(c.Map(errMessage, opts, parseRune)),
+	)
+}
+
+// parseOptions - parse (?is-is) or (?is-is:...),
+// capturing groups are translated to named groups, so group without options is invalid
+func parseOptions(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	except ...rune,
+) c.Combinator[rune, int, node.Node] {
+	optsDict := map[rune]node.ScanOption{
+		'i': node.ScanOptionCaseInsensetive,
+		's': node.ScanOptionMultiline,
+	}
+
+	prefix := c.Eq[rune, int](
+		"expected '?' as prefix of options",
+		'?',
+	)
+
+	comma := c.Try(c.Eq[rune, int](
+		"expected ':' as suffix of options for non captured group",
+		':',
+	))
+
+	parseEnabled := c.Try(makeOptionsParser(optsDict, append(except, '-', ':')...))
+
+	parseDisabled := c.Try(
+		c.Skip(
+			c.Eq[rune, int](
+				"expected '-' as prefix for disabled options",
+				'-',
+			),
+			parseEnabled,
+		),
+	)
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		_, err := prefix(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		enable, enableErr := parseEnabled(buf)
+		disable, disableErr := parseDisabled(buf)
+
+		if len(enable) == 0 && len(disable) == 0 {
+			return nil, c.NewParseError(
+				pos,
+				"expected options",
+				enableErr,
+				disableErr,
+			)
+		}
+
+		_, err = comma(buf)
+		if err != nil {
+			return node.NewOptionsSwitcher(enable, disable), nil
+		}
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		// (?i:foo) -> (?i)(?:foo)(?-i)
+
+		beforeSwitcher := node.NewOptionsSwitcher(enable, disable)
+		group := node.NewNotCapturedGroup(alt)
+		afterSwitcher := node.NewOptionsSwitcher(disable, enable)
+
+		beforeSwitcher.GetNestedNodes()[group.GetKey()] = group
+		group.GetNestedNodes()[afterSwitcher.GetKey()] = afterSwitcher
+
+		return beforeSwitcher, nil
+	}
+}
+
+// parseNamedGroup - parse (?<name>...) or (?<number>...) of numbered group
+func parseNamedGroup(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	except ...rune,
+) c.Combinator[rune, int, node.Node] {
+	parseGroupName := parseName('>', except...)
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		name, err := parseGroupName(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected named group",
+				err,
+			)
+		}
+
+		if index, castErr := strconv.Atoi(name); castErr == nil {
+			return node.NewSubmatch(index, 0, alt), nil
+		}
+
+		return node.NewNamedGroup(name, alt), nil
+	}
+}
+
+func parseNotCapturedGroup(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected non captured group",
+				err,
+			)
+		}
+
+		return node.NewNotCapturedGroup(alt), nil
+	}
+}
+
+func parseAtomicGroup(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected atomic group",
+				err,
+			)
+		}
+
+		return node.NewAtomicGroup(alt), nil
+	}
+}
+
+func parseLookAhead(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected lookahead expression",
+				err,
+			)
+		}
+
+		return node.NewLookAhead(alt), nil
+	}
+}
+
+func parseNegativeLookAhead(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected negative lookahead expression",
+				err,
+			)
+		}
+
+		return node.NewNegativeLookAhead(alt), nil
+	}
+}
+
+func parseLookBehind(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected lookbehind expression",
+				err,
+			)
+		}
+
+		n, validationErr := node.NewLookBehind(alt)
+		if validationErr != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected lookbehind expression",
+				c.NewParseError(
+					pos,
+					validationErr.Error(),
+				),
+			)
+		}
+
+		return n, nil
+	}
+}
+
+func parseNegativeLookBehind(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"negative lookbehind",
+				err,
+			)
+		}
+
+		n, validationErr := node.NewNegativeLookBehind(alt)
+		if validationErr != nil {
+			return nil, c.NewParseError(
+				pos,
+				"negative lookbehind",
+				c.NewParseError(
+					pos,
+					validationErr.Error(),
+				),
+			)
+		}
+
+		return n, nil
+	}
+}
+
+// parseCondition - parse (?(<name>)yes|no), numbered groups are referenced by its numbers as names
+func parseCondition(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	except ...rune,
+) c.Combinator[rune, int, node.Node] {
+	parseLeftAngle := c.Eq[rune, int]("expected '<' as begining of name", '<')
+	parseConditionName := parseName('>', except...)
+	parseRightParens := c.Eq[rune, int]("expected ')' as ending of condition", ')')
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		_, err := parseLeftAngle(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := parseConditionName(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = parseRightParens(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		cond := node.NewNamedGroupPredicate(name)
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected condition branch",
+				err,
+			)
+		}
+
+		variants := alt.GetVariants()
+
+		switch len(variants) {
+		case 1:
+			return node.NewGuard(cond, variants[0]), nil
+		case 2:
+			return node.NewCondition(cond, variants[0], variants[1]), nil
+		default:
+			return nil, c.NewParseError(
+				pos,
+				"invalid condition pattern",
+			)
+		}
+	}
+}
+
+func parseComment(
+	_ c.Combinator[rune, int, node.Alternation],
+	except ...rune,
+) c.Combinator[rune, int, node.Node] {
+	parse := c.Many(
+		10,
+		c.Try(
+			c.NoneOf[rune, int](
+				"expected comment",
+				except...,
+			),
+		),
+	)
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		runes, err := parse(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return node.NewComment(string(runes)), nil
+	}
+}
+
+// parseQuantity - parse {n}, {n,}, {,m} or {n,m}
+func parseQuantity(except ...rune) c.Combinator[rune, int, *quantity.Quantity] {
+	parseBound := parseNumber(except...)
+	parseOptionalBound := c.Try(parseBound)
+	parseComma := c.Try(c.Eq[rune, int]("expected ',' as separator in quantifier", ','))
+	parseEnd := c.Eq[rune, int]("expected '}' as ending of quantifier", '}')
+
+	return func(buf c.Buffer[rune, int]) (*quantity.Quantity, c.Error[int]) {
+		pos := buf.Position()
+
+		from, fromErr := parseOptionalBound(buf)
+
+		_, commaErr := parseComma(buf)
+		if fromErr != nil && commaErr != nil {
+			return nil, c.NewParseError(pos, "expected quantifier", fromErr, commaErr)
+		}
+
+		q := quantity.New(from, from)
+
+		if commaErr == nil {
+			q = quantity.NewEndlessQuantity(from)
+
+			to, toErr := parseOptionalBound(buf)
+
+			switch {
+			case toErr == nil && from > to:
+				return nil, c.NewParseError(pos, "invalid quantifier")
+			case toErr == nil:
+				q = quantity.New(from, to)
+			case fromErr != nil:
+				// {,} isn't quantifier
+				return nil, c.NewParseError(pos, "expected quantifier", toErr)
+			}
+		}
+
+		_, err := parseEnd(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return q, nil
+	}
+}
+
+func parseNumber(_ ...rune) c.Combinator[rune, int, int] {
+	const zero = rune('0')
+
+	digit := c.Try(c.OneOf[rune, int](
+		"expected digit",
+		'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
+	))
+
+	return func(buf c.Buffer[rune, int]) (int, c.Error[int]) {
+		token, err := digit(buf)
+		if err != nil {
+			return 0, err
+		}
+
+		number := int(token - zero)
+
+		for {
+			token, err := digit(buf)
+			if err != nil {
+				break
+			}
+
+			number = number * 10
+			number += int(token - zero)
+		}
+
+		return number, nil
+	}
+}
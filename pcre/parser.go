@@ -0,0 +1,212 @@
+package pcre
+
+import (
+	"fmt"
+	"unicode"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// DOC - https://www.pcre.org/current/doc/html/pcre2pattern.html
+//
+// Meaning of some PCRE syntax depends on context of expression
+// (quotation, extended and multiline modes, numbers of groups),
+// so expressions are translated to syntax of internal parser (see translator).
+
+var (
+	// Parser - parser of PCRE2 expressions
+	Parser = new(pcreParser)
+
+	parse = parser.Translated(translate, internal)
+
+	// \d, \w, \s and POSIX classes are defined for ASCII (PCRE2 without UCP option)
+	ascii = unicodeEncoding.NewTableByPredicate(unicode.MaxASCII, func(x rune) bool { return true })
+	word  = unicodeEncoding.NewTableByPredicate(unicode.MaxASCII, isWord)
+
+	// horizontal and vertical whitespaces
+	hspace = unicodeEncoding.NewTable(
+		'\t', ' ', '\u00a0', '\u1680', '\u180e',
+		'\u2000', '\u2001', '\u2002', '\u2003', '\u2004', '\u2005',
+		'\u2006', '\u2007', '\u2008', '\u2009', '\u200a',
+		'\u202f', '\u205f', '\u3000',
+	)
+	vspace = unicodeEncoding.NewTable('\n', '\v', '\f', '\r', '\u0085', '\u2028', '\u2029')
+
+	notAlnum   = unicodeEncoding.ASCIIAlnum.Invert(unicode.MaxRune)
+	notAlpha   = unicodeEncoding.ASCIIAlpha.Invert(unicode.MaxRune)
+	notAscii   = ascii.Invert(unicode.MaxRune)
+	notBlank   = unicodeEncoding.ASCIIBlank.Invert(unicode.MaxRune)
+	notCntrl   = unicodeEncoding.ASCIICntrl.Invert(unicode.MaxRune)
+	notDigit   = unicodeEncoding.ASCIIDigit.Invert(unicode.MaxRune)
+	notGraph   = unicodeEncoding.ASCIIGraph.Invert(unicode.MaxRune)
+	notLower   = unicodeEncoding.ASCIILower.Invert(unicode.MaxRune)
+	notPrint   = unicodeEncoding.ASCIIPrint.Invert(unicode.MaxRune)
+	notPunct   = unicodeEncoding.ASCIIPunct.Invert(unicode.MaxRune)
+	notSpace   = unicodeEncoding.ASCIISpace.Invert(unicode.MaxRune)
+	notUpper   = unicodeEncoding.ASCIIUpper.Invert(unicode.MaxRune)
+	notWord    = word.Invert(unicode.MaxRune)
+	notXdigit  = unicodeEncoding.ASCIIXdigit.Invert(unicode.MaxRune)
+	notHspace  = hspace.Invert(unicode.MaxRune)
+	notVspace  = vspace.Invert(unicode.MaxRune)
+	notNewLine = unicodeEncoding.NewTable('\n').Invert(unicode.MaxRune)
+
+	parseHexCharTable = parser.RuneAsTable(parseHexChar)
+	parseHexCharNode  = parser.TableAsClass(parseHexCharTable)
+
+	internal = parser.New(func(cfg *parser.Config) {
+		cfg.Class().
+			Items().
+			StringAsValue("[:alnum:]", unicodeEncoding.ASCIIAlnum).
+			StringAsValue("[:alpha:]", unicodeEncoding.ASCIIAlpha).
+			StringAsValue("[:ascii:]", ascii).
+			StringAsValue("[:blank:]", unicodeEncoding.ASCIIBlank).
+			StringAsValue("[:cntrl:]", unicodeEncoding.ASCIICntrl).
+			StringAsValue("[:digit:]", unicodeEncoding.ASCIIDigit).
+			StringAsValue("[:graph:]", unicodeEncoding.ASCIIGraph).
+			StringAsValue("[:lower:]", unicodeEncoding.ASCIILower).
+			StringAsValue("[:print:]", unicodeEncoding.ASCIIPrint).
+			StringAsValue("[:punct:]", unicodeEncoding.ASCIIPunct).
+			StringAsValue("[:space:]", unicodeEncoding.ASCIISpace).
+			StringAsValue("[:upper:]", unicodeEncoding.ASCIIUpper).
+			StringAsValue("[:word:]", word).
+			StringAsValue("[:xdigit:]", unicodeEncoding.ASCIIXdigit).
+			StringAsValue("[:^alnum:]", notAlnum).
+			StringAsValue("[:^alpha:]", notAlpha).
+			StringAsValue("[:^ascii:]", notAscii).
+			StringAsValue("[:^blank:]", notBlank).
+			StringAsValue("[:^cntrl:]", notCntrl).
+			StringAsValue("[:^digit:]", notDigit).
+			StringAsValue("[:^graph:]", notGraph).
+			StringAsValue("[:^lower:]", notLower).
+			StringAsValue("[:^print:]", notPrint).
+			StringAsValue("[:^punct:]", notPunct).
+			StringAsValue("[:^space:]", notSpace).
+			StringAsValue("[:^upper:]", notUpper).
+			StringAsValue("[:^word:]", notWord).
+			StringAsValue("[:^xdigit:]", notXdigit).
+			StringAsValue(`\d`, unicodeEncoding.ASCIIDigit).
+			StringAsValue(`\D`, notDigit).
+			StringAsValue(`\w`, word).
+			StringAsValue(`\W`, notWord).
+			StringAsValue(`\s`, unicodeEncoding.ASCIISpace).
+			StringAsValue(`\S`, notSpace).
+			StringAsValue(`\h`, hspace).
+			StringAsValue(`\H`, notHspace).
+			StringAsValue(`\v`, vspace).
+			StringAsValue(`\V`, notVspace)
+
+		cfg.Class().
+			Runes().
+			WithPrefix(`\a`, parser.Const('\a')).
+			WithPrefix(`\b`, parser.Const('\b')).
+			WithPrefix(`\e`, parser.Const('\u001b')).
+			WithPrefix(`\f`, parser.Const('\f')).
+			WithPrefix(`\n`, parser.Const('\n')).
+			WithPrefix(`\r`, parser.Const('\r')).
+			WithPrefix(`\t`, parser.Const('\t')).
+			WithPrefix(`\x`, parseHexChar).
+			WithPrefix(`\`, parseEscapedRune)
+
+		cfg.NonClass().
+			Items().
+			StringAsFunc(`\A`, node.NewStartOfString).
+			StringAsFunc(`\z`, node.NewEndOfString).
+			StringAsFunc(`\Z`, node.NewEndOfStringAndNewLine).
+			StringAsFunc(`\K`, node.NewKeep).
+			StringAsFunc(`.`, node.NewDot).
+			StringAsFunc(`^`, node.NewStartOfLine).
+			StringAsFunc(`$`, node.NewEndOfLine).
+			StringAsFunc(`\b`, node.NewWordBoundary).
+			StringAsFunc(`\B`, node.NewNonWordBoundary).
+			WithPrefix(`\d`, parser.TableAsClass(parser.Const(unicodeEncoding.ASCIIDigit))).
+			WithPrefix(`\D`, parser.TableAsClass(parser.Const(notDigit))).
+			WithPrefix(`\w`, parser.TableAsClass(parser.Const(word))).
+			WithPrefix(`\W`, parser.TableAsClass(parser.Const(notWord))).
+			WithPrefix(`\s`, parser.TableAsClass(parser.Const(unicodeEncoding.ASCIISpace))).
+			WithPrefix(`\S`, parser.TableAsClass(parser.Const(notSpace))).
+			WithPrefix(`\h`, parser.TableAsClass(parser.Const(hspace))).
+			WithPrefix(`\H`, parser.TableAsClass(parser.Const(notHspace))).
+			WithPrefix(`\v`, parser.TableAsClass(parser.Const(vspace))).
+			WithPrefix(`\V`, parser.TableAsClass(parser.Const(notVspace))).
+			WithPrefix(`\N`, parser.TableAsClass(parser.Const(notNewLine))).
+			WithPrefix(`\a`, parser.TableAsClass(parser.Const(unicodeEncoding.NewTable('\a')))).
+			WithPrefix(`\e`, parser.TableAsClass(parser.Const(unicodeEncoding.NewTable('\u001b')))).
+			WithPrefix(`\f`, parser.TableAsClass(parser.Const(unicodeEncoding.NewTable('\f')))).
+			WithPrefix(`\n`, parser.TableAsClass(parser.Const(unicodeEncoding.NewTable('\n')))).
+			WithPrefix(`\r`, parser.TableAsClass(parser.Const(unicodeEncoding.NewTable('\r')))).
+			WithPrefix(`\t`, parser.TableAsClass(parser.Const(unicodeEncoding.NewTable('\t')))).
+			WithPrefix(`\x`, parseHexCharNode).
+			WithPrefix(`\k`, parseNamedReference).
+			WithPrefix(`\`, parser.TableAsClass(parser.RuneAsTable(parseEscapedRune)))
+
+		cfg.Groups().
+			Parse(parseOptions).
+			ParsePrefix("?(", parseCondition).
+			ParsePrefix("?:", parseNotCapturedGroup).
+			ParsePrefix("?<", parseNamedGroup).
+			ParsePrefix("?>", parseAtomicGroup).
+			ParsePrefix("?=", parseLookAhead).
+			ParsePrefix("?!", parseNegativeLookAhead).
+			ParsePrefix("?<=", parseLookBehind).
+			ParsePrefix("?<!", parseNegativeLookBehind).
+			ParsePrefix("?#", parseComment)
+
+		configureProperty(cfg, unicode.Properties)
+		configureProperty(cfg, unicode.Scripts)
+		configureProperty(cfg, unicode.Categories)
+
+		cfg.Quantifier().Items().StringAsValue("?", quantity.New(0, 1))
+		cfg.Quantifier().Items().StringAsValue("+", quantity.NewEndlessQuantity(1))
+		cfg.Quantifier().Items().StringAsValue("*", quantity.NewEndlessQuantity(0))
+		cfg.Quantifier().Items().StringAsValue("??", quantity.New(0, 1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("+?", quantity.NewEndlessQuantity(1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("*?", quantity.NewEndlessQuantity(0).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("?+", quantity.New(0, 1).AsPossessive())
+		cfg.Quantifier().Items().StringAsValue("++", quantity.NewEndlessQuantity(1).AsPossessive())
+		cfg.Quantifier().Items().StringAsValue("*+", quantity.NewEndlessQuantity(0).AsPossessive())
+		cfg.Quantifier().Items().WithPrefix("{", parser.OptionalSuffix(
+			parseQuantity,
+			map[rune]func(*quantity.Quantity) *quantity.Quantity{
+				'?': (*quantity.Quantity).AsLazy,
+				'+': (*quantity.Quantity).AsPossessive,
+			},
+		))
+	})
+)
+
+// pcreParser - parser of PCRE2 expressions,
+// capturing groups are parsed as node.NewSubmatch and reported as named groups "1", "2" and etc
+type pcreParser struct{}
+
+// Parse - parse PCRE2 expression and return node.Alternation as base type for it
+func (p *pcreParser) Parse(expression string) (node.Alternation, error) {
+	return parse(expression)
+}
+
+func configureProperty(cfg *parser.Config, props map[string]*unicode.RangeTable) {
+	for name, prop := range props {
+		tbl := unicodeEncoding.NewTableByRangeTable(prop)
+
+		positive := parser.Const(tbl)
+		negative := parser.Const(tbl.Invert(unicode.MaxRune))
+
+		cfg.NonClass().
+			Items().
+			WithPrefix(fmt.Sprintf("\\p{%s}", name), parser.TableAsClass(positive)).
+			WithPrefix(fmt.Sprintf("\\p{^%s}", name), parser.TableAsClass(negative)).
+			WithPrefix(fmt.Sprintf("\\P{%s}", name), parser.TableAsClass(negative))
+
+		cfg.Class().
+			Items().
+			WithPrefix(fmt.Sprintf("\\p{%s}", name), positive).
+			WithPrefix(fmt.Sprintf("\\p{^%s}", name), negative).
+			WithPrefix(fmt.Sprintf("\\P{%s}", name), negative)
+	}
+}
+
+func isWord(x rune) bool {
+	return x == '_' || unicode.IsLetter(x) || unicode.IsDigit(x)
+}
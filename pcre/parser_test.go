@@ -0,0 +1,145 @@
+package pcre_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/pcre"
+)
+
+func TestParser_Errors(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		err        error
+	}
+
+	tests := []test{
+		{expression: "(a", err: pcre.ErrParenthesesImbalance},
+		{expression: "a)", err: pcre.ErrParenthesesImbalance},
+		{expression: "(?i", err: pcre.ErrParenthesesImbalance},
+		{expression: "[a", err: pcre.ErrBracketsImbalance},
+		{expression: "[]", err: pcre.ErrBracketsImbalance},
+		{expression: `(a)\2`, err: pcre.ErrInvalidBackReference},
+		{expression: `(a)\g{-2}`, err: pcre.ErrInvalidBackReference},
+		{expression: `\g{0}`, err: pcre.ErrInvalidBackReference},
+		{expression: `\k<>`, err: pcre.ErrInvalidBackReference},
+		{expression: `(?P=1)`, err: pcre.ErrInvalidBackReference},
+		{expression: `\i`, err: pcre.ErrInvalidEscape},
+		{expression: `[\B]`, err: pcre.ErrInvalidEscape},
+		{expression: `\o{8}`, err: pcre.ErrInvalidEscape},
+		{expression: `\o{}`, err: pcre.ErrInvalidEscape},
+		{expression: `\o{12`, err: pcre.ErrInvalidEscape},
+		{expression: `\x{zz}`, err: pcre.ErrInvalidEscape},
+		{expression: `[\x{zz}]`, err: pcre.ErrInvalidEscape},
+		{expression: `\x{}`, err: pcre.ErrInvalidEscape},
+		{expression: `\x{41`, err: pcre.ErrInvalidEscape},
+		{expression: `\x{110000}`, err: pcre.ErrInvalidEscape},
+		{expression: `\p`, err: pcre.ErrInvalidEscape},
+		{expression: `\p{L`, err: pcre.ErrInvalidEscape},
+		{expression: `\p{Foo}`, err: pcre.ErrUnknownProperty},
+		{expression: `\P{Foo}`, err: pcre.ErrUnknownProperty},
+		{expression: `\p{^Foo}`, err: pcre.ErrUnknownProperty},
+		{expression: `[\p{Foo}]`, err: pcre.ErrUnknownProperty},
+		{expression: `\pQ`, err: pcre.ErrUnknownProperty},
+		{expression: `a\`, err: pcre.ErrTrailingBackslash},
+		{expression: `(*SKIP)(*FAIL)`, err: pcre.ErrUnsupported},
+		{expression: `\G`, err: pcre.ErrUnsupported},
+		{expression: `(?R)`, err: pcre.ErrUnsupported},
+		{expression: `(?1)`, err: pcre.ErrUnsupported},
+		{expression: `(a)\g<1>`, err: pcre.ErrUnsupported},
+		{expression: `(?(DEFINE)a)`, err: pcre.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := pcre.Parser.Parse(test.expression)
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// errors of translated expressions refer to original expression
+func TestParser_Offsets(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		offset     int
+		byteOffset int
+		snippet    string
+	}
+
+	tests := []test{
+		{expression: "a||b", offset: 2, byteOffset: 2, snippet: "a||b\n  ^"},
+		{expression: "^a||b", offset: 3, byteOffset: 3, snippet: "^a||b\n   ^"},
+		{expression: "(?x) a  ||b", offset: 9, byteOffset: 9, snippet: "(?x) a  ||b\n         ^"},
+		{expression: `\Qa|\E||`, offset: 7, byteOffset: 7, snippet: "\\Qa|\\E||\n       ^"},
+		{expression: "(?i)ü||", offset: 6, byteOffset: 7, snippet: "(?i)ü||\n      ^"},
+		{expression: `(a)|\1||`, offset: 7, byteOffset: 7, snippet: "(a)|\\1||\n       ^"},
+		{expression: "ü\n(b", offset: 4, byteOffset: 5, snippet: "(b\n  ^"},
+		{expression: `a\p{Foo}`, offset: 1, byteOffset: 1, snippet: "a\\p{Foo}\n ^"},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := pcre.Parser.Parse(test.expression)
+
+			var parseErr *parser.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, test.expression, parseErr.Expression)
+			require.Equal(t, test.offset, parseErr.Offset)
+			require.Equal(t, test.byteOffset, parseErr.ByteOffset)
+			require.Equal(t, test.snippet, parseErr.Snippet())
+		})
+	}
+}
+
+func TestParser_Keys(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		same       string
+	}
+
+	tests := []test{
+		{expression: `\Qa.b\E`, same: `a\.b`},
+		{expression: `(?x) a b # comment`, same: `ab`},
+		{expression: `(?P<x>a)(?P=x)`, same: `(?<x>a)\k<x>`},
+		{expression: `(a)\g{-1}`, same: `(a)\1`},
+		{expression: `^a$`, same: `\Aa\Z`},
+		{expression: `\101`, same: `A`},
+		{expression: `\x{41}`, same: `\x41`},
+		{expression: `\xzz`, same: `\x00zz`},
+		{expression: `\P{^L}`, same: `\p{L}`},
+		{expression: `\pL`, same: `\p{L}`},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := pcre.Parser.Parse(test.same)
+			require.NoError(t, err)
+
+			actual, err := pcre.Parser.Parse(test.expression)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.GetKey(), actual.GetKey())
+		})
+	}
+}
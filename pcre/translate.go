@@ -0,0 +1,805 @@
+package pcre
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/parser"
+)
+
+// translator - rewrite PCRE expression to syntax of internal parser.
+//
+// It handles the syntax which depends on context of expression:
+//
+//   - \Q...\E quotation is replaced by escaped characters;
+//   - (?x) extended mode, whitespaces and # comments are removed;
+//   - (?m) multiline mode, ^ and $ are replaced by \A and \Z without it;
+//   - capturing groups are numbered (branch reset (?|...) too)
+//     and translated to (?<number>...), named groups keep names;
+//   - back references (\1, \g{-1}, \k'name', (?P=name) etc)
+//     are resolved to \k<number> or \k<name>;
+//   - \R is replaced by atomic group of new line sequences;
+//   - octal, hex and control characters are replaced by \x{...};
+//   - names of properties (\p{...}, \P{...}) are checked.
+
+var (
+	ErrParenthesesImbalance = errors.New("parentheses imbalance")
+	ErrBracketsImbalance    = errors.New("missing terminating ] for character class")
+	ErrInvalidBackReference = errors.New("reference to non-existent group")
+	ErrInvalidEscape        = errors.New("unrecognized character follows \\")
+	ErrTrailingBackslash    = errors.New("trailing backslash")
+	ErrUnknownProperty      = errors.New("unknown property name after \\P or \\p")
+	ErrUnsupported          = errors.New("unsupported syntax")
+)
+
+const (
+	// escapes which are known by internal parser or translator
+	escapes      = "aAbBdDefhHKnNpPrsStvVwWxzZ"
+	classEscapes = "abdDefhHnpPrsStvVwWx"
+
+	// valid in PCRE, but not supported
+	unsupportedEscapes = "CGX"
+
+	// \R - any new line sequence
+	newLineSequence = `(?>\r\n|[\n\x0b\f\r\x{85}\x{2028}\x{2029}])`
+)
+
+type translator struct {
+	src    []rune
+	pos    int
+	token  int                  // position of token which is translated
+	pieces []parser.Translation // references are resolved after the whole expression
+	refs   []*reference
+	groups int // number of the last opened capturing group
+	total  int
+	names  map[int]string
+	frames []*frame
+	flags
+}
+
+type flags struct {
+	extended  bool
+	multiline bool
+}
+
+// frame - opened group
+type frame struct {
+	flags       // flags before group
+	branchReset bool
+	start       int // number of groups before branch reset
+	max         int
+}
+
+type reference struct {
+	piece  int
+	pos    int
+	number int
+	digits string // digits of \N, it's octal character if group doesn't exist
+	format string
+}
+
+func translate(expression string) (parser.Translation, error) {
+	t := &translator{
+		src:    []rune(expression),
+		pieces: make([]parser.Translation, 0, len(expression)),
+		names:  make(map[int]string),
+	}
+
+	for t.pos < len(t.src) {
+		if err := t.next(); err != nil {
+			return parser.Translation{}, err
+		}
+	}
+
+	if len(t.frames) > 0 {
+		return parser.Translation{}, t.errorf(ErrParenthesesImbalance, len(t.src))
+	}
+
+	return t.resolve()
+}
+
+func (t *translator) next() error {
+	r := t.src[t.pos]
+	t.token = t.pos
+
+	switch {
+	case t.extended && isSpace(r):
+		t.pos++
+	case t.extended && r == '#':
+		for t.pos < len(t.src) && t.src[t.pos] != '\n' {
+			t.pos++
+		}
+	case r == '\\':
+		return t.escape(false)
+	case r == '[':
+		return t.class()
+	case r == '(':
+		return t.open()
+	case r == ')':
+		return t.close()
+	case r == '|':
+		t.bar()
+	case r == '^' && !t.multiline:
+		t.emit(`\A`)
+		t.pos++
+	case r == '$' && !t.multiline:
+		t.emit(`\Z`)
+		t.pos++
+	default:
+		t.emit(string(r))
+		t.pos++
+	}
+
+	return nil
+}
+
+func (t *translator) escape(inClass bool) error {
+	start := t.pos
+
+	if t.pos+1 >= len(t.src) {
+		return t.errorf(ErrTrailingBackslash, start)
+	}
+
+	r := t.src[t.pos+1]
+	t.pos += 2
+
+	switch {
+	case r == 'Q':
+		t.quote()
+	case r == 'E':
+		// end of quotation without beginning is ignored
+	case r == 'c':
+		return t.control(start)
+	case r == 'o':
+		return t.octal(start)
+	case r == 'x':
+		return t.hex(start)
+	case r == 'p' || r == 'P':
+		return t.property(start, r)
+	case r == '0':
+		t.emitRune(t.readOctal(2, 0))
+	case r >= '1' && r <= '9' && inClass:
+		t.pos--
+		if r >= '8' {
+			t.emit(string(r))
+			t.pos++
+			return nil
+		}
+
+		t.emitRune(t.readOctal(3, 0))
+	case r >= '1' && r <= '9':
+		t.pos--
+		digits := t.readWhile(unicode.IsDigit)
+		number, _ := strconv.Atoi(digits)
+		t.reference(start, number, digits, `\k<%s>`)
+	case r == 'g' && !inClass:
+		return t.groupReference(start)
+	case r == 'k' && !inClass:
+		return t.namedReference(start)
+	case r == 'R' && !inClass:
+		t.emit(newLineSequence)
+	case strings.ContainsRune(unsupportedEscapes, r):
+		return t.errorf(ErrUnsupported, start)
+	case r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		known := escapes
+		if inClass {
+			known = classEscapes
+		}
+
+		if !strings.ContainsRune(known, r) {
+			return t.errorf(ErrInvalidEscape, start)
+		}
+
+		t.emit(`\` + string(r))
+	default:
+		t.emit(`\` + string(r))
+	}
+
+	return nil
+}
+
+// quote - escape each character before \E or end of expression
+func (t *translator) quote() {
+	for t.pos < len(t.src) {
+		if t.hasPrefix(`\E`) {
+			t.pos += 2
+			return
+		}
+
+		r := t.src[t.pos]
+		t.token = t.pos
+		t.pos++
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			t.emit(string(r))
+		} else {
+			t.emit(`\` + string(r))
+		}
+	}
+}
+
+// control - \cx
+func (t *translator) control(start int) error {
+	if t.pos >= len(t.src) || t.src[t.pos] > unicode.MaxASCII {
+		return t.errorf(ErrInvalidEscape, start)
+	}
+
+	t.emitRune(unicode.ToUpper(t.src[t.pos]) ^ 0x40)
+	t.pos++
+
+	return nil
+}
+
+// octal - \o{ddd}
+func (t *translator) octal(start int) error {
+	if t.peek() != '{' {
+		return t.errorf(ErrInvalidEscape, start)
+	}
+
+	t.pos++
+	digits := t.readWhile(isOctal)
+
+	x, err := strconv.ParseInt(digits, 8, 32)
+	if err != nil || x > unicode.MaxRune || t.peek() != '}' {
+		return t.errorf(ErrInvalidEscape, start)
+	}
+
+	t.pos++
+	t.emitRune(rune(x))
+
+	return nil
+}
+
+// hex - \xhh or \x{hhh..}, \x without digits is \x00
+func (t *translator) hex(start int) error {
+	if t.peek() != '{' {
+		from := t.pos
+
+		for t.pos < len(t.src) && t.pos-from < 2 && isHex(t.src[t.pos]) {
+			t.pos++
+		}
+
+		x, _ := strconv.ParseInt("0"+string(t.src[from:t.pos]), 16, 32)
+		t.emitRune(rune(x))
+
+		return nil
+	}
+
+	t.pos++
+	digits := t.readWhile(isHex)
+
+	x, err := strconv.ParseInt(digits, 16, 32)
+	if err != nil || x > unicode.MaxRune || t.peek() != '}' {
+		return t.errorf(ErrInvalidEscape, start)
+	}
+
+	t.pos++
+	t.emitRune(rune(x))
+
+	return nil
+}
+
+// property - \pL, \p{Name}, \p{^Name} or \P{Name}
+func (t *translator) property(start int, r rune) error {
+	var name string
+
+	switch {
+	case t.peek() == '{':
+		end := t.indexOf('}')
+		if end < 0 {
+			return t.errorf(ErrInvalidEscape, start)
+		}
+
+		name = string(t.src[t.pos+1 : end])
+		t.pos = end + 1
+	case t.pos < len(t.src):
+		name = string(t.src[t.pos])
+		t.pos++
+	default:
+		return t.errorf(ErrInvalidEscape, start)
+	}
+
+	negative := strings.HasPrefix(name, "^")
+	name = strings.TrimPrefix(name, "^")
+
+	if !isProperty(name) {
+		return t.errorf(ErrUnknownProperty, start)
+	}
+
+	// \P{^Name} is the same as \p{Name}
+	if negative == (r == 'P') {
+		t.emit(fmt.Sprintf(`\p{%s}`, name))
+	} else {
+		t.emit(fmt.Sprintf(`\P{%s}`, name))
+	}
+
+	return nil
+}
+
+// readOctal - read up to size octal digits
+func (t *translator) readOctal(size int, value rune) rune {
+	for i := 0; i < size && t.pos < len(t.src) && isOctal(t.src[t.pos]); i++ {
+		value = value*8 + t.src[t.pos] - '0'
+		t.pos++
+	}
+
+	return value
+}
+
+// groupReference - \gn, \g{n}, \g{-n}, \g{+n} or \g{name}
+func (t *translator) groupReference(start int) error {
+	var ref string
+
+	switch t.peek() {
+	case '{':
+		t.pos++
+		ref = t.readWhile(func(x rune) bool { return x != '}' })
+
+		if t.peek() != '}' || ref == "" {
+			return t.errorf(ErrInvalidBackReference, start)
+		}
+
+		t.pos++
+	case '<', '\'':
+		// subroutine calls
+		return t.errorf(ErrUnsupported, start)
+	default:
+		ref = t.readWhile(func(x rune) bool { return x == '-' || x == '+' || unicode.IsDigit(x) })
+	}
+
+	number, err := strconv.Atoi(ref)
+
+	switch {
+	case err != nil && isName(ref):
+		t.emit(`\k<` + ref + `>`)
+		return nil
+	case err != nil:
+		return t.errorf(ErrInvalidBackReference, start)
+	case strings.HasPrefix(ref, "-"):
+		number = t.groups + number + 1
+	case strings.HasPrefix(ref, "+"):
+		number = t.groups + number
+	}
+
+	if number < 1 {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	t.reference(start, number, "", `\k<%s>`)
+
+	return nil
+}
+
+// namedReference - \k<name>, \k'name' or \k{name}
+func (t *translator) namedReference(start int) error {
+	ends := map[rune]rune{'<': '>', '\'': '\'', '{': '}'}
+
+	end, exists := ends[t.peek()]
+	if !exists {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	t.pos++
+	name := t.readWhile(func(x rune) bool { return x != end })
+
+	if t.peek() != end || !isName(name) {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	t.pos++
+	t.emit(`\k<` + name + `>`)
+
+	return nil
+}
+
+func (t *translator) class() error {
+	start := t.pos
+
+	t.emit("[")
+	t.pos++
+
+	if t.peek() == '^' {
+		t.emit("^")
+		t.pos++
+	}
+
+	// ] is the first character of class
+	if t.peek() == ']' {
+		t.emit(`\]`)
+		t.pos++
+	}
+
+	for t.pos < len(t.src) {
+		r := t.src[t.pos]
+		t.token = t.pos
+
+		switch {
+		case r == ']':
+			t.emit("]")
+			t.pos++
+			return nil
+		case r == '\\':
+			if err := t.escape(true); err != nil {
+				return err
+			}
+		case t.hasPrefix("[:"):
+			name, ok := t.posixClass()
+			if !ok {
+				t.emit(`\[`)
+				t.pos++
+				continue
+			}
+
+			t.emit(name)
+		case r == '[':
+			t.emit(`\[`)
+			t.pos++
+		default:
+			t.emit(string(r))
+			t.pos++
+		}
+	}
+
+	return t.errorf(ErrBracketsImbalance, start)
+}
+
+// posixClass - [:alpha:] or [:^alpha:]
+func (t *translator) posixClass() (string, bool) {
+	for i := t.pos + 2; i < len(t.src); i++ {
+		if t.src[i] == ':' && i+1 < len(t.src) && t.src[i+1] == ']' {
+			name := string(t.src[t.pos : i+2])
+			t.pos = i + 2
+			return name, true
+		}
+
+		if t.src[i] != '^' && !unicode.IsLetter(t.src[i]) {
+			break
+		}
+	}
+
+	return "", false
+}
+
+func (t *translator) open() error {
+	start := t.pos
+
+	switch {
+	case t.hasPrefix("(*"):
+		// backtracking control verbs and options like (*UTF)
+		return t.errorf(ErrUnsupported, start)
+	case t.hasPrefix("(?#"):
+		end := t.indexOf(')')
+		if end < 0 {
+			return t.errorf(ErrParenthesesImbalance, start)
+		}
+
+		t.emit(string(t.src[t.pos : end+1]))
+		t.pos = end + 1
+	case t.hasPrefix("(?|"):
+		t.push(&frame{branchReset: true, start: t.groups, max: t.groups})
+		t.emit("(?:")
+		t.pos += 3
+	case t.hasPrefix("(?P="):
+		t.pos += 4
+		name := t.readWhile(func(x rune) bool { return x != ')' })
+
+		if t.peek() != ')' || !isName(name) {
+			return t.errorf(ErrInvalidBackReference, start)
+		}
+
+		t.pos++
+		t.emit(`\k<` + name + `>`)
+	case t.hasPrefix("(?P<"):
+		t.pos += 4
+		return t.namedGroup(start, '>')
+	case t.hasPrefix("(?<="), t.hasPrefix("(?<!"):
+		t.push(new(frame))
+		t.emit(string(t.src[t.pos : t.pos+4]))
+		t.pos += 4
+	case t.hasPrefix("(?<"):
+		t.pos += 3
+		return t.namedGroup(start, '>')
+	case t.hasPrefix("(?'"):
+		t.pos += 3
+		return t.namedGroup(start, '\'')
+	case t.hasPrefix("(?("):
+		return t.condition(start)
+	case t.hasPrefix("(?:"), t.hasPrefix("(?>"), t.hasPrefix("(?="), t.hasPrefix("(?!"):
+		t.push(new(frame))
+		t.emit(string(t.src[t.pos : t.pos+3]))
+		t.pos += 3
+	case t.hasPrefix("(?"):
+		return t.options(start)
+	default:
+		t.pos++
+		t.capture("")
+	}
+
+	return nil
+}
+
+func (t *translator) namedGroup(start int, end rune) error {
+	name := t.readWhile(func(x rune) bool { return x != end })
+
+	if t.peek() != end || !isName(name) {
+		return t.errorf(ErrUnsupported, start)
+	}
+
+	t.pos++
+	t.capture(name)
+
+	return nil
+}
+
+// capture - open capturing group, unnamed groups are named by its numbers
+func (t *translator) capture(name string) {
+	t.groups++
+	if t.groups > t.total {
+		t.total = t.groups
+	}
+
+	t.push(new(frame))
+
+	if name == "" {
+		t.emit(fmt.Sprintf("(?<%d>", t.groups))
+		return
+	}
+
+	t.names[t.groups] = name
+	t.emit("(?<" + name + ">")
+}
+
+// condition - (?(n)...), (?(<name>)...), (?('name')...) or (?(name)...)
+func (t *translator) condition(start int) error {
+	t.pos += 3
+
+	if t.peek() == '?' {
+		// assertion as condition
+		return t.errorf(ErrUnsupported, start)
+	}
+
+	ref := t.readWhile(func(x rune) bool { return x != ')' })
+	if t.peek() != ')' {
+		return t.errorf(ErrParenthesesImbalance, start)
+	}
+
+	t.pos++
+	t.push(new(frame))
+
+	if number, err := strconv.Atoi(ref); err == nil {
+		t.reference(start, number, "", "(?(<%s>)")
+		return nil
+	}
+
+	if len(ref) > 2 && (ref[0] == '<' && ref[len(ref)-1] == '>' || ref[0] == '\'' && ref[len(ref)-1] == '\'') {
+		ref = ref[1 : len(ref)-1]
+	}
+
+	// (?(R)...) and (?(DEFINE)...) are recursion conditions
+	if !isName(ref) || ref == "DEFINE" || strings.HasPrefix(ref, "R") {
+		return t.errorf(ErrUnsupported, start)
+	}
+
+	t.emit("(?(<" + ref + ">)")
+
+	return nil
+}
+
+// options - (?imsx-imsx) or (?imsx-imsx:...),
+// m and x are handled by translator, other options are passed to internal parser
+func (t *translator) options(start int) error {
+	var (
+		enable  strings.Builder
+		disable strings.Builder
+		current = &enable
+		changed = t.flags
+	)
+
+	for i := t.pos + 2; i < len(t.src); i++ {
+		r := t.src[i]
+
+		switch r {
+		case '-':
+			current = &disable
+		case 'm':
+			changed.multiline = current == &enable
+		case 'x':
+			changed.extended = current == &enable
+		case ')', ':':
+			options := enable.String()
+			if disable.Len() > 0 {
+				options += "-" + disable.String()
+			}
+
+			t.pos = i + 1
+
+			if r == ':' {
+				t.push(new(frame))
+				t.flags = changed
+				t.emit("(?" + options + ":")
+				return nil
+			}
+
+			t.flags = changed
+			if options != "" {
+				t.emit("(?" + options + ")")
+			}
+
+			return nil
+		case 'i', 's':
+			current.WriteRune(r)
+		default:
+			// other options, recursion, subroutine calls and etc
+			return t.errorf(ErrUnsupported, start)
+		}
+	}
+
+	return t.errorf(ErrParenthesesImbalance, start)
+}
+
+func (t *translator) close() error {
+	if len(t.frames) == 0 {
+		return t.errorf(ErrParenthesesImbalance, t.pos)
+	}
+
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	if f.branchReset && f.max > t.groups {
+		t.groups = f.max
+	}
+
+	t.flags = f.flags
+	t.emit(")")
+	t.pos++
+
+	return nil
+}
+
+// bar - groups of each alternative of branch reset group starts with the same number
+func (t *translator) bar() {
+	if len(t.frames) > 0 {
+		if f := t.frames[len(t.frames)-1]; f.branchReset {
+			if t.groups > f.max {
+				f.max = t.groups
+			}
+
+			t.groups = f.start
+		}
+	}
+
+	t.emit("|")
+	t.pos++
+}
+
+func (t *translator) push(f *frame) {
+	f.flags = t.flags
+	t.frames = append(t.frames, f)
+}
+
+// reference - add reference which is resolved when all groups are known
+func (t *translator) reference(pos, number int, digits, format string) {
+	t.refs = append(t.refs, &reference{
+		piece:  len(t.pieces),
+		pos:    pos,
+		number: number,
+		digits: digits,
+		format: format,
+	})
+
+	t.emit("")
+}
+
+func (t *translator) resolve() (parser.Translation, error) {
+	for _, ref := range t.refs {
+		switch {
+		case ref.number >= 1 && ref.number <= t.total:
+			name, exists := t.names[ref.number]
+			if !exists {
+				name = strconv.Itoa(ref.number)
+			}
+
+			t.pieces[ref.piece] = parser.NewTranslation(fmt.Sprintf(ref.format, name), ref.pos)
+		case len(ref.digits) > 1 && isOctal(rune(ref.digits[0])):
+			// \N is octal character when there are less than N groups
+			x := &translator{src: []rune(ref.digits)}
+			value := x.readOctal(3, 0)
+
+			t.pieces[ref.piece] = parser.NewTranslation(fmt.Sprintf(`\x{%x}`, value)+string(x.src[x.pos:]), ref.pos)
+		default:
+			return parser.Translation{}, t.errorf(ErrInvalidBackReference, ref.pos)
+		}
+	}
+
+	return parser.Concat(t.pieces...), nil
+}
+
+func (t *translator) emit(s string) {
+	t.pieces = append(t.pieces, parser.NewTranslation(s, t.token))
+}
+
+func (t *translator) emitRune(r rune) {
+	t.emit(fmt.Sprintf(`\x{%x}`, r))
+}
+
+func (t *translator) peek() rune {
+	if t.pos < len(t.src) {
+		return t.src[t.pos]
+	}
+
+	return -1
+}
+
+func (t *translator) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(t.src[t.pos:]), prefix)
+}
+
+func (t *translator) indexOf(r rune) int {
+	for i := t.pos; i < len(t.src); i++ {
+		if t.src[i] == r {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (t *translator) readWhile(predicate func(rune) bool) string {
+	from := t.pos
+
+	for t.pos < len(t.src) && predicate(t.src[t.pos]) {
+		t.pos++
+	}
+
+	return string(t.src[from:t.pos])
+}
+
+func (t *translator) errorf(err error, pos int) error {
+	return parser.NewParseError(string(t.src), pos, err)
+}
+
+func isSpace(x rune) bool {
+	return x == ' ' || x == '\t' || x == '\n' || x == '\v' || x == '\f' || x == '\r'
+}
+
+func isHex(x rune) bool {
+	return x >= '0' && x <= '9' ||
+		x >= 'a' && x <= 'f' ||
+		x >= 'A' && x <= 'F'
+}
+
+// isProperty - check name of property, script or category of unicode
+func isProperty(name string) bool {
+	for _, props := range []map[string]*unicode.RangeTable{
+		unicode.Properties,
+		unicode.Scripts,
+		unicode.Categories,
+	} {
+		if _, exists := props[name]; exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isOctal(x rune) bool {
+	return x >= '0' && x <= '7'
+}
+
+func isName(x string) bool {
+	if x == "" || unicode.IsDigit([]rune(x)[0]) {
+		return false
+	}
+
+	for _, r := range x {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
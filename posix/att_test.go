@@ -0,0 +1,127 @@
+package posix_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche"
+	"github.com/okneniz/cliche/posix"
+	"github.com/okneniz/cliche/quantity"
+	tableTests "github.com/okneniz/cliche/testing"
+)
+
+var (
+	errorsByName = map[string]error{
+		"EPAREN":   posix.ErrParenthesesImbalance,
+		"EBRACK":   posix.ErrBracketsImbalance,
+		"EBRACE":   posix.ErrBracesImbalance,
+		"BADBR":    posix.ErrInvalidInterval,
+		"BADRPT":   posix.ErrInvalidRepetition,
+		"ESUBREG":  posix.ErrInvalidBackReference,
+		"EESCAPE":  posix.ErrTrailingBackslash,
+		"ECOLLATE": posix.ErrInvalidCollatingElement,
+		"ECTYPE":   posix.ErrInvalidCharacterClass,
+		"ERANGE":   posix.ErrInvalidRangeEnd,
+	}
+
+	// known differences with test files
+	knownDifferences = map[string]string{
+		// [-]? can't match two characters, typo in basic.dat
+		"basic.dat:E:a[-]?c:ac": "typo in test file",
+	}
+)
+
+func TestATT(t *testing.T) {
+	t.Parallel()
+
+	files := []string{
+		"basic.dat",
+		"nullsubexpr.txt",
+		"repetition.dat",
+	}
+
+	for _, name := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tests, err := tableTests.LoadATTFile(t, "../testdata/at&t_posix/"+name)
+			require.NoError(t, err)
+			require.NotEmpty(t, tests)
+
+			for _, test := range tests {
+				for _, flag := range []rune{'B', 'E'} {
+					if !test.HasFlag(flag) {
+						continue
+					}
+
+					key := fmt.Sprintf("%s:%c:%s:%s", name, flag, test.Pattern, test.Input)
+
+					t.Run(fmt.Sprintf("%d-%c", test.Line, flag), func(t *testing.T) {
+						if reason, exists := knownDifferences[key]; exists {
+							t.Skip(reason)
+						}
+
+						parser := posix.ExtendedParser
+						if flag == 'B' {
+							parser = posix.BasicParser
+						}
+
+						testATT(t, parser, test)
+					})
+				}
+			}
+		})
+	}
+}
+
+func testATT(t *testing.T, parser cliche.Parser, test *tableTests.ATTTest) {
+	t.Helper()
+
+	tr := cliche.New(parser)
+	err := tr.Add(test.Pattern)
+
+	if test.Error != "" {
+		expected, exists := errorsByName[test.Error]
+		require.True(t, exists, "unknown error %s", test.Error)
+		require.ErrorIs(t, err, expected)
+		return
+	}
+
+	require.NoError(t, err)
+
+	matches := posix.Match(tr, test.Input)
+
+	if test.NoMatch {
+		require.Empty(t, matches, test.String())
+		return
+	}
+
+	require.NotEmpty(t, matches, test.String())
+
+	m := matches[0]
+	actual := make([]tableTests.ATTSpan, 0, len(test.Want))
+	actual = append(actual, toATTSpan(m.Span()))
+
+	for i := 1; i < len(test.Want); i++ {
+		g, exists := m.NamedGroups()[strconv.Itoa(i)]
+		if !exists {
+			actual = append(actual, tableTests.ATTSpan{Unset: true})
+			continue
+		}
+
+		actual = append(actual, toATTSpan(g))
+	}
+
+	require.Equal(t, test.Want, actual, test.String())
+}
+
+func toATTSpan(sp quantity.Interface) tableTests.ATTSpan {
+	if sp.Empty() {
+		return tableTests.ATTSpan{From: sp.From(), To: sp.From()}
+	}
+
+	return tableTests.ATTSpan{From: sp.From(), To: sp.To() + 1}
+}
@@ -0,0 +1,193 @@
+package posix
+
+import (
+	"strconv"
+
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/quantity"
+	c "github.com/okneniz/parsec/common"
+)
+
+// combinators of internal syntax, see translator for details
+
+func parseAnyRune(_ ...rune) c.Combinator[rune, int, rune] {
+	return c.NoneOf[rune, int]("expected any character")
+}
+
+func parseEquivalenceClass(except ...rune) c.Combinator[rune, int, node.Table] {
+	parseEscapedRune := c.Skip(
+		c.Eq[rune, int]("expected '\\' as prefix of character", '\\'),
+		parseAnyRune(except...),
+	)
+
+	parseRune := c.Choice(
+		"expected character of equivalence class",
+		c.Try(parseEscapedRune),
+		c.Try(c.NoneOf[rune, int]("expected character", '\\')),
+	)
+
+	parseEnd := c.Skip(
+		c.Eq[rune, int]("expected '=' as ending of equivalence class", '='),
+		c.Eq[rune, int]("expected ']' as ending of equivalence class", ']'),
+	)
+
+	return func(buf c.Buffer[rune, int]) (node.Table, c.Error[int]) {
+		r, err := parseRune(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = parseEnd(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return equivalenceClass(r), nil
+	}
+}
+
+func parseBackReference(except ...rune) c.Combinator[rune, int, node.Node] {
+	parseIndex := parseNumber(except...)
+	parseEnd := c.Eq[rune, int]("expected '>' as ending of back reference", '>')
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		index, err := parseIndex(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = parseEnd(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return node.NewForNameReference(strconv.Itoa(index)), nil
+	}
+}
+
+// parseSubmatch - parse (?<index:parent>...)
+func parseSubmatch(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	except ...rune,
+) c.Combinator[rune, int, node.Node] {
+	parseIndex := parseNumber(except...)
+	parseColon := c.Eq[rune, int]("expected ':' as separator of submatch indexes", ':')
+	parseEnd := c.Eq[rune, int]("expected '>' as ending of submatch indexes", '>')
+
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		index, err := parseIndex(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = parseColon(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		parent, err := parseIndex(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = parseEnd(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected submatch",
+				err,
+			)
+		}
+
+		return node.NewSubmatch(index, parent, alt), nil
+	}
+}
+
+func parseNotCapturedGroup(
+	parseAlternation c.Combinator[rune, int, node.Alternation],
+	_ ...rune,
+) c.Combinator[rune, int, node.Node] {
+	return func(buf c.Buffer[rune, int]) (node.Node, c.Error[int]) {
+		pos := buf.Position()
+
+		alt, err := parseAlternation(buf)
+		if err != nil {
+			return nil, c.NewParseError(
+				pos,
+				"expected non captured group",
+				err,
+			)
+		}
+
+		return node.NewNotCapturedGroup(alt), nil
+	}
+}
+
+// parseInterval - parse {m}, {m,} or {m,n},
+// bounds are already checked by translator
+func parseInterval(except ...rune) c.Combinator[rune, int, *quantity.Quantity] {
+	parseBound := parseNumber(except...)
+	parseComma := c.Try(c.Eq[rune, int]("expected ',' as separator in interval", ','))
+	parseEnd := c.Eq[rune, int]("expected '}' as ending of interval", '}')
+
+	return func(buf c.Buffer[rune, int]) (*quantity.Quantity, c.Error[int]) {
+		from, err := parseBound(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		q := quantity.New(from, from)
+
+		if _, err := parseComma(buf); err == nil {
+			q = quantity.NewEndlessQuantity(from)
+
+			if to, err := c.Try(parseBound)(buf); err == nil {
+				q = quantity.New(from, to)
+			}
+		}
+
+		_, err = parseEnd(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return q, nil
+	}
+}
+
+func parseNumber(_ ...rune) c.Combinator[rune, int, int] {
+	const zero = rune('0')
+
+	digit := c.Try(c.OneOf[rune, int](
+		"expected digit",
+		'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
+	))
+
+	return func(buf c.Buffer[rune, int]) (int, c.Error[int]) {
+		token, err := digit(buf)
+		if err != nil {
+			return 0, err
+		}
+
+		number := int(token - zero)
+
+		for {
+			token, err := digit(buf)
+			if err != nil {
+				break
+			}
+
+			number = number * 10
+			number += int(token - zero)
+		}
+
+		return number, nil
+	}
+}
@@ -0,0 +1,48 @@
+package posix
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+	"golang.org/x/text/unicode/norm"
+)
+
+// equivalence classes like [=a=] include characters with the same base character
+// after canonical decomposition, like a, à, á, â, ã, ä, å for [=a=],
+// characters are grouped lazily only for the basic multilingual plane
+var (
+	equivalents     map[rune][]rune
+	equivalentsOnce sync.Once
+)
+
+const maxEquivalent = rune(0xFFFF)
+
+func equivalenceClass(x rune) node.Table {
+	equivalentsOnce.Do(func() {
+		equivalents = make(map[rune][]rune)
+
+		for r := rune(0); r <= maxEquivalent; r++ {
+			if b := baseOf(r); b != r {
+				equivalents[b] = append(equivalents[b], r)
+			}
+		}
+	})
+
+	base := baseOf(x)
+	runes := append([]rune{base, x}, equivalents[base]...)
+
+	return unicodeEncoding.NewTable(runes...)
+}
+
+func baseOf(x rune) rune {
+	if !utf8.ValidRune(x) {
+		return x
+	}
+
+	decomposed := norm.NFD.String(string(x))
+	base, _ := utf8.DecodeRuneInString(decomposed)
+
+	return base
+}
@@ -0,0 +1,360 @@
+package posix
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/quantity"
+	"github.com/okneniz/cliche/scanner"
+)
+
+// Matcher - tree or any other matcher which can pass matches to custom output
+type Matcher interface {
+	MatchTo(text string, output node.Output, options ...node.ScanOption)
+}
+
+// Match - scan text and return matches with submatches chosen by POSIX rules
+func Match(m Matcher, text string, options ...node.ScanOption) []*scanner.Match {
+	output := NewOutput()
+	options = append(options, node.ScanOptionSubmatchesHistory)
+	m.MatchTo(text, output, options...)
+	return output.Slice()
+}
+
+// Output - output which choose matches by POSIX rules:
+//
+//   - the leftmost match is chosen, then the longest one;
+//   - subexpressions are chosen from left to right,
+//     each of them is the leftmost and the longest one
+//     (for repeated subexpressions the same rule is applied to each iteration);
+//   - only the last iteration of repeated subexpression is reported,
+//     nested subexpressions are reported only if they are matched in this iteration.
+//
+// Output requires node.ScanOptionSubmatchesHistory (see Match),
+// without it submatches are chosen only by bounds of match.
+type Output struct {
+	// the best candidate for each start position of each leaf of tree
+	candidates map[node.Node]map[int]*candidate
+}
+
+var _ node.Output = NewOutput()
+
+type candidate struct {
+	node        node.Node
+	subString   string
+	span        quantity.Interface
+	namedGroups map[string]quantity.Interface
+	history     map[int][]submatchStep
+	locator     node.Locator
+}
+
+// submatchStep - one match of subexpression
+type submatchStep struct {
+	step   int
+	parent int
+	span   quantity.Interface
+}
+
+// NewOutput - return output which choose matches by POSIX rules
+func NewOutput() *Output {
+	out := new(Output)
+	out.candidates = make(map[node.Node]map[int]*candidate)
+	return out
+}
+
+func (out *Output) String() string {
+	ms := make([]string, 0, 10)
+	for _, v := range out.Slice() {
+		ms = append(ms, v.String())
+	}
+
+	return fmt.Sprintf(
+		"posix.Output(matches=[%s])",
+		strings.Join(ms, ", "),
+	)
+}
+
+func (out *Output) Yield(
+	n node.Node,
+	subString string,
+	sp quantity.Interface,
+	_ []quantity.Interface,
+	namedGroups map[string]quantity.Interface,
+	locator node.Locator,
+) {
+	x := &candidate{
+		node:        n,
+		subString:   subString,
+		span:        sp,
+		namedGroups: namedGroups,
+		history:     parseHistory(namedGroups),
+		locator:     locator,
+	}
+
+	byStart, exists := out.candidates[n]
+	if !exists {
+		byStart = make(map[int]*candidate)
+		out.candidates[n] = byStart
+	}
+
+	if prev, exists := byStart[sp.From()]; !exists || x.preferred(prev) {
+		byStart[sp.From()] = x
+	}
+}
+
+// LastPosOf - each position must be scanned to find all candidates
+func (out *Output) LastPosOf(_ node.Node) (int, bool) {
+	return -1, false
+}
+
+func (out *Output) Flush() {}
+
+func (out *Output) Done() bool {
+	return false
+}
+
+// Slice - return matches of each expression,
+// like repeated calls of regexec from the end of previous match
+func (out *Output) Slice() []*scanner.Match {
+	result := make([]*scanner.Match, 0)
+	keys := make(map[string]struct{})
+
+	for _, byStart := range out.candidates {
+		starts := make([]int, 0, len(byStart))
+		for start := range byStart {
+			starts = append(starts, start)
+		}
+
+		sort.Ints(starts)
+
+		pos := 0
+		prevEnd := -1
+
+		for _, start := range starts {
+			x := byStart[start]
+
+			if start < pos {
+				continue
+			}
+
+			// empty match right after previous match is skipped
+			if x.span.Empty() && start == prevEnd {
+				continue
+			}
+
+			m := x.match()
+
+			if _, exists := keys[m.Key()]; !exists {
+				keys[m.Key()] = struct{}{}
+				result = append(result, m)
+			}
+
+			pos = end(x.span)
+			prevEnd = pos
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].Span(), result[j].Span()
+
+		if a.From() != b.From() {
+			return a.From() < b.From()
+		}
+
+		return end(a) < end(b)
+	})
+
+	return result
+}
+
+func (x *candidate) match() *scanner.Match {
+	reported := x.reported()
+
+	namedGroups := make(map[string]quantity.Interface, len(reported))
+	for index, sp := range reported {
+		namedGroups[strconv.Itoa(index)] = sp
+	}
+
+	// without history only last values are available
+	if len(x.history) == 0 {
+		for name, sp := range x.namedGroups {
+			namedGroups[name] = sp
+		}
+	}
+
+	return scanner.NewMatch(
+		x.node,
+		x.subString,
+		x.span,
+		make([]quantity.Interface, 0),
+		namedGroups,
+		x.locator,
+	)
+}
+
+// reported - return the last match of each subexpression,
+// which is matched in the last iteration of enclosing subexpression
+func (x *candidate) reported() map[int]quantity.Interface {
+	result := make(map[int]quantity.Interface, len(x.history))
+
+	for _, index := range x.indexes() {
+		steps := x.history[index]
+		last := steps[len(steps)-1]
+
+		if last.parent != 0 {
+			if _, exists := result[last.parent]; !exists {
+				continue
+			}
+
+			// it's matched in previous iteration of parent
+			parentSteps := x.history[last.parent]
+			if len(parentSteps) > 1 && last.step < parentSteps[len(parentSteps)-2].step {
+				continue
+			}
+		}
+
+		result[index] = last.span
+	}
+
+	return result
+}
+
+// indexes - return sorted indexes of matched subexpressions
+func (x *candidate) indexes() []int {
+	indexes := make([]int, 0, len(x.history))
+	for index := range x.history {
+		indexes = append(indexes, index)
+	}
+
+	sort.Ints(indexes)
+	return indexes
+}
+
+// preferred - return true if candidate is preferred to other candidate
+// with the same start position
+func (x *candidate) preferred(other *candidate) bool {
+	if end(x.span) != end(other.span) {
+		return end(x.span) > end(other.span)
+	}
+
+	indexes := x.indexes()
+	indexes = append(indexes, other.indexes()...)
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		if z := compareSteps(x.history[index], other.history[index]); z != 0 {
+			return z > 0
+		}
+	}
+
+	return false
+}
+
+// compareSteps - compare matches of subexpression,
+// return 1 if first matches are preferred
+func compareSteps(a, b []submatchStep) int {
+	if len(a) > 0 && len(b) > 0 {
+		// the leftmost and the longest extent of all iterations
+		if z := compareSpans(a[0].span.From(), end(a[len(a)-1].span), b[0].span.From(), end(b[len(b)-1].span)); z != 0 {
+			return z
+		}
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if z := compareSpans(a[i].span.From(), end(a[i].span), b[i].span.From(), end(b[i].span)); z != 0 {
+			return z
+		}
+	}
+
+	switch {
+	case len(a) == len(b):
+		return 0
+	case len(a) > len(b):
+		return compareExtra(a, len(b))
+	default:
+		return -compareExtra(b, len(a))
+	}
+}
+
+// compareExtra - compare longer list of matches with its prefix,
+// empty iterations after non empty ones are useless, so the prefix is preferred,
+// but matched subexpression is preferred to not matched one
+func compareExtra(steps []submatchStep, prefix int) int {
+	for _, x := range steps[prefix:] {
+		if !x.span.Empty() {
+			return 1
+		}
+	}
+
+	if prefix == 0 {
+		return 1
+	}
+
+	return -1
+}
+
+func compareSpans(aFrom, aTo, bFrom, bTo int) int {
+	switch {
+	case aFrom < bFrom:
+		return 1
+	case aFrom > bFrom:
+		return -1
+	case aTo > bTo:
+		return 1
+	case aTo < bTo:
+		return -1
+	}
+
+	return 0
+}
+
+// parseHistory - parse named groups "step:index:parent" (see node.NewSubmatch)
+func parseHistory(namedGroups map[string]quantity.Interface) map[int][]submatchStep {
+	history := make(map[int][]submatchStep)
+
+	for name, sp := range namedGroups {
+		parts := strings.Split(name, ":")
+		if len(parts) != 3 {
+			continue
+		}
+
+		step, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		parent, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+
+		history[index] = append(history[index], submatchStep{
+			step:   step,
+			parent: parent,
+			span:   sp,
+		})
+	}
+
+	for _, steps := range history {
+		sort.Slice(steps, func(i, j int) bool { return steps[i].step < steps[j].step })
+	}
+
+	return history
+}
+
+// end - return exclusive end of span
+func end(sp quantity.Interface) int {
+	if sp.Empty() {
+		return sp.From()
+	}
+
+	return sp.To() + 1
+}
@@ -0,0 +1,82 @@
+package posix
+
+import (
+	"unicode"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// DOC - https://pubs.opengroup.org/onlinepubs/9799919799/basedefs/V1_chap09.html
+//
+// POSIX expressions are translated to syntax of internal parser (see translator),
+// because meaning of the same characters is different in BRE and ERE
+// and depends on position of character in expression.
+
+var (
+	// BasicParser - parser of POSIX basic regular expressions (BRE)
+	BasicParser = &Parser{extended: false}
+
+	// ExtendedParser - parser of POSIX extended regular expressions (ERE)
+	ExtendedParser = &Parser{extended: true}
+
+	// any character, include new line
+	anything = unicodeEncoding.NewTable().Invert(unicode.MaxRune)
+
+	internal = parser.New(func(cfg *parser.Config) {
+		cfg.Class().
+			Items().
+			StringAsValue("[:alnum:]", unicodeEncoding.ASCIIAlnum).
+			StringAsValue("[:alpha:]", unicodeEncoding.ASCIIAlpha).
+			StringAsValue("[:blank:]", unicodeEncoding.ASCIIBlank).
+			StringAsValue("[:cntrl:]", unicodeEncoding.ASCIICntrl).
+			StringAsValue("[:digit:]", unicodeEncoding.ASCIIDigit).
+			StringAsValue("[:graph:]", unicodeEncoding.ASCIIGraph).
+			StringAsValue("[:lower:]", unicodeEncoding.ASCIILower).
+			StringAsValue("[:print:]", unicodeEncoding.ASCIIPrint).
+			StringAsValue("[:punct:]", unicodeEncoding.ASCIIPunct).
+			StringAsValue("[:space:]", unicodeEncoding.ASCIISpace).
+			StringAsValue("[:upper:]", unicodeEncoding.ASCIIUpper).
+			StringAsValue("[:xdigit:]", unicodeEncoding.ASCIIXdigit).
+			WithPrefix("[=", parseEquivalenceClass)
+
+		cfg.Class().
+			Runes().
+			WithPrefix(`\`, parseAnyRune)
+
+		cfg.NonClass().
+			Items().
+			StringAsFunc(`\E`, func() node.Node { return node.NewComment("") }).
+			StringAsFunc(`\A`, node.NewStartOfString).
+			StringAsFunc(`\z`, node.NewEndOfString).
+			StringAsFunc(".", func() node.Node { return node.NewClass(anything) }).
+			WithPrefix(`\k<`, parseBackReference).
+			WithPrefix(`\`, parser.TableAsClass(parser.RuneAsTable(parseAnyRune)))
+
+		cfg.Groups().
+			ParsePrefix("?<", parseSubmatch).
+			ParsePrefix("?:", parseNotCapturedGroup)
+
+		cfg.Quantifier().Items().StringAsValue("?", quantity.New(0, 1))
+		cfg.Quantifier().Items().StringAsValue("+", quantity.NewEndlessQuantity(1))
+		cfg.Quantifier().Items().StringAsValue("*", quantity.NewEndlessQuantity(0))
+		cfg.Quantifier().Items().WithPrefix("{", parseInterval)
+	})
+)
+
+// Parser - parser of POSIX regular expressions,
+// parenthesized subexpressions are parsed as node.NewSubmatch
+type Parser struct {
+	extended bool
+}
+
+// Parse - parse POSIX expression and return node.Alternation as base type for it
+func (p *Parser) Parse(expression string) (node.Alternation, error) {
+	return parser.Translated(p.translate, internal)(expression)
+}
+
+func (p *Parser) translate(expression string) (parser.Translation, error) {
+	return translate(expression, p.extended)
+}
@@ -0,0 +1,124 @@
+package posix_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/posix"
+)
+
+func TestParser_Errors(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		parser     *posix.Parser
+		err        error
+	}
+
+	tests := []test{
+		{expression: "(a", parser: posix.ExtendedParser, err: posix.ErrParenthesesImbalance},
+		{expression: `\(a`, parser: posix.BasicParser, err: posix.ErrParenthesesImbalance},
+		{expression: `a\)`, parser: posix.BasicParser, err: posix.ErrParenthesesImbalance},
+		{expression: "[a", parser: posix.ExtendedParser, err: posix.ErrBracketsImbalance},
+		{expression: "[]", parser: posix.BasicParser, err: posix.ErrBracketsImbalance},
+		{expression: "a{1", parser: posix.ExtendedParser, err: posix.ErrBracesImbalance},
+		{expression: `a\{1`, parser: posix.BasicParser, err: posix.ErrBracesImbalance},
+		{expression: "a{2,1}", parser: posix.ExtendedParser, err: posix.ErrInvalidInterval},
+		{expression: "a{256}", parser: posix.ExtendedParser, err: posix.ErrInvalidInterval},
+		{expression: `a\{x\}`, parser: posix.BasicParser, err: posix.ErrInvalidInterval},
+		{expression: "*a", parser: posix.ExtendedParser, err: posix.ErrInvalidRepetition},
+		{expression: "a|+", parser: posix.ExtendedParser, err: posix.ErrInvalidRepetition},
+		{expression: "^*", parser: posix.ExtendedParser, err: posix.ErrInvalidRepetition},
+		{expression: `\1(a)`, parser: posix.ExtendedParser, err: posix.ErrInvalidBackReference},
+		{expression: `\(a\1\)`, parser: posix.BasicParser, err: posix.ErrInvalidBackReference},
+		{expression: `a\`, parser: posix.BasicParser, err: posix.ErrTrailingBackslash},
+		{expression: "[[.ch.]]", parser: posix.ExtendedParser, err: posix.ErrInvalidCollatingElement},
+		{expression: "[[=aleph=]]", parser: posix.ExtendedParser, err: posix.ErrInvalidCollatingElement},
+		{expression: "[[:foo:]]", parser: posix.ExtendedParser, err: posix.ErrInvalidCharacterClass},
+		{expression: "[z-a]", parser: posix.ExtendedParser, err: posix.ErrInvalidRangeEnd},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := test.parser.Parse(test.expression)
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// errors of translated expressions refer to original expression
+func TestParser_Offsets(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		parser     *posix.Parser
+		offset     int
+		byteOffset int
+		snippet    string
+	}
+
+	tests := []test{
+		{expression: "ü|+", parser: posix.ExtendedParser, offset: 2, byteOffset: 3, snippet: "ü|+\n  ^"},
+		{expression: "(ü", parser: posix.ExtendedParser, offset: 2, byteOffset: 3, snippet: "(ü\n  ^"},
+		{expression: "a{2,1}", parser: posix.ExtendedParser, offset: 6, byteOffset: 6, snippet: "a{2,1}\n      ^"},
+		{expression: `\(a\)\2`, parser: posix.BasicParser, offset: 5, byteOffset: 5, snippet: "\\(a\\)\\2\n     ^"},
+		{expression: "ü\n[b", parser: posix.BasicParser, offset: 4, byteOffset: 5, snippet: "[b\n  ^"},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := test.parser.Parse(test.expression)
+
+			var parseErr *parser.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, test.expression, parseErr.Expression)
+			require.Equal(t, test.offset, parseErr.Offset)
+			require.Equal(t, test.byteOffset, parseErr.ByteOffset)
+			require.Equal(t, test.snippet, parseErr.Snippet())
+		})
+	}
+}
+
+func TestParser_OrdinaryCharacters(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		parser     *posix.Parser
+	}
+
+	tests := []test{
+		{expression: `*a`, parser: posix.BasicParser},
+		{expression: `\(*a\)`, parser: posix.BasicParser},
+		{expression: `^*a`, parser: posix.BasicParser},
+		{expression: `a^b$c`, parser: posix.BasicParser},
+		{expression: `a+?|{}()`, parser: posix.BasicParser},
+		{expression: `a)`, parser: posix.ExtendedParser},
+		{expression: `a{b`, parser: posix.ExtendedParser},
+		{expression: `[]a-]`, parser: posix.ExtendedParser},
+		{expression: `[\]`, parser: posix.ExtendedParser},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := test.parser.Parse(test.expression)
+			require.NoError(t, err)
+		})
+	}
+}
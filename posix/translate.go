@@ -0,0 +1,592 @@
+package posix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/parser"
+)
+
+// errors of POSIX expressions, names of regcomp errors are in comments
+var (
+	ErrParenthesesImbalance    = errors.New("parentheses imbalance")       // REG_EPAREN
+	ErrBracketsImbalance       = errors.New("brackets imbalance")          // REG_EBRACK
+	ErrBracesImbalance         = errors.New("braces imbalance")            // REG_EBRACE
+	ErrInvalidInterval         = errors.New("invalid content of interval") // REG_BADBR
+	ErrInvalidRepetition       = errors.New("repetition without operand")  // REG_BADRPT
+	ErrInvalidBackReference    = errors.New("invalid back reference")      // REG_ESUBREG
+	ErrTrailingBackslash       = errors.New("trailing backslash")          // REG_EESCAPE
+	ErrInvalidCollatingElement = errors.New("invalid collating element")   // REG_ECOLLATE
+	ErrInvalidCharacterClass   = errors.New("invalid character class")     // REG_ECTYPE
+	ErrInvalidRangeEnd         = errors.New("invalid end of range")        // REG_ERANGE
+)
+
+// RE_DUP_MAX - max bound of interval
+const maxRepetitions = 255
+
+var (
+	classes = map[string]struct{}{
+		"alnum": {}, "alpha": {}, "blank": {}, "cntrl": {},
+		"digit": {}, "graph": {}, "lower": {}, "print": {},
+		"punct": {}, "space": {}, "upper": {}, "xdigit": {},
+	}
+
+	// names of collating elements of portable character set
+	collatingElements = map[string]rune{
+		"NUL": 0x00, "SOH": 0x01, "STX": 0x02, "ETX": 0x03, "EOT": 0x04,
+		"ENQ": 0x05, "ACK": 0x06, "alert": 0x07, "backspace": 0x08, "tab": 0x09,
+		"newline": 0x0A, "vertical-tab": 0x0B, "form-feed": 0x0C,
+		"carriage-return": 0x0D, "SO": 0x0E, "SI": 0x0F, "DLE": 0x10,
+		"DC1": 0x11, "DC2": 0x12, "DC3": 0x13, "DC4": 0x14, "NAK": 0x15,
+		"SYN": 0x16, "ETB": 0x17, "CAN": 0x18, "EM": 0x19, "SUB": 0x1A,
+		"ESC": 0x1B, "IS4": 0x1C, "IS3": 0x1D, "IS2": 0x1E, "IS1": 0x1F,
+		"space": ' ', "exclamation-mark": '!', "quotation-mark": '"',
+		"number-sign": '#', "dollar-sign": '$', "percent-sign": '%',
+		"ampersand": '&', "apostrophe": '\'', "left-parenthesis": '(',
+		"right-parenthesis": ')', "asterisk": '*', "plus-sign": '+',
+		"comma": ',', "hyphen": '-', "hyphen-minus": '-', "period": '.',
+		"full-stop": '.', "slash": '/', "solidus": '/', "zero": '0',
+		"one": '1', "two": '2', "three": '3', "four": '4', "five": '5',
+		"six": '6', "seven": '7', "eight": '8', "nine": '9', "colon": ':',
+		"semicolon": ';', "less-than-sign": '<', "equals-sign": '=',
+		"greater-than-sign": '>', "question-mark": '?', "commercial-at": '@',
+		"left-square-bracket": '[', "backslash": '\\', "reverse-solidus": '\\',
+		"right-square-bracket": ']', "circumflex": '^', "circumflex-accent": '^',
+		"underscore": '_', "low-line": '_', "grave-accent": '`',
+		"left-brace": '{', "left-curly-bracket": '{', "vertical-line": '|',
+		"right-brace": '}', "right-curly-bracket": '}', "tilde": '~', "DEL": 0x7F,
+	}
+)
+
+// translator - translate POSIX expression to syntax of internal parser:
+//
+//   - subexpressions to (?<index:parent>...), where parent is index of enclosing subexpression
+//   - back references to \k<index>
+//   - anchors to \A and \z, because POSIX anchors don't depend on new lines
+//   - empty branches and subexpressions to \E
+//   - intervals to {m}, {m,} or {m,n}
+//   - stacked quantifiers like a** to (?:a*)*
+//   - other characters to escaped characters
+type translator struct {
+	src      []rune
+	pos      int
+	extended bool
+
+	groups  int          // count of opened subexpressions
+	parents []int        // indexes of opened subexpressions
+	closed  map[int]bool // closed subexpressions, only they can be referenced
+}
+
+func translate(expression string, extended bool) (parser.Translation, error) {
+	t := &translator{
+		src:      []rune(expression),
+		extended: extended,
+		closed:   make(map[int]bool),
+	}
+
+	result, err := t.expression()
+	if err != nil {
+		return parser.Translation{}, err
+	}
+
+	if !t.eof() {
+		// unmatched \) in BRE
+		return parser.Translation{}, t.errorf(ErrParenthesesImbalance)
+	}
+
+	return result, nil
+}
+
+func (t *translator) errorf(err error) error {
+	return parser.NewParseError(string(t.src), t.pos, err)
+}
+
+func (t *translator) eof() bool {
+	return t.pos >= len(t.src)
+}
+
+func (t *translator) peek(offset int) (rune, bool) {
+	if t.pos+offset >= len(t.src) {
+		return 0, false
+	}
+
+	return t.src[t.pos+offset], true
+}
+
+func (t *translator) next(expected ...rune) bool {
+	for i, x := range expected {
+		if r, ok := t.peek(i); !ok || r != x {
+			return false
+		}
+	}
+
+	return true
+}
+
+// closing - check that subexpression is closed at current position
+func (t *translator) closing() bool {
+	if len(t.parents) == 0 {
+		return false
+	}
+
+	if t.extended {
+		return t.next(')')
+	}
+
+	return t.next('\\', ')')
+}
+
+func (t *translator) expression() (parser.Translation, error) {
+	pieces := make([]parser.Translation, 0, 1)
+
+	for {
+		branch, err := t.branch()
+		if err != nil {
+			return parser.Translation{}, err
+		}
+
+		pieces = append(pieces, branch)
+
+		if !t.extended || !t.next('|') {
+			break
+		}
+
+		pieces = append(pieces, parser.NewTranslation("|", t.pos))
+		t.pos++
+	}
+
+	return parser.Concat(pieces...), nil
+}
+
+func (t *translator) branch() (parser.Translation, error) {
+	pieces := make([]parser.Translation, 0)
+
+	start := t.pos
+
+	for !t.eof() && !t.closing() && !(t.extended && t.next('|')) {
+		// in BRE * is ordinary character at the beginning of expression
+		// or subexpression and after leading ^
+		if !t.extended && t.next('*') && (t.pos == start || t.pos == start+1 && t.src[start] == '^') {
+			pieces = append(pieces, parser.NewTranslation(escape('*'), t.pos))
+			t.pos++
+			continue
+		}
+
+		pos := t.pos
+
+		atom, quantifiable, err := t.atom(start)
+		if err != nil {
+			return parser.Translation{}, err
+		}
+
+		piece, err := t.quantifiers(pos, atom, quantifiable)
+		if err != nil {
+			return parser.Translation{}, err
+		}
+
+		pieces = append(pieces, piece)
+	}
+
+	if len(pieces) == 0 {
+		return parser.NewTranslation(`\E`, t.pos), nil
+	}
+
+	return parser.Concat(pieces...), nil
+}
+
+// atom - return translated atom and flag which is true if atom can be repeated
+func (t *translator) atom(start int) (parser.Translation, bool, error) {
+	pos := t.pos
+	x := t.src[t.pos]
+
+	switch {
+	case x == '.':
+		t.pos++
+		return parser.NewTranslation(".", pos), true, nil
+	case x == '[':
+		t.pos++
+		class, err := t.bracket()
+		return parser.NewTranslation(class, pos), true, err
+	case x == '^' && (t.extended || t.pos == start):
+		t.pos++
+		return parser.NewTranslation(`\A`, pos), !t.extended, nil
+	case x == '$' && (t.extended || t.pos+1 == len(t.src) || t.closingAfter(1)):
+		t.pos++
+		return parser.NewTranslation(`\z`, pos), !t.extended, nil
+	case t.extended && x == '(':
+		t.pos++
+		return t.subexpression(pos)
+	case t.extended && x == ')':
+		// unmatched ) is ordinary character
+		t.pos++
+		return parser.NewTranslation(escape(x), pos), true, nil
+	case t.extended && (x == '*' || x == '+' || x == '?'):
+		return parser.Translation{}, false, t.errorf(ErrInvalidRepetition)
+	case t.extended && x == '{' && t.isInterval(1):
+		return parser.Translation{}, false, t.errorf(ErrInvalidRepetition)
+	case x == '\\':
+		return t.escaped()
+	}
+
+	t.pos++
+	return parser.NewTranslation(escape(x), pos), true, nil
+}
+
+func (t *translator) closingAfter(offset int) bool {
+	pos := t.pos
+	t.pos += offset
+	defer func() { t.pos = pos }()
+
+	return t.closing()
+}
+
+func (t *translator) isInterval(offset int) bool {
+	r, ok := t.peek(offset)
+	return ok && r >= '0' && r <= '9'
+}
+
+func (t *translator) escaped() (parser.Translation, bool, error) {
+	pos := t.pos
+
+	x, ok := t.peek(1)
+	if !ok {
+		return parser.Translation{}, false, t.errorf(ErrTrailingBackslash)
+	}
+
+	switch {
+	case x >= '1' && x <= '9':
+		index := int(x - '0')
+		if !t.closed[index] {
+			return parser.Translation{}, false, t.errorf(ErrInvalidBackReference)
+		}
+
+		t.pos += 2
+		return parser.NewTranslation(fmt.Sprintf(`\k<%d>`, index), pos), true, nil
+	case !t.extended && x == '(':
+		t.pos += 2
+		return t.subexpression(pos)
+	case !t.extended && x == ')':
+		return parser.Translation{}, false, t.errorf(ErrParenthesesImbalance)
+	case !t.extended && x == '{':
+		return parser.Translation{}, false, t.errorf(ErrInvalidRepetition)
+	}
+
+	t.pos += 2
+	return parser.NewTranslation(escape(x), pos), true, nil
+}
+
+// subexpression - parse subexpression which is opened at position start
+func (t *translator) subexpression(start int) (parser.Translation, bool, error) {
+	t.groups++
+	index := t.groups
+
+	parent := 0
+	if len(t.parents) > 0 {
+		parent = t.parents[len(t.parents)-1]
+	}
+
+	t.parents = append(t.parents, index)
+
+	value, err := t.expression()
+	if err != nil {
+		return parser.Translation{}, false, err
+	}
+
+	if !t.closing() {
+		return parser.Translation{}, false, t.errorf(ErrParenthesesImbalance)
+	}
+
+	end := t.pos
+
+	if t.extended {
+		t.pos++
+	} else {
+		t.pos += 2
+	}
+
+	t.parents = t.parents[:len(t.parents)-1]
+	t.closed[index] = true
+
+	return parser.Concat(
+		parser.NewTranslation(fmt.Sprintf("(?<%d:%d>", index, parent), start),
+		value,
+		parser.NewTranslation(")", end),
+	), true, nil
+}
+
+// quantifiers - parse quantifiers of atom which starts at position start
+func (t *translator) quantifiers(
+	start int,
+	atom parser.Translation,
+	quantifiable bool,
+) (parser.Translation, error) {
+	piece := atom
+	count := 0
+
+	for !t.eof() {
+		var q string
+
+		pos := t.pos
+
+		switch {
+		case t.next('*'):
+			t.pos++
+			q = "*"
+		case t.extended && (t.next('+') || t.next('?')):
+			q = string(t.src[t.pos])
+			t.pos++
+		case t.extended && t.next('{') && t.isInterval(1):
+			t.pos++
+
+			interval, err := t.interval()
+			if err != nil {
+				return parser.Translation{}, err
+			}
+
+			q = interval
+		case !t.extended && t.next('\\', '{'):
+			t.pos += 2
+
+			interval, err := t.interval()
+			if err != nil {
+				return parser.Translation{}, err
+			}
+
+			q = interval
+		default:
+			return piece, nil
+		}
+
+		if !quantifiable {
+			return parser.Translation{}, t.errorf(ErrInvalidRepetition)
+		}
+
+		if count > 0 {
+			piece = parser.Concat(
+				parser.NewTranslation("(?:", start),
+				piece,
+				parser.NewTranslation(")", pos),
+			)
+		}
+
+		piece = parser.Concat(piece, parser.NewTranslation(q, pos))
+		count++
+	}
+
+	return piece, nil
+}
+
+// interval - parse content of interval after left brace
+func (t *translator) interval() (string, error) {
+	from, ok := t.number()
+	if !ok {
+		return "", t.errorf(ErrInvalidInterval)
+	}
+
+	to, endless := from, false
+
+	if t.next(',') {
+		t.pos++
+
+		to, ok = t.number()
+		endless = !ok
+	}
+
+	switch {
+	case t.extended && t.next('}'):
+		t.pos++
+	case !t.extended && t.next('\\', '}'):
+		t.pos += 2
+	case t.eof():
+		return "", t.errorf(ErrBracesImbalance)
+	default:
+		return "", t.errorf(ErrInvalidInterval)
+	}
+
+	if from > maxRepetitions || to > maxRepetitions || (!endless && from > to) {
+		return "", t.errorf(ErrInvalidInterval)
+	}
+
+	switch {
+	case endless:
+		return fmt.Sprintf("{%d,}", from), nil
+	case from == to:
+		return fmt.Sprintf("{%d}", from), nil
+	default:
+		return fmt.Sprintf("{%d,%d}", from, to), nil
+	}
+}
+
+// number - parse decimal number, too big numbers are limited
+// by value greater than max bound of interval
+func (t *translator) number() (int, bool) {
+	start := t.pos
+	number := 0
+
+	for t.isInterval(0) {
+		if number <= maxRepetitions {
+			number = number*10 + int(t.src[t.pos]-'0')
+		}
+
+		t.pos++
+	}
+
+	return number, t.pos > start
+}
+
+// bracket - parse bracket expression after left square bracket
+func (t *translator) bracket() (string, error) {
+	var b strings.Builder
+
+	b.WriteRune('[')
+
+	if t.next('^') {
+		t.pos++
+		b.WriteRune('^')
+	}
+
+	first := true
+
+	for {
+		if t.eof() {
+			return "", t.errorf(ErrBracketsImbalance)
+		}
+
+		if t.next(']') && !first {
+			t.pos++
+			break
+		}
+
+		first = false
+
+		switch {
+		case t.next('[', ':'):
+			name, err := t.bracketItem(':')
+			if err != nil {
+				return "", err
+			}
+
+			if _, exists := classes[name]; !exists {
+				return "", t.errorf(ErrInvalidCharacterClass)
+			}
+
+			if t.next('-') && !t.next('-', ']') {
+				return "", t.errorf(ErrInvalidRangeEnd)
+			}
+
+			b.WriteString("[:" + name + ":]")
+		case t.next('[', '='):
+			name, err := t.bracketItem('=')
+			if err != nil {
+				return "", err
+			}
+
+			x, err := t.collatingElement(name)
+			if err != nil {
+				return "", err
+			}
+
+			if t.next('-') && !t.next('-', ']') {
+				return "", t.errorf(ErrInvalidRangeEnd)
+			}
+
+			b.WriteString("[=" + escape(x) + "=]")
+		default:
+			from, err := t.bracketRune()
+			if err != nil {
+				return "", err
+			}
+
+			if !t.next('-') || t.next('-', ']') {
+				b.WriteString(escapeInBracket(from))
+				continue
+			}
+
+			t.pos++
+
+			if t.eof() {
+				return "", t.errorf(ErrBracketsImbalance)
+			}
+
+			to, err := t.bracketRune()
+			if err != nil {
+				return "", err
+			}
+
+			if from > to {
+				return "", t.errorf(ErrInvalidRangeEnd)
+			}
+
+			b.WriteString(escapeInBracket(from) + "-" + escapeInBracket(to))
+		}
+	}
+
+	b.WriteRune(']')
+
+	return b.String(), nil
+}
+
+// bracketItem - parse [:name:], [=name=] or [.name.] and return name
+func (t *translator) bracketItem(delimiter rune) (string, error) {
+	start := t.pos
+	t.pos += 2
+
+	for !t.next(delimiter, ']') {
+		if t.eof() {
+			t.pos = start
+			return "", t.errorf(ErrBracketsImbalance)
+		}
+
+		t.pos++
+	}
+
+	name := string(t.src[start+2 : t.pos])
+	t.pos += 2
+
+	return name, nil
+}
+
+func (t *translator) bracketRune() (rune, error) {
+	if t.next('[', '.') {
+		name, err := t.bracketItem('.')
+		if err != nil {
+			return 0, err
+		}
+
+		return t.collatingElement(name)
+	}
+
+	x := t.src[t.pos]
+	t.pos++
+
+	return x, nil
+}
+
+// collatingElement - return single character by name of collating element,
+// multi-character collating elements aren't supported
+func (t *translator) collatingElement(name string) (rune, error) {
+	runes := []rune(name)
+	if len(runes) == 1 {
+		return runes[0], nil
+	}
+
+	if x, exists := collatingElements[name]; exists {
+		return x, nil
+	}
+
+	return 0, t.errorf(ErrInvalidCollatingElement)
+}
+
+func escape(x rune) string {
+	if x < unicode.MaxASCII && (unicode.IsLetter(x) || unicode.IsDigit(x)) {
+		return string(x)
+	}
+
+	return `\` + string(x)
+}
+
+func escapeInBracket(x rune) string {
+	return `\` + string(x)
+}
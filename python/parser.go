@@ -0,0 +1,165 @@
+package python
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// DOC - https://docs.python.org/3/library/re.html#regular-expression-syntax
+//
+// Meaning of some Python syntax depends on flags and context of expression
+// (verbose, multiline and ASCII modes, numbers and names of groups),
+// so expressions are translated to syntax of internal parser (see translator).
+
+var (
+	// DefaultParser - parser of Python expressions without flags (re.compile(pattern))
+	DefaultParser = &Parser{}
+
+	// \d, \w and \s of str patterns are defined for unicode
+	digit = unicodeEncoding.NewTableByPredicate(unicode.MaxRune, func(x rune) bool {
+		return unicode.Is(unicode.Nd, x)
+	})
+	word = unicodeEncoding.NewTableByPredicate(unicode.MaxRune, func(x rune) bool {
+		return x == '_' || unicode.IsLetter(x) || unicode.IsNumber(x)
+	})
+	space = unicodeEncoding.NewTable(
+		'\t', '\n', '\v', '\f', '\r', '\x1c', '\x1d', '\x1e', '\x1f', ' ',
+		'\u0085', '\u00a0', '\u1680',
+		'\u2000', '\u2001', '\u2002', '\u2003', '\u2004', '\u2005',
+		'\u2006', '\u2007', '\u2008', '\u2009', '\u200a',
+		'\u2028', '\u2029', '\u202f', '\u205f', '\u3000',
+	)
+
+	// \d, \w and \s with (?a) flag
+	asciiDigit = unicodeEncoding.NewTableByPredicate(unicode.MaxASCII, isDigit)
+	asciiWord  = unicodeEncoding.NewTableByPredicate(unicode.MaxASCII, func(x rune) bool {
+		return x == '_' || isDigit(x) || isASCIILetter(x)
+	})
+	asciiSpace = unicodeEncoding.NewTable('\t', '\n', '\v', '\f', '\r', ' ')
+
+	notDigit      = digit.Invert(unicode.MaxRune)
+	notWord       = word.Invert(unicode.MaxRune)
+	notSpace      = space.Invert(unicode.MaxRune)
+	notASCIIDigit = asciiDigit.Invert(unicode.MaxRune)
+	notASCIIWord  = asciiWord.Invert(unicode.MaxRune)
+	notASCIISpace = asciiSpace.Invert(unicode.MaxRune)
+	anyRune       = unicodeEncoding.NewTable().Invert(unicode.MaxRune)
+	notNewLine    = unicodeEncoding.NewTable('\n').Invert(unicode.MaxRune)
+
+	internal = parser.New(func(cfg *parser.Config) {
+		cfg.Class().
+			Items().
+			StringAsValue(`\d`, digit).
+			StringAsValue(`\D`, notDigit).
+			StringAsValue(`\w`, word).
+			StringAsValue(`\W`, notWord).
+			StringAsValue(`\s`, space).
+			StringAsValue(`\S`, notSpace).
+			StringAsValue("[:digit:]", asciiDigit).
+			StringAsValue("[:^digit:]", notASCIIDigit).
+			StringAsValue("[:word:]", asciiWord).
+			StringAsValue("[:^word:]", notASCIIWord).
+			StringAsValue("[:space:]", asciiSpace).
+			StringAsValue("[:^space:]", notASCIISpace)
+
+		cfg.Class().
+			Runes().
+			WithPrefix(`\x`, parseHexChar).
+			WithPrefix(`\`, parseEscapedRune)
+
+		cfg.NonClass().
+			Items().
+			StringAsFunc(`\A`, node.NewStartOfString).
+			StringAsFunc(`\z`, node.NewEndOfString).
+			StringAsFunc(`\Z`, node.NewEndOfStringAndNewLine).
+			WithPrefix(`.`, parser.TableAsClass(parser.Const(anyRune))).
+			WithPrefix(`\N`, parser.TableAsClass(parser.Const(notNewLine))).
+			WithPrefix(`\d`, parser.TableAsClass(parser.Const(digit))).
+			WithPrefix(`\D`, parser.TableAsClass(parser.Const(notDigit))).
+			WithPrefix(`\w`, parser.TableAsClass(parser.Const(word))).
+			WithPrefix(`\W`, parser.TableAsClass(parser.Const(notWord))).
+			WithPrefix(`\s`, parser.TableAsClass(parser.Const(space))).
+			WithPrefix(`\S`, parser.TableAsClass(parser.Const(notSpace))).
+			WithPrefix(`\x`, parser.TableAsClass(parser.RuneAsTable(parseHexChar))).
+			WithPrefix(`\k`, parseNamedReference).
+			WithPrefix(`\`, parser.TableAsClass(parser.RuneAsTable(parseEscapedRune)))
+
+		cfg.Groups().
+			Parse(parseOptions).
+			ParsePrefix("?(", parseCondition).
+			ParsePrefix("?:", parseNotCapturedGroup).
+			ParsePrefix("?<", parseNamedGroup).
+			ParsePrefix("?>", parseAtomicGroup).
+			ParsePrefix("?=", parseLookAhead).
+			ParsePrefix("?!", parseNegativeLookAhead).
+			ParsePrefix("?<=", parseLookBehind).
+			ParsePrefix("?<!", parseNegativeLookBehind)
+
+		cfg.Quantifier().Items().StringAsValue("?", quantity.New(0, 1))
+		cfg.Quantifier().Items().StringAsValue("+", quantity.NewEndlessQuantity(1))
+		cfg.Quantifier().Items().StringAsValue("*", quantity.NewEndlessQuantity(0))
+		cfg.Quantifier().Items().StringAsValue("??", quantity.New(0, 1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("+?", quantity.NewEndlessQuantity(1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("*?", quantity.NewEndlessQuantity(0).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("?+", quantity.New(0, 1).AsPossessive())
+		cfg.Quantifier().Items().StringAsValue("++", quantity.NewEndlessQuantity(1).AsPossessive())
+		cfg.Quantifier().Items().StringAsValue("*+", quantity.NewEndlessQuantity(0).AsPossessive())
+		cfg.Quantifier().Items().WithPrefix("{", parser.OptionalSuffix(
+			parseQuantity,
+			map[rune]func(*quantity.Quantity) *quantity.Quantity{
+				'?': (*quantity.Quantity).AsLazy,
+				'+': (*quantity.Quantity).AsPossessive,
+			},
+		))
+	})
+)
+
+// Parser - parser of Python expressions with flags of re.compile,
+// capturing groups are parsed as node.NewSubmatch and reported as named groups "1", "2" and etc
+type Parser struct {
+	flags string
+	mods  modifiers
+}
+
+// New - make parser for flags like inline flags (for example "im" for re.I | re.M),
+// str patterns are unicode by default, so u flag doesn't change anything
+func New(flags string) (*Parser, error) {
+	p := &Parser{flags: flags}
+
+	for _, x := range flags {
+		switch x {
+		case 'a', 'i', 'm', 's', 'u', 'x':
+			p.mods.set(x, true)
+		case 'L':
+			return nil, fmt.Errorf("%w: cannot use LOCALE flag with a str pattern", ErrInvalidFlags)
+		default:
+			return nil, fmt.Errorf("%w: unknown flag '%c'", ErrInvalidFlags, x)
+		}
+	}
+
+	if strings.ContainsRune(flags, 'a') && strings.ContainsRune(flags, 'u') {
+		return nil, fmt.Errorf("%w: ASCII and UNICODE flags are incompatible", ErrInvalidFlags)
+	}
+
+	return p, nil
+}
+
+// Flags - return flags of parser
+func (p *Parser) Flags() string {
+	return p.flags
+}
+
+// Parse - parse Python expression and return node.Alternation as base type for it
+func (p *Parser) Parse(expression string) (node.Alternation, error) {
+	return parser.Translated(p.translate, internal)(expression)
+}
+
+func (p *Parser) translate(expression string) (parser.Translation, error) {
+	return translate(expression, p.mods)
+}
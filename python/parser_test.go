@@ -0,0 +1,185 @@
+package python_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/python"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		flags string
+		err   error
+	}
+
+	tests := []test{
+		{flags: ""},
+		{flags: "aimsx"},
+		{flags: "u"},
+		{flags: "L", err: python.ErrInvalidFlags},
+		{flags: "g", err: python.ErrInvalidFlags},
+		{flags: "au", err: python.ErrInvalidFlags},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := python.New(test.flags)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.flags, p.Flags())
+		})
+	}
+}
+
+func TestParser_Errors(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		flags      string
+		err        error
+	}
+
+	tests := []test{
+		{expression: "(?L)a", err: python.ErrInvalidFlags},
+		{expression: "(?au)a", err: python.ErrInvalidFlags},
+		{expression: "(?-a:a)", err: python.ErrInvalidFlags},
+		{expression: "(a", err: python.ErrParenthesesImbalance},
+		{expression: "a)", err: python.ErrParenthesesImbalance},
+		{expression: "[a", err: python.ErrBracketsImbalance},
+		{expression: "*a", err: python.ErrNothingToRepeat},
+		{expression: "^*", err: python.ErrNothingToRepeat},
+		{expression: "(?=a)|*", err: python.ErrNothingToRepeat},
+		{expression: "a**", err: python.ErrMultipleRepeat},
+		{expression: "a{2,1}", err: python.ErrInvalidQuantifier},
+		{expression: "(?Q)", err: python.ErrInvalidGroup},
+		{expression: "(?P<1a>a)", err: python.ErrInvalidGroupName},
+		{expression: "(?P<a>a)(?P<a>b)", err: python.ErrDuplicateGroupName},
+		{expression: "(?P=a)", err: python.ErrUnknownGroupName},
+		{expression: "(?(a)b)", err: python.ErrUnknownGroupName},
+		{expression: `(a)\2`, err: python.ErrInvalidBackReference},
+		{expression: `(a\1)`, err: python.ErrOpenGroupReference},
+		{expression: "(?(1)a|b|c)(d)", err: python.ErrInvalidCondition},
+		{expression: `\q`, err: python.ErrInvalidEscape},
+		{expression: `\x4`, err: python.ErrInvalidEscape},
+		{expression: `a\`, err: python.ErrTrailingBackslash},
+		{expression: `[z-a]`, err: python.ErrInvalidRange},
+		{expression: `[\d-z]`, err: python.ErrInvalidRange},
+		{expression: `(?<=a+)b`, err: python.ErrInvalidLookBehind},
+		{expression: `(?<!a|bc)d`, err: python.ErrInvalidLookBehind},
+		{expression: `\N{DIGIT ONE}`, err: python.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression+"/"+test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := python.New(test.flags)
+			require.NoError(t, err)
+
+			_, err = p.Parse(test.expression)
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// errors of translated expressions refer to original expression
+func TestParser_Offsets(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		flags      string
+		offset     int
+		byteOffset int
+		snippet    string
+	}
+
+	tests := []test{
+		{expression: "a{2,1}", offset: 1, byteOffset: 1, snippet: "a{2,1}\n ^"},
+		{expression: "ü)", flags: "i", offset: 1, byteOffset: 2, snippet: "ü)\n ^"},
+		{expression: "(?x) ü |\n *", offset: 10, byteOffset: 11, snippet: " *\n ^"},
+		{expression: "(?<=a+)b", offset: 6, byteOffset: 6, snippet: "(?<=a+)b\n      ^"},
+		{expression: "ü\n(b", offset: 4, byteOffset: 5, snippet: "(b\n  ^"},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression+"/"+test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := python.New(test.flags)
+			require.NoError(t, err)
+
+			_, err = p.Parse(test.expression)
+
+			var parseErr *parser.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, test.expression, parseErr.Expression)
+			require.Equal(t, test.offset, parseErr.Offset)
+			require.Equal(t, test.byteOffset, parseErr.ByteOffset)
+			require.Equal(t, test.snippet, parseErr.Snippet())
+		})
+	}
+}
+
+func TestParser_Keys(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		flags      string
+		same       string
+	}
+
+	tests := []test{
+		{expression: `\101`, same: `A`},
+		{expression: `\x41`, same: `A`},
+		{expression: `A`, same: `A`},
+		{expression: `\U00000041`, same: `A`},
+		{expression: `(?P<x>a)(?P=x)`, same: `(?P<x>a)\1`},
+		{expression: `a{,2}`, same: `a{0,2}`},
+		{expression: `a{x}`, same: `a\{x\}`},
+		{expression: `[]a]`, same: `[\]a]`},
+		{expression: `(?#comment)a`, same: `a`},
+		{expression: "a b # comment\n", flags: "x", same: `ab`},
+		{expression: `(?x)a b`, same: `ab`},
+		{expression: `.`, flags: "s", same: `(?s).`},
+		{expression: `(a|)b`, same: `(?s)(a|(?=.|\Z))b`},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression+"/"+test.flags, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := python.New(test.flags)
+			require.NoError(t, err)
+
+			expected, err := python.DefaultParser.Parse(test.same)
+			require.NoError(t, err)
+
+			actual, err := p.Parse(test.expression)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.GetKey(), actual.GetKey())
+		})
+	}
+}
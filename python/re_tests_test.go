@@ -0,0 +1,126 @@
+package python_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche"
+	"github.com/okneniz/cliche/python"
+	"github.com/okneniz/cliche/quantity"
+	"github.com/okneniz/cliche/scanner"
+	tableTests "github.com/okneniz/cliche/testing"
+)
+
+// known differences with CPython, by line of Lib/test/re_tests.py
+var knownDifferences = map[int]string{
+	215: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	216: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	217: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	408: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	409: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	410: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	531: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	532: "greedy quantifier doesn't take precedence over next quantifier in submatches",
+	543: "first variant of alternation doesn't take precedence over the second in lazy repetition",
+}
+
+func TestCPython(t *testing.T) {
+	t.Parallel()
+
+	tests, err := tableTests.LoadCPythonFile(t, "../testdata/cpython/re_tests.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, tests)
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d", test.Line), func(t *testing.T) {
+			t.Parallel()
+
+			if reason, exists := knownDifferences[test.Line]; exists {
+				t.Skip(reason)
+			}
+
+			testCPython(t, test)
+		})
+	}
+}
+
+func testCPython(t *testing.T, test *tableTests.CPythonTest) {
+	t.Helper()
+
+	tr := cliche.New(python.DefaultParser)
+	err := tr.Add(test.Pattern)
+
+	if test.Error {
+		require.Error(t, err, test.String())
+		return
+	}
+
+	require.NoError(t, err, test.String())
+
+	m := search(tr.Match(test.Input))
+
+	if test.NoMatch {
+		require.Nil(t, m, test.String())
+		return
+	}
+
+	require.NotNil(t, m, test.String())
+
+	names := make(map[int]string, len(test.Names))
+	for name, number := range test.Names {
+		names[number] = name
+	}
+
+	actual := make([]*tableTests.CPythonSpan, 0, len(test.Groups))
+	actual = append(actual, toCPythonSpan(m.Span()))
+
+	for i := 1; i < len(test.Groups); i++ {
+		name, exists := names[i]
+		if !exists {
+			name = strconv.Itoa(i)
+		}
+
+		g, exists := m.NamedGroups()[name]
+		if !exists {
+			actual = append(actual, nil)
+			continue
+		}
+
+		actual = append(actual, toCPythonSpan(g))
+	}
+
+	require.Equal(t, spansToString(test.Groups), spansToString(actual), test.String())
+}
+
+// search - the leftmost match like re.search
+func search(matches []*scanner.Match) *scanner.Match {
+	var result *scanner.Match
+
+	for _, m := range matches {
+		if result == nil || m.Span().From() < result.Span().From() {
+			result = m
+		}
+	}
+
+	return result
+}
+
+func spansToString(spans []*tableTests.CPythonSpan) []string {
+	result := make([]string, len(spans))
+	for i, x := range spans {
+		result[i] = x.String()
+	}
+
+	return result
+}
+
+func toCPythonSpan(sp quantity.Interface) *tableTests.CPythonSpan {
+	if sp.Empty() {
+		return &tableTests.CPythonSpan{sp.From(), sp.From()}
+	}
+
+	return &tableTests.CPythonSpan{sp.From(), sp.To() + 1}
+}
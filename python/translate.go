@@ -0,0 +1,1004 @@
+package python
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/okneniz/cliche/parser"
+)
+
+// translator - rewrite Python expression to syntax of internal parser.
+//
+// It handles the syntax which depends on flags and context of expression:
+//
+//   - global flags (?aimsux) are allowed only at the start of expression,
+//     flags of groups (?aimsux-imsx:...) are scoped by groups;
+//   - (?x) verbose mode, whitespaces and # comments are removed;
+//   - ^ and $ are replaced by \A and \Z without (?m)
+//     and by look-arounds for new lines with it;
+//   - . is replaced by \N (any character except new line) without (?s);
+//   - \d, \w, \s and \b are replaced by ASCII classes with (?a);
+//   - capturing groups are numbered and translated to (?<number>...),
+//     named groups (?P<name>...) keep names;
+//   - back references (\1, (?P=name)) are resolved to \k<number> or \k<name>;
+//   - escaped characters are replaced by \x{...};
+//   - empty branches like () or (a|) are replaced by assertion which is always true;
+//   - sizes of look-behinds are checked, internal parser could parse invalid one as characters.
+
+var (
+	ErrInvalidFlags         = errors.New("bad inline flag")
+	ErrParenthesesImbalance = errors.New("parentheses imbalance")
+	ErrBracketsImbalance    = errors.New("unterminated character set")
+	ErrNothingToRepeat      = errors.New("nothing to repeat")
+	ErrMultipleRepeat       = errors.New("multiple repeat")
+	ErrInvalidQuantifier    = errors.New("min repeat greater than max repeat")
+	ErrInvalidGroup         = errors.New("unknown extension")
+	ErrInvalidGroupName     = errors.New("bad character in group name")
+	ErrDuplicateGroupName   = errors.New("redefinition of group name")
+	ErrUnknownGroupName     = errors.New("unknown group name")
+	ErrInvalidBackReference = errors.New("invalid group reference")
+	ErrOpenGroupReference   = errors.New("cannot refer to an open group")
+	ErrInvalidCondition     = errors.New("conditional backref with more than two branches")
+	ErrInvalidEscape        = errors.New("bad escape")
+	ErrTrailingBackslash    = errors.New("bad escape (end of pattern)")
+	ErrInvalidRange         = errors.New("bad character range")
+	ErrInvalidLookBehind    = errors.New("look-behind requires fixed-width pattern")
+	ErrUnsupported          = errors.New("unsupported syntax")
+)
+
+const (
+	// whitespaces which are ignored in verbose mode
+	whitespaces = " \t\n\r\v\f"
+
+	startOfLine = `(?<![^\x{a}])`
+	endOfLine   = `(?![^\x{a}])`
+
+	// internal parser doesn't support empty sequences like () or (a|),
+	// so empty branch is replaced by assertion which is always true
+	emptyBranch = `(?=.|\z)`
+)
+
+// kinds of the previous item of sequence, it's checked before repetition
+const (
+	nothing    = iota // start of sequence or assertion
+	repeatable        // character, class, group or reference
+	repetition        // quantified item
+)
+
+// ASCII classes of internal parser for (?a) flag
+var asciiClasses = map[rune]string{
+	'd': "[:digit:]",
+	'D': "[:^digit:]",
+	'w': "[:word:]",
+	'W': "[:^word:]",
+	's': "[:space:]",
+	'S': "[:^space:]",
+}
+
+type translator struct {
+	src      []rune
+	pos      int
+	token    int                  // position of token which is translated
+	pieces   []parser.Translation // references of conditions are resolved after the whole expression
+	refs     []*reference
+	groups   int
+	names    map[int]string
+	numbers  map[string]int
+	closed   map[int]bool
+	frames   []*frame
+	previous int  // kind of the previous item
+	started  bool // something is emitted, global flags aren't allowed anymore
+	branch   int  // index of the first piece of the current branch
+	modifiers
+}
+
+// modifiers - flags of expression, which could be changed for group by (?aimsux-imsx:...)
+type modifiers struct {
+	ascii      bool // a
+	ignoreCase bool // i
+	multiline  bool // m
+	dotAll     bool // s
+	unicode    bool // u
+	verbose    bool // x
+}
+
+// frame - opened group
+type frame struct {
+	modifiers  // modifiers before group
+	group      int
+	condition  bool
+	branches   int
+	lookBehind bool
+	body       int // index of the first piece of group body
+}
+
+// reference - number of group in condition (?(1)yes|no),
+// the group could be defined after condition
+type reference struct {
+	piece  int
+	pos    int
+	number int
+}
+
+func translate(expression string, mods modifiers) (parser.Translation, error) {
+	t := &translator{
+		src:       []rune(expression),
+		pieces:    make([]parser.Translation, 0, len(expression)),
+		names:     make(map[int]string),
+		numbers:   make(map[string]int),
+		closed:    make(map[int]bool),
+		modifiers: mods,
+	}
+
+	for t.pos < len(t.src) {
+		if err := t.next(); err != nil {
+			return parser.Translation{}, err
+		}
+	}
+
+	if len(t.frames) > 0 {
+		return parser.Translation{}, t.errorf(ErrParenthesesImbalance, len(t.src))
+	}
+
+	t.token = len(t.src)
+	t.fillEmptyBranch()
+
+	translated, err := t.resolve()
+	if err != nil {
+		return parser.Translation{}, err
+	}
+
+	// global flags are known after the whole expression
+	if t.ignoreCase {
+		translated = parser.Concat(
+			parser.NewTranslation("(?i:", 0),
+			translated,
+			parser.NewTranslation(")", len(t.src)),
+		)
+	}
+
+	return translated, nil
+}
+
+func (t *translator) next() error {
+	r := t.src[t.pos]
+	t.token = t.pos
+
+	switch {
+	case t.verbose && strings.ContainsRune(whitespaces, r):
+		t.pos++
+	case t.verbose && r == '#':
+		for t.pos < len(t.src) && t.src[t.pos] != '\n' {
+			t.pos++
+		}
+	case r == '\\':
+		return t.escape()
+	case r == '[':
+		return t.class()
+	case r == '(':
+		return t.open()
+	case r == ')':
+		return t.close()
+	case r == '*', r == '+', r == '?':
+		t.pos++
+		return t.quantifier(string(r), t.pos-1)
+	case r == '{':
+		return t.braces()
+	case r == '|':
+		return t.bar()
+	case r == '^':
+		if t.multiline {
+			t.assertion(startOfLine)
+		} else {
+			t.assertion(`\A`)
+		}
+
+		t.pos++
+	case r == '$':
+		if t.multiline {
+			t.assertion(endOfLine)
+		} else {
+			t.assertion(`\Z`)
+		}
+
+		t.pos++
+	case r == '.':
+		if t.dotAll {
+			t.atom(".") // any character
+		} else {
+			t.atom(`\N`) // any character except new line
+		}
+
+		t.pos++
+	case r == '}', r == ']':
+		t.atom(`\` + string(r))
+		t.pos++
+	default:
+		t.atom(string(r))
+		t.pos++
+	}
+
+	return nil
+}
+
+// quantifier - emit quantifier with optional lazy or possessive suffix,
+// quantifier is already read from start position
+func (t *translator) quantifier(q string, start int) error {
+	switch t.previous {
+	case nothing:
+		return t.errorf(ErrNothingToRepeat, start)
+	case repetition:
+		return t.errorf(ErrMultipleRepeat, start)
+	}
+
+	if x := t.peek(); x == '?' || x == '+' {
+		q += string(x)
+		t.pos++
+	}
+
+	t.pieces = append(t.pieces, parser.NewTranslation(q, t.token))
+	t.previous = repetition
+
+	return nil
+}
+
+// braces - {n}, {n,}, {,m}, {,} or {n,m} quantifier, otherwise it's literal brace
+func (t *translator) braces() error {
+	start := t.pos
+	t.pos++
+
+	from := t.readWhile(isDigit)
+	to := from
+	comma := t.peek() == ','
+
+	if comma {
+		t.pos++
+		to = t.readWhile(isDigit)
+	}
+
+	if t.peek() != '}' || !comma && from == "" {
+		t.pos = start + 1
+		t.atom(literal('{'))
+
+		return nil
+	}
+
+	t.pos++
+
+	x, y := 0, -1
+
+	if from != "" {
+		n, err := strconv.Atoi(from)
+		if err != nil {
+			return t.errorf(ErrInvalidQuantifier, start)
+		}
+
+		x = n
+	}
+
+	if to != "" {
+		n, err := strconv.Atoi(to)
+		if err != nil || n < x {
+			return t.errorf(ErrInvalidQuantifier, start)
+		}
+
+		y = n
+	}
+
+	q := fmt.Sprintf("{%d,%d}", x, y)
+
+	switch {
+	case !comma:
+		q = fmt.Sprintf("{%d}", x)
+	case y < 0:
+		q = fmt.Sprintf("{%d,}", x)
+	}
+
+	return t.quantifier(q, start)
+}
+
+func (t *translator) escape() error {
+	start := t.pos
+
+	if t.pos+1 >= len(t.src) {
+		return t.errorf(ErrTrailingBackslash, start)
+	}
+
+	r := t.src[t.pos+1]
+	t.pos += 2
+
+	switch {
+	case r == 'A':
+		t.assertion(`\A`)
+	case r == 'Z':
+		t.assertion(`\z`)
+	case r == 'b':
+		t.assertion(t.wordBoundary(false))
+	case r == 'B':
+		t.assertion(t.wordBoundary(true))
+	case strings.ContainsRune("dDwWsS", r):
+		t.atom(t.category(r, false))
+	case r == '0':
+		t.pos--
+		t.atom(literal(t.readOctal(3)))
+	case isDigit(r):
+		return t.numberedReference(start)
+	default:
+		t.pos = start
+
+		x, err := t.characterEscape()
+		if err != nil {
+			return err
+		}
+
+		t.atom(literal(x))
+	}
+
+	return nil
+}
+
+// numberedReference - \N back reference or octal escape of three digits
+func (t *translator) numberedReference(start int) error {
+	t.pos = start + 1
+	digits := string(t.src[t.pos])
+	t.pos++
+
+	if isDigit(t.peek()) {
+		digits += string(t.src[t.pos])
+		t.pos++
+
+		if isOctal(rune(digits[0])) && isOctal(rune(digits[1])) && isOctal(t.peek()) {
+			t.pos = start + 1
+
+			x := t.readOctal(3)
+			if x > 0o377 {
+				return t.errorf(ErrInvalidEscape, start)
+			}
+
+			t.atom(literal(x))
+
+			return nil
+		}
+	}
+
+	number, err := strconv.Atoi(digits)
+	if err != nil || number > t.groups {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	return t.backReference(start, number)
+}
+
+// backReference - reference to closed group by number
+func (t *translator) backReference(start, number int) error {
+	if !t.closed[number] {
+		return t.errorf(ErrOpenGroupReference, start)
+	}
+
+	name, exists := t.names[number]
+	if !exists {
+		name = strconv.Itoa(number)
+	}
+
+	t.atom(`\k<` + name + `>`)
+
+	return nil
+}
+
+// characterEscape - escape which is the same in character class and out of it
+func (t *translator) characterEscape() (rune, error) {
+	start := t.pos
+	r := t.src[t.pos+1]
+	t.pos += 2
+
+	switch {
+	case r == 'a':
+		return '\a', nil
+	case r == 'f':
+		return '\f', nil
+	case r == 'n':
+		return '\n', nil
+	case r == 'r':
+		return '\r', nil
+	case r == 't':
+		return '\t', nil
+	case r == 'v':
+		return '\v', nil
+	case r == 'x', r == 'u', r == 'U':
+		size := map[rune]int{'x': 2, 'u': 4, 'U': 8}[r]
+
+		x, ok := t.readHex(size)
+		if !ok || x > unicode.MaxRune {
+			return 0, t.errorf(ErrInvalidEscape, start)
+		}
+
+		return x, nil
+	case r == 'N' && t.peek() == '{':
+		// named unicode characters
+		return 0, t.errorf(ErrUnsupported, start)
+	case r <= unicode.MaxASCII && (isASCIILetter(r) || isDigit(r)):
+		return 0, t.errorf(ErrInvalidEscape, start)
+	default:
+		return r, nil
+	}
+}
+
+// category - \d, \D, \w, \W, \s or \S, they are ASCII classes with (?a)
+func (t *translator) category(r rune, inClass bool) string {
+	if !t.ascii {
+		return `\` + string(r)
+	}
+
+	if inClass {
+		return asciiClasses[r]
+	}
+
+	return "[" + asciiClasses[r] + "]"
+}
+
+// wordBoundary - \b or \B, word characters depends on (?a)
+func (t *translator) wordBoundary(negative bool) string {
+	w := t.category('w', false)
+
+	if negative {
+		return "(?:(?<=" + w + ")(?=" + w + ")|(?<!" + w + ")(?!" + w + "))"
+	}
+
+	return "(?:(?<!" + w + ")(?=" + w + ")|(?<=" + w + ")(?!" + w + "))"
+}
+
+// class - [...] or [^...], ] is literal at the beginning of class
+func (t *translator) class() error {
+	start := t.pos
+
+	var b strings.Builder
+
+	b.WriteString("[")
+	t.pos++
+
+	if t.peek() == '^' {
+		b.WriteString("^")
+		t.pos++
+	}
+
+	first := t.pos
+
+	for t.pos < len(t.src) {
+		if t.peek() == ']' && t.pos != first {
+			t.pos++
+			b.WriteString("]")
+			t.atom(b.String())
+
+			return nil
+		}
+
+		from, err := t.classAtom()
+		if err != nil {
+			return err
+		}
+
+		// range of characters
+		if t.peek() != '-' || t.pos+1 >= len(t.src) || t.src[t.pos+1] == ']' {
+			b.WriteString(from.value)
+			continue
+		}
+
+		pos := t.pos
+		t.pos++
+
+		to, err := t.classAtom()
+		if err != nil {
+			return err
+		}
+
+		if !from.isRune || !to.isRune || from.r > to.r {
+			return t.errorf(ErrInvalidRange, pos)
+		}
+
+		b.WriteString(from.value + "-" + to.value)
+	}
+
+	return t.errorf(ErrBracketsImbalance, start)
+}
+
+type classAtom struct {
+	value  string
+	r      rune
+	isRune bool
+}
+
+func (t *translator) classAtom() (*classAtom, error) {
+	r := t.src[t.pos]
+
+	if r != '\\' {
+		t.pos++
+		return &classAtom{value: literal(r), r: r, isRune: true}, nil
+	}
+
+	start := t.pos
+
+	if t.pos+1 >= len(t.src) {
+		return nil, t.errorf(ErrTrailingBackslash, start)
+	}
+
+	x := t.src[t.pos+1]
+
+	switch {
+	case strings.ContainsRune("dDwWsS", x):
+		t.pos += 2
+		return &classAtom{value: t.category(x, true)}, nil
+	case x == 'b':
+		t.pos += 2
+		return &classAtom{value: literal('\b'), r: '\b', isRune: true}, nil
+	case isOctal(x):
+		t.pos++
+
+		value := t.readOctal(3)
+		if value > 0o377 {
+			return nil, t.errorf(ErrInvalidEscape, start)
+		}
+
+		return &classAtom{value: literal(value), r: value, isRune: true}, nil
+	}
+
+	value, err := t.characterEscape()
+	if err != nil {
+		return nil, err
+	}
+
+	return &classAtom{value: literal(value), r: value, isRune: true}, nil
+}
+
+func (t *translator) open() error {
+	start := t.pos
+
+	if !t.hasPrefix("(?") {
+		t.pos++
+		t.capture("")
+
+		return nil
+	}
+
+	t.pos += 2
+
+	switch {
+	case t.hasPrefix("P<"):
+		t.pos += 2
+
+		name, err := t.name('>', start)
+		if err != nil {
+			return err
+		}
+
+		if _, exists := t.numbers[name]; exists {
+			return t.errorf(ErrDuplicateGroupName, start)
+		}
+
+		t.capture(name)
+	case t.hasPrefix("P="):
+		t.pos += 2
+
+		name, err := t.name(')', start)
+		if err != nil {
+			return err
+		}
+
+		number, exists := t.numbers[name]
+		if !exists {
+			return t.errorf(ErrUnknownGroupName, start)
+		}
+
+		return t.backReference(start, number)
+	case t.hasPrefix(":"):
+		t.push(new(frame))
+		t.assertion("(?:")
+		t.pos++
+	case t.hasPrefix(">"):
+		t.push(new(frame))
+		t.assertion("(?>")
+		t.pos++
+	case t.hasPrefix("#"):
+		for t.pos < len(t.src) && t.src[t.pos] != ')' {
+			t.pos++
+		}
+
+		if t.pos >= len(t.src) {
+			return t.errorf(ErrParenthesesImbalance, start)
+		}
+
+		t.pos++
+	case t.hasPrefix("="), t.hasPrefix("!"):
+		t.push(new(frame))
+		t.assertion("(?" + string(t.src[t.pos]))
+		t.pos++
+	case t.hasPrefix("<="), t.hasPrefix("<!"):
+		t.push(&frame{lookBehind: true})
+		t.assertion("(?" + string(t.src[t.pos:t.pos+2]))
+		t.pos += 2
+	case t.hasPrefix("("):
+		return t.condition(start)
+	case t.pos < len(t.src) && strings.ContainsRune("aiLmstux-", t.src[t.pos]):
+		return t.flags(start)
+	case t.pos >= len(t.src):
+		return t.errorf(ErrParenthesesImbalance, start)
+	default:
+		return t.errorf(ErrInvalidGroup, start)
+	}
+
+	return nil
+}
+
+// name - name of group which is ended by end character
+func (t *translator) name(end rune, start int) (string, error) {
+	name := t.readWhile(func(x rune) bool { return x != end })
+
+	if t.peek() != end || !isName(name) {
+		return "", t.errorf(ErrInvalidGroupName, start)
+	}
+
+	t.pos++
+
+	return name, nil
+}
+
+// capture - open capturing group, unnamed groups are named by its numbers
+func (t *translator) capture(name string) {
+	t.groups++
+	t.push(&frame{group: t.groups})
+
+	if name == "" {
+		t.assertion(fmt.Sprintf("(?<%d>", t.groups))
+		return
+	}
+
+	t.names[t.groups] = name
+	t.numbers[name] = t.groups
+	t.assertion("(?<" + name + ">")
+}
+
+// condition - (?(number)yes|no) or (?(name)yes|no)
+func (t *translator) condition(start int) error {
+	t.pos++
+	ref := t.readWhile(func(x rune) bool { return x != ')' })
+
+	if t.peek() != ')' {
+		return t.errorf(ErrInvalidGroupName, start)
+	}
+
+	t.pos++
+
+	if isName(ref) {
+		if _, exists := t.numbers[ref]; !exists {
+			return t.errorf(ErrUnknownGroupName, start)
+		}
+
+		t.push(&frame{condition: true})
+		t.assertion("(?(<" + ref + ">)")
+
+		return nil
+	}
+
+	if ref == "" || strings.IndexFunc(ref, func(x rune) bool { return !isDigit(x) }) >= 0 {
+		return t.errorf(ErrInvalidGroupName, start)
+	}
+
+	number, err := strconv.Atoi(ref)
+	if err != nil || number == 0 {
+		return t.errorf(ErrInvalidBackReference, start)
+	}
+
+	t.push(&frame{condition: true})
+	t.refs = append(t.refs, &reference{piece: len(t.pieces), pos: start, number: number})
+	t.assertion("")
+
+	return nil
+}
+
+// flags - global flags (?aimsux) or flags of group (?aimsux-imsx:...),
+// i is passed to internal parser, other flags are handled by translator
+func (t *translator) flags(start int) error {
+	var (
+		enable  = true
+		added   = make(map[rune]bool)
+		removed = make(map[rune]bool)
+		changed = t.modifiers
+	)
+
+	for ; t.pos < len(t.src); t.pos++ {
+		r := t.src[t.pos]
+
+		switch {
+		case r == 'L':
+			// locale flag is allowed only for bytes patterns
+			return t.errorf(ErrInvalidFlags, start)
+		case r == 't':
+			// deprecated template flag
+			return t.errorf(ErrUnsupported, start)
+		case r == '-' && enable:
+			enable = false
+		case r == ')' && enable && len(added) > 0:
+			t.pos++
+			return t.global(start, changed, added)
+		case r == ':' && (enable && len(added) > 0 || !enable && len(removed) > 0):
+			t.pos++
+			return t.scoped(changed, added, removed)
+		case !strings.ContainsRune("aimsux", r):
+			return t.errorf(ErrInvalidFlags, start)
+		case enable:
+			if r == 'a' && added['u'] || r == 'u' && added['a'] {
+				return t.errorf(ErrInvalidFlags, start)
+			}
+
+			added[r] = true
+			changed.set(r, true)
+		default:
+			if r == 'a' || r == 'u' || added[r] {
+				return t.errorf(ErrInvalidFlags, start)
+			}
+
+			removed[r] = true
+			changed.set(r, false)
+		}
+	}
+
+	return t.errorf(ErrParenthesesImbalance, start)
+}
+
+// global - apply global flags, they are allowed only at the start of expression
+func (t *translator) global(start int, changed modifiers, added map[rune]bool) error {
+	if t.started || len(t.frames) > 0 {
+		return t.errorf(ErrInvalidFlags, start)
+	}
+
+	if added['a'] && t.unicode || added['u'] && t.ascii {
+		return t.errorf(ErrInvalidFlags, start)
+	}
+
+	t.modifiers = changed
+
+	return nil
+}
+
+// scoped - open group with flags
+func (t *translator) scoped(changed modifiers, added, removed map[rune]bool) error {
+	var options string
+
+	switch {
+	case added['i']:
+		options = "i"
+	case removed['i']:
+		options = "-i"
+	}
+
+	t.push(new(frame))
+	t.modifiers = changed
+	t.assertion("(?" + options + ":")
+
+	return nil
+}
+
+func (m *modifiers) set(flag rune, value bool) {
+	switch flag {
+	case 'a':
+		m.ascii = value
+		m.unicode = !value
+	case 'i':
+		m.ignoreCase = value
+	case 'm':
+		m.multiline = value
+	case 's':
+		m.dotAll = value
+	case 'u':
+		m.unicode = value
+		m.ascii = !value
+	case 'x':
+		m.verbose = value
+	}
+}
+
+func (t *translator) close() error {
+	if len(t.frames) == 0 {
+		return t.errorf(ErrParenthesesImbalance, t.pos)
+	}
+
+	t.fillEmptyBranch()
+
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	t.modifiers = f.modifiers
+
+	if f.group > 0 {
+		t.closed[f.group] = true
+	}
+
+	if f.lookBehind && !t.hasFixedSize(f.body) {
+		return t.errorf(ErrInvalidLookBehind, t.pos)
+	}
+
+	t.atom(")")
+	t.pos++
+
+	return nil
+}
+
+// bar - alternation, condition has only two branches
+func (t *translator) bar() error {
+	if len(t.frames) > 0 {
+		if f := t.frames[len(t.frames)-1]; f.condition {
+			f.branches++
+
+			if f.branches > 1 {
+				return t.errorf(ErrInvalidCondition, t.pos)
+			}
+		}
+	}
+
+	t.fillEmptyBranch()
+	t.assertion("|")
+	t.branch = len(t.pieces)
+	t.pos++
+
+	return nil
+}
+
+// push - open group, syntax of group is emitted right after it
+func (t *translator) push(f *frame) {
+	f.modifiers = t.modifiers
+	f.body = len(t.pieces) + 1
+	t.frames = append(t.frames, f)
+	t.branch = f.body
+}
+
+// hasFixedSize - check size of translated pieces from index,
+// invalid syntax is reported by internal parser for the whole expression
+func (t *translator) hasFixedSize(from int) bool {
+	alt, err := internal.Parse(parser.Concat(t.pieces[from:]...).Expression)
+	if err != nil {
+		return true
+	}
+
+	_, fixedSize := alt.Size()
+
+	return fixedSize
+}
+
+// fillEmptyBranch - emit always true assertion if nothing is emitted for the current branch
+func (t *translator) fillEmptyBranch() {
+	if len(t.pieces) == t.branch {
+		t.pieces = append(t.pieces, parser.NewTranslation(emptyBranch, t.token))
+	}
+}
+
+// resolve - resolve numbers of groups in conditions
+func (t *translator) resolve() (parser.Translation, error) {
+	for _, ref := range t.refs {
+		if ref.number > t.groups {
+			return parser.Translation{}, t.errorf(ErrInvalidBackReference, ref.pos)
+		}
+
+		name, exists := t.names[ref.number]
+		if !exists {
+			name = strconv.Itoa(ref.number)
+		}
+
+		t.pieces[ref.piece] = parser.NewTranslation("(?(<"+name+">)", ref.pos)
+	}
+
+	return parser.Concat(t.pieces...), nil
+}
+
+// atom - emit syntax which could be quantified
+func (t *translator) atom(s string) {
+	t.pieces = append(t.pieces, parser.NewTranslation(s, t.token))
+	t.previous = repeatable
+	t.started = true
+}
+
+// assertion - emit syntax which couldn't be quantified
+func (t *translator) assertion(s string) {
+	t.pieces = append(t.pieces, parser.NewTranslation(s, t.token))
+	t.previous = nothing
+	t.started = true
+}
+
+func (t *translator) peek() rune {
+	if t.pos < len(t.src) {
+		return t.src[t.pos]
+	}
+
+	return -1
+}
+
+func (t *translator) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(t.src[t.pos:]), prefix)
+}
+
+// readOctal - read up to size octal digits
+func (t *translator) readOctal(size int) rune {
+	value := rune(0)
+
+	for i := 0; i < size && isOctal(t.peek()); i++ {
+		value = value*8 + t.src[t.pos] - '0'
+		t.pos++
+	}
+
+	return value
+}
+
+// readHex - read exactly size hex digits
+func (t *translator) readHex(size int) (rune, bool) {
+	if t.pos+size > len(t.src) {
+		return 0, false
+	}
+
+	digits := string(t.src[t.pos : t.pos+size])
+	if strings.IndexFunc(digits, func(x rune) bool { return !isHex(x) }) >= 0 {
+		return 0, false
+	}
+
+	x, err := strconv.ParseInt(digits, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	t.pos += size
+
+	return rune(x), true
+}
+
+func (t *translator) readWhile(predicate func(rune) bool) string {
+	from := t.pos
+
+	for t.pos < len(t.src) && predicate(t.src[t.pos]) {
+		t.pos++
+	}
+
+	return string(t.src[from:t.pos])
+}
+
+func (t *translator) errorf(err error, pos int) error {
+	return parser.NewParseError(string(t.src), pos, err)
+}
+
+// literal - escaped character in syntax of internal parser
+func literal(r rune) string {
+	return fmt.Sprintf(`\x{%x}`, r)
+}
+
+func isDigit(x rune) bool {
+	return x >= '0' && x <= '9'
+}
+
+func isOctal(x rune) bool {
+	return x >= '0' && x <= '7'
+}
+
+func isHex(x rune) bool {
+	return x >= '0' && x <= '9' ||
+		x >= 'a' && x <= 'f' ||
+		x >= 'A' && x <= 'F'
+}
+
+func isASCIILetter(x rune) bool {
+	return x >= 'a' && x <= 'z' || x >= 'A' && x <= 'Z'
+}
+
+// isName - check that name is identifier (like str.isidentifier)
+func isName(x string) bool {
+	for i, r := range x {
+		start := r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+
+		if i == 0 && !start {
+			return false
+		}
+
+		if !start && !unicode.In(r, unicode.Nd, unicode.Mn, unicode.Mc, unicode.Pc) {
+			return false
+		}
+	}
+
+	return x != ""
+}
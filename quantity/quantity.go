@@ -11,6 +11,9 @@ type Quantity struct {
 	from int
 	to   *int
 	more bool // TODO : rename to endless?
+	lazy bool
+	// possessive - don't backtrack into repetitions like atomic group
+	possessive bool
 }
 
 func New(from int, to int) *Quantity {
@@ -60,12 +63,53 @@ func (n *Quantity) Endless() bool {
 	return n.more
 }
 
+// Lazy - quantity prefer less repetitions (reluctant quantifier like *?)
+func (n *Quantity) Lazy() bool {
+	return n.lazy
+}
+
+// AsLazy - return lazy copy of quantity
+func (n *Quantity) AsLazy() *Quantity {
+	x := *n
+	x.lazy = true
+	return &x
+}
+
+// Possessive - quantity never give up repetitions (possessive quantifier like *+)
+func (n *Quantity) Possessive() bool {
+	return n.possessive
+}
+
+// AsPossessive - return possessive copy of quantity
+func (n *Quantity) AsPossessive() *Quantity {
+	x := *n
+	x.possessive = true
+	return &x
+}
+
+// AsGreedy - return copy of quantity without lazy or possessive modifiers
+func (n *Quantity) AsGreedy() *Quantity {
+	x := *n
+	x.lazy = false
+	x.possessive = false
+	return &x
+}
+
 func (n *Quantity) Optional() bool {
 	return n.from == 0
 }
 
+// Gt - check that quantity allows count of repetitions greater or equal to value
 func (n *Quantity) Gt(value int) bool {
-	return n.to == nil || *n.to >= value
+	if n.more {
+		return true
+	}
+
+	if n.to == nil {
+		return n.from >= value
+	}
+
+	return *n.to >= value
 }
 
 func (n *Quantity) Include(value int) bool {
@@ -85,6 +129,17 @@ func (n *Quantity) Include(value int) bool {
 }
 
 func (n *Quantity) String() string {
+	switch {
+	case n.lazy:
+		return n.greedyString() + "?"
+	case n.possessive:
+		return n.greedyString() + "+"
+	}
+
+	return n.greedyString()
+}
+
+func (n *Quantity) greedyString() string {
 	if n.from == 0 && n.to == nil && n.more {
 		return "*"
 	}
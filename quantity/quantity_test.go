@@ -2,6 +2,7 @@ package quantity
 
 import (
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -189,3 +190,40 @@ func TestQuantity_Include(t *testing.T) {
 		})
 	}
 }
+
+func TestQuantity_String(t *testing.T) {
+	t.Parallel()
+
+	type example struct {
+		q      *Quantity
+		output string
+	}
+
+	tests := []example{
+		{q: New(0, 1), output: "?"},
+		{q: NewEndlessQuantity(0), output: "*"},
+		{q: NewEndlessQuantity(1), output: "+"},
+		{q: NewEndlessQuantity(2), output: "{2,}"},
+		{q: New(2, 3), output: "{2,3}"},
+		{q: New(2, 2), output: "{2}"},
+		{q: New(0, 1).AsLazy(), output: "??"},
+		{q: NewEndlessQuantity(0).AsLazy(), output: "*?"},
+		{q: NewEndlessQuantity(1).AsLazy(), output: "+?"},
+		{q: NewEndlessQuantity(2).AsLazy(), output: "{2,}?"},
+		{q: New(2, 3).AsLazy(), output: "{2,3}?"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.output, func(t *testing.T) {
+			t.Parallel()
+
+			if result := test.q.String(); result != test.output {
+				t.Fatalf("expected %v, actual %v", test.output, result)
+			}
+
+			if test.q.Lazy() != strings.HasSuffix(test.output, "?") && test.output != "?" {
+				t.Fatalf("unexpected laziness of %v", test.output)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package re2
+
+import (
+	"sync"
+
+	"github.com/okneniz/cliche/node"
+)
+
+// dialect - made on first use, because keys of tables are known only after build of tables
+var dialect = sync.OnceValue(func() *node.Dialect {
+	return &node.Dialect{
+		Escapes:    `.?+*^$[]()}|`,
+		NamedGroup: "?P<",
+		Options: map[node.ScanOption]rune{
+			node.ScanOptionCaseInsensetive: 'i',
+			node.ScanOptionMultiline:       's',
+		},
+		Tables: map[string]string{
+			asciiDigit.String():    `\d`,
+			notAsciiDigit.String(): `\D`,
+			asciiWord.String():     `\w`,
+			notAsciiWord.String():  `\W`,
+			asciiSpace.String():    `\s`,
+			notAsciiSpace.String(): `\S`,
+		},
+	}
+})
+
+// Format - write node (like result of Parser.Parse or unified node of tree)
+// in RE2 syntax, parsing of result returns nodes with the same keys,
+// look-arounds, back references and conditions return node.ErrNoFormat
+func Format(n node.Node) (string, error) {
+	return dialect().Format(n)
+}
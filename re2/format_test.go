@@ -0,0 +1,138 @@
+package re2_test
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/onigmo"
+	"github.com/okneniz/cliche/re2"
+	tests "github.com/okneniz/cliche/testing"
+	ohsnap "github.com/okneniz/oh-snap"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		formatted  string
+	}
+
+	tests := []test{
+		{expression: `abc`, formatted: `abc`},
+		{expression: `a|b|cd`, formatted: `a|b|cd`},
+		{expression: `[a-z]+`, formatted: `[a-z]+`},
+		{expression: `[^a-z0-9_]`, formatted: `[^0-9_a-z]`},
+		{expression: `[0-9]\d\D\w\W\s\S`, formatted: `\d\d\D\w\W\s\S`},
+		{expression: `[[:alpha:]][^\d]`, formatted: `[A-Za-z][^\d]`},
+		{expression: `\.\?\+\*\^\$\[\]\(\)\}\|`, formatted: `\.\?\+\*\^\$\[\]\(\)\}\|`},
+		{expression: `[.?*(]`, formatted: `[(*.?]`},
+		{expression: `[\]\[\^]`, formatted: `[\x5B\x5D\x5E]`},
+		{expression: `[-a]`, formatted: `[\x2Da]`},
+		{expression: `\x5C\x7B`, formatted: `\x5C\x7B`},
+		{expression: `[\x00-\x1f]\n\t[\n\t]\x7f`, formatted: `[\x00-\x1F]\n\t[\t\n]\x7F`},
+		{expression: `é😀[é-ü]`, formatted: `é😀[é-ü]`},
+		{expression: `a{2}b{2,}c{2,5}d*?e+?f??`, formatted: `a{2}b{2,}c{2,5}d*?e+?f??`},
+		{expression: `(?:ab)+c`, formatted: `(?:ab)+c`},
+		{expression: `(a)(?<x>b)(?P<y>c)(?:d|e)`, formatted: `(a)(?P<x>b)(?P<y>c)(?:d|e)`},
+		{expression: `(?i)a(?-i)b(?s).`, formatted: `(?i)a(?-i)b(?s).`},
+		{expression: `\A^a$\z\ba\B`, formatted: `\A^a$\z\ba\B`},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := re2.Parser.Parse(test.expression)
+			require.NoError(t, err)
+
+			formatted, err := re2.Format(raw)
+			require.NoError(t, err)
+			require.Equal(t, test.formatted, formatted)
+
+			parsed, err := re2.Parser.Parse(formatted)
+			require.NoError(t, err)
+			require.Equal(t, raw.GetKey(), parsed.GetKey())
+
+			// result is valid for standard library too
+			_, err = regexp.Compile(formatted)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		`a(?=b)`,
+		`a(?!b)`,
+		`(?<=a)b`,
+		`(?<!a)b`,
+		`(a)\1`,
+		`(?<x>a)\k<x>`,
+		`(a)(?(1)b|c)`,
+	}
+
+	for _, tt := range tests {
+		expression := tt
+
+		t.Run(expression, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := onigmo.Parser.Parse(expression)
+			require.NoError(t, err)
+
+			_, err = re2.Format(raw)
+			require.ErrorIs(t, err, node.ErrNoFormat)
+		})
+	}
+}
+
+func TestFormat_Properties(t *testing.T) {
+	t.Parallel()
+
+	const iterations = 1000
+
+	seed := time.Now().UnixNano()
+	t.Logf("seed: %v", seed)
+
+	rnd := rand.New(rand.NewPCG(0, uint64(seed)))
+	arb := tests.ArbitraryRegexp(rnd, 3, 7)
+
+	ohsnap.Check(t, iterations, arb, func(reg tests.TestRegexp) bool {
+		raw, err := re2.Parser.Parse(reg.Expression)
+		if err != nil {
+			t.Error(err)
+			return false
+		}
+
+		formatted, err := re2.Format(raw)
+		if err != nil {
+			t.Error(err)
+			return false
+		}
+
+		parsed, err := re2.Parser.Parse(formatted)
+		if err != nil {
+			t.Log("formatted", formatted)
+			t.Error(err)
+			return false
+		}
+
+		if raw.GetKey() != parsed.GetKey() {
+			t.Log("expression", reg.Expression)
+			t.Log("formatted", formatted)
+			return false
+		}
+
+		return true
+	})
+}
@@ -238,15 +238,21 @@ var (
 		cfg.Quantifier().Items().StringAsValue("?", quantity.New(0, 1))
 		cfg.Quantifier().Items().StringAsValue("+", quantity.NewEndlessQuantity(1))
 		cfg.Quantifier().Items().StringAsValue("*", quantity.NewEndlessQuantity(0))
-		cfg.Quantifier().Items().WithPrefix("{", parseQuantity())
+		cfg.Quantifier().Items().StringAsValue("??", quantity.New(0, 1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("+?", quantity.NewEndlessQuantity(1).AsLazy())
+		cfg.Quantifier().Items().StringAsValue("*?", quantity.NewEndlessQuantity(0).AsLazy())
+		cfg.Quantifier().Items().WithPrefix("{", parser.OptionalSuffix(
+			parseQuantity(),
+			map[rune]func(*quantity.Quantity) *quantity.Quantity{
+				'?': (*quantity.Quantity).AsLazy,
+			},
+		))
 	})
 )
 
 func configureProperty(cfg *parser.Config, props map[string]*unicode.RangeTable) {
 	for name, prop := range props {
-		tbl := unicodeEncoding.NewTableByPredicate(unicode.MaxRune, func(r rune) bool {
-			return unicode.In(r, prop)
-		})
+		tbl := unicodeEncoding.NewTableByRangeTable(prop)
 
 		positive := parser.Const(tbl)
 		negative := parser.Const(tbl.Invert(unicode.MaxRune))
@@ -0,0 +1,237 @@
+package re2
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"unicode"
+
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// anyRune - table of all runes for (?s).
+var anyRune = unicodeEncoding.NewTableByRange(0, unicode.MaxRune)
+
+// FromSyntax - convert regexp/syntax tree to node.Alternation like result of Parser.Parse,
+// leafs contain re.String() as expression (see cliche.Tree.AddParsed).
+//
+// Nodes keep semantic of regexp/syntax, except \b and \B
+// which are matched by unicode word characters (like in Parser).
+func FromSyntax(re *syntax.Regexp) (node.Alternation, error) {
+	variants, err := fromSyntaxVariants(re)
+	if err != nil {
+		return nil, err
+	}
+
+	alt := node.NewAlternation(variants)
+	expression := re.String()
+
+	node.Traverse(alt, func(x node.Node) bool {
+		if len(x.GetNestedNodes()) == 0 {
+			x.AddExpression(node.NewExpression(expression))
+		}
+
+		return false
+	})
+
+	return alt, nil
+}
+
+func fromSyntaxVariants(re *syntax.Regexp) ([]node.Node, error) {
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpAlternate {
+		subs = re.Sub
+	}
+
+	variants := make([]node.Node, 0, len(subs))
+
+	for _, sub := range subs {
+		variant, err := fromSyntax(sub)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+func fromSyntaxAlternation(re *syntax.Regexp) (node.Alternation, error) {
+	variants, err := fromSyntaxVariants(re)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.NewAlternation(variants), nil
+}
+
+// fromSyntax - convert regexp/syntax tree to chain of nodes
+func fromSyntax(re *syntax.Regexp) (node.Node, error) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return node.NewClass(unicodeEncoding.NewTable()), nil
+	case syntax.OpEmptyMatch:
+		return emptyMatch(), nil
+	case syntax.OpLiteral:
+		nodes := make([]node.Node, 0, len(re.Rune))
+
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				nodes = append(nodes, node.NewClass(foldTable(r)))
+			} else {
+				nodes = append(nodes, node.NewClass(unicodeEncoding.NewTable(r)))
+			}
+		}
+
+		return chain(nodes...), nil
+	case syntax.OpCharClass:
+		return classFromSyntax(re.Rune), nil
+	case syntax.OpAnyCharNotNL:
+		return node.NewNegativeClass(unicodeEncoding.NewTable('\n')), nil
+	case syntax.OpAnyChar:
+		return node.NewClass(anyRune), nil
+	case syntax.OpBeginLine:
+		return node.NewStartOfLine(), nil
+	case syntax.OpEndLine:
+		return node.NewEndOfLine(), nil
+	case syntax.OpBeginText:
+		return node.NewStartOfString(), nil
+	case syntax.OpEndText:
+		return node.NewEndOfString(), nil
+	case syntax.OpWordBoundary:
+		return node.NewWordBoundary(), nil
+	case syntax.OpNoWordBoundary:
+		return node.NewNonWordBoundary(), nil
+	case syntax.OpCapture:
+		alt, err := fromSyntaxAlternation(re.Sub[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if re.Name != "" {
+			return node.NewNamedGroup(re.Name, alt), nil
+		}
+
+		return node.NewGroup(alt), nil
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return quantifierFromSyntax(re)
+	case syntax.OpConcat:
+		nodes := make([]node.Node, 0, len(re.Sub))
+
+		for _, sub := range re.Sub {
+			x, err := fromSyntax(sub)
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, x)
+		}
+
+		return chain(nodes...), nil
+	case syntax.OpAlternate:
+		alt, err := fromSyntaxAlternation(re)
+		if err != nil {
+			return nil, err
+		}
+
+		return node.NewNotCapturedGroup(alt), nil
+	}
+
+	return nil, fmt.Errorf("unsupported operation of regexp/syntax: %s", re.Op)
+}
+
+func quantifierFromSyntax(re *syntax.Regexp) (node.Node, error) {
+	var q *quantity.Quantity
+
+	switch {
+	case re.Op == syntax.OpStar:
+		q = quantity.NewEndlessQuantity(0)
+	case re.Op == syntax.OpPlus:
+		q = quantity.NewEndlessQuantity(1)
+	case re.Op == syntax.OpQuest:
+		q = quantity.New(0, 1)
+	case re.Max == -1:
+		q = quantity.NewEndlessQuantity(re.Min)
+	default:
+		q = quantity.New(re.Min, re.Max)
+	}
+
+	if re.Flags&syntax.NonGreedy != 0 {
+		q = q.AsLazy()
+	}
+
+	value, err := fromSyntax(re.Sub[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// quantifier repeats only one node, like (?:ab)* in parser
+	if len(value.GetNestedNodes()) > 0 {
+		value = node.NewNotCapturedGroup(node.NewAlternation([]node.Node{value}))
+	}
+
+	return node.NewQuantifier(q, value), nil
+}
+
+// classFromSyntax - class from sorted pairs of ranges,
+// class which includes the last rune is negative like [^a] in parser
+// and its table is made of gaps between ranges
+func classFromSyntax(ranges []rune) node.Node {
+	tables := make([]node.Table, 0, len(ranges)/2)
+
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		for i := 1; i+1 < len(ranges); i += 2 {
+			tables = append(tables, unicodeEncoding.NewTableByRange(ranges[i]+1, ranges[i+1]-1))
+		}
+
+		return node.NewNegativeClass(unicodeEncoding.Union(tables...))
+	}
+
+	for i := 0; i < len(ranges); i += 2 {
+		tables = append(tables, unicodeEncoding.NewTableByRange(ranges[i], ranges[i+1]))
+	}
+
+	return node.NewClass(unicodeEncoding.Union(tables...))
+}
+
+// foldTable - table of rune and its equivalents by simple case folding
+func foldTable(r rune) node.Table {
+	runes := []rune{r}
+
+	for x := unicode.SimpleFold(r); x != r; x = unicode.SimpleFold(x) {
+		runes = append(runes, x)
+	}
+
+	return unicodeEncoding.NewTable(runes...)
+}
+
+// emptyMatch - nodes can't be empty, so it's empty comment which always matches,
+// it's converted back to empty match by node.ToSyntax
+func emptyMatch() node.Node {
+	return node.NewComment("")
+}
+
+// chain - link nodes one by one like sequence of parser
+func chain(nodes ...node.Node) node.Node {
+	if len(nodes) == 0 {
+		return emptyMatch()
+	}
+
+	first := nodes[0]
+	last := first
+
+	for _, next := range nodes[1:] {
+		for len(last.GetNestedNodes()) == 1 {
+			for _, x := range last.GetNestedNodes() {
+				last = x
+			}
+		}
+
+		last.GetNestedNodes()[next.GetKey()] = next
+		last = next
+	}
+
+	return first
+}
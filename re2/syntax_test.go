@@ -0,0 +1,263 @@
+package re2_test
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okneniz/cliche"
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/onigmo"
+	"github.com/okneniz/cliche/re2"
+	"github.com/okneniz/cliche/scanner"
+)
+
+func TestFromSyntax(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		inputs     []string
+	}
+
+	tests := []test{
+		{expression: `abc`, inputs: []string{"abc", "xabcx", "ab"}},
+		{expression: `a|bc|d`, inputs: []string{"a", "bc", "xd", "b"}},
+		{expression: `[a-c]+x`, inputs: []string{"abcx", "dx", "cax"}},
+		{expression: `[^a-c]y`, inputs: []string{"dy", "ay", "\ny"}},
+		{expression: `\d{2,3}`, inputs: []string{"1", "12", "12345"}},
+		{expression: `(?i)héllo`, inputs: []string{"HÉLLO", "HeLLo", "hÉllo"}},
+		{expression: `x.y`, inputs: []string{"xay", "x\ny"}},
+		{expression: `(?s)x.y`, inputs: []string{"xay", "x\ny"}},
+		{expression: `^ab$`, inputs: []string{"ab", "ab\n", "xab"}},
+		{expression: `(?m)^ab$`, inputs: []string{"x\nab\ny", "xab"}},
+		{expression: `\Aab\z`, inputs: []string{"ab", "ab\n"}},
+		{expression: `\bab\b`, inputs: []string{"ab", "xab", "x ab y"}},
+		{expression: `a\Bb`, inputs: []string{"ab", "a b"}},
+		{expression: `(a)(?P<name>b+)`, inputs: []string{"abbb", "a"}},
+		{expression: `(?:ab)*c`, inputs: []string{"ababc", "c", "abac"}},
+		{expression: `a+?b??c`, inputs: []string{"aac", "abc"}},
+		{expression: `a{2,}`, inputs: []string{"a", "aaaa"}},
+		{expression: `a(|b)c`, inputs: []string{"ac", "abc"}},
+		{expression: `\pL+\PL`, inputs: []string{"héllo1", "ж!", "12"}},
+		{expression: `[^\x00-\x{10FFFE}]`, inputs: []string{"a", "\U0010FFFF"}},
+		{expression: `[\x00\x{10FFFF}]`, inputs: []string{"a", "\x00", "\U0010FFFF"}},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := syntax.Parse(test.expression, syntax.Perl)
+			require.NoError(t, err)
+
+			raw, err := re2.FromSyntax(re)
+			require.NoError(t, err)
+
+			tr := cliche.New(re2.Parser)
+			require.NoError(t, tr.AddParsed(re.String(), raw))
+
+			expected := regexp.MustCompile(test.expression)
+
+			for _, input := range test.inputs {
+				spans := matchedSpans(tr.Match(input))
+
+				loc := expected.FindStringIndex(input)
+				if loc == nil {
+					require.Empty(t, spans, input)
+					continue
+				}
+
+				from := len([]rune(input[:loc[0]]))
+				to := from + len([]rune(input[loc[0]:loc[1]]))
+
+				require.Contains(t, spans, [2]int{from, to}, input)
+			}
+		})
+	}
+}
+
+func TestFromSyntax_Keys(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		`abc`,
+		`[^a]`,
+		`a*?`,
+		`(a)(?P<x>b)`,
+		`(?:ab)+`,
+		`\Aa\z`,
+	}
+
+	for _, tt := range tests {
+		expression := tt
+
+		t.Run(expression, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := syntax.Parse(expression, syntax.Perl)
+			require.NoError(t, err)
+
+			actual, err := re2.FromSyntax(re)
+			require.NoError(t, err)
+
+			expected, err := re2.Parser.Parse(expression)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.GetKey(), actual.GetKey())
+		})
+	}
+}
+
+func TestToSyntax(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		inputs     []string
+	}
+
+	tests := []test{
+		{expression: `abc`, inputs: []string{"abc", "xabcx", "ab"}},
+		{expression: `a|bc|d`, inputs: []string{"a", "bc", "xd", "b"}},
+		{expression: `[a-c]+x`, inputs: []string{"abcx", "dx", "cax"}},
+		{expression: `[^a-c]y`, inputs: []string{"dy", "ay", "\ny"}},
+		{expression: `\d{2,3}\s\w`, inputs: []string{"12 a", "1 a", "12345\tb"}},
+		{expression: `x.y`, inputs: []string{"xay", "x\ny"}},
+		{expression: `^ab$`, inputs: []string{"ab", "xab"}},
+		{expression: `\Aab\z`, inputs: []string{"ab", "ab\n"}},
+		{expression: `\bab\b`, inputs: []string{"ab", "xab", "x ab y"}},
+		{expression: `(a)(?P<name>b+)`, inputs: []string{"abbb", "a"}},
+		{expression: `(?:ab)*c`, inputs: []string{"ababc", "c", "abac"}},
+		{expression: `a+?b??c`, inputs: []string{"aac", "abc"}},
+		{expression: `a{2,}b{1,3}`, inputs: []string{"ab", "aabbbb"}},
+		{expression: `[[:alpha:]]+`, inputs: []string{"abc1", "1"}},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := re2.Parser.Parse(test.expression)
+			require.NoError(t, err)
+
+			re, err := node.ToSyntax(raw)
+			require.NoError(t, err)
+
+			actual, err := regexp.Compile(re.String())
+			require.NoError(t, err)
+
+			expected := regexp.MustCompile(test.expression)
+
+			require.Equal(t, expected.NumSubexp(), actual.NumSubexp())
+			require.Equal(t, expected.SubexpNames(), actual.SubexpNames())
+
+			for _, input := range test.inputs {
+				require.Equal(t,
+					expected.FindAllStringSubmatchIndex(input, -1),
+					actual.FindAllStringSubmatchIndex(input, -1),
+					input,
+				)
+			}
+		})
+	}
+}
+
+func TestSyntax_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		inputs     []string
+	}
+
+	tests := []test{
+		{expression: `(?:)`, inputs: []string{"", "ab"}},
+		{expression: `a|`, inputs: []string{"", "a", "ba"}},
+		{expression: `x{0}`, inputs: []string{"", "xx"}},
+		{expression: `a(?:)b`, inputs: []string{"ab", "a b"}},
+		{expression: `(|a)b`, inputs: []string{"b", "ab"}},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := syntax.Parse(test.expression, syntax.Perl)
+			require.NoError(t, err)
+
+			raw, err := re2.FromSyntax(re)
+			require.NoError(t, err)
+
+			back, err := node.ToSyntax(raw)
+			require.NoError(t, err)
+
+			actual, err := regexp.Compile(back.String())
+			require.NoError(t, err)
+
+			expected := regexp.MustCompile(test.expression)
+
+			require.Equal(t, expected.NumSubexp(), actual.NumSubexp())
+
+			for _, input := range test.inputs {
+				require.Equal(t,
+					expected.FindAllStringSubmatchIndex(input, -1),
+					actual.FindAllStringSubmatchIndex(input, -1),
+					input,
+				)
+			}
+		})
+	}
+}
+
+func TestToSyntax_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		`a\Kb`,
+		`a(?=b)`,
+		`a(?!b)`,
+		`(?<=a)b`,
+		`(?<!a)b`,
+		`(a)\1`,
+		`(?<x>a)\k<x>`,
+		`(?>ab)`,
+	}
+
+	for _, tt := range tests {
+		expression := tt
+
+		t.Run(expression, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := onigmo.Parser.Parse(expression)
+			require.NoError(t, err)
+
+			_, err = node.ToSyntax(raw)
+			require.ErrorIs(t, err, node.ErrNoSyntax)
+		})
+	}
+}
+
+// matchedSpans - spans of matches as [from, to) of runes
+func matchedSpans(matches []*scanner.Match) [][2]int {
+	spans := make([][2]int, 0, len(matches))
+
+	for _, m := range matches {
+		if m.Span().Empty() {
+			spans = append(spans, [2]int{m.Span().From(), m.Span().From()})
+		} else {
+			spans = append(spans, [2]int{m.Span().From(), m.Span().To() + 1})
+		}
+	}
+
+	return spans
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/okneniz/cliche/node"
 	"github.com/okneniz/cliche/quantity"
 	"github.com/okneniz/cliche/structs"
 )
@@ -12,9 +13,73 @@ import (
 type Match struct {
 	subString   string
 	span        quantity.Interface // todo : rename to bounds
-	expressions structs.Set[string]
+	expressions structs.Set[node.Expression]
 	groups      []quantity.Interface
 	namedGroups map[string]quantity.Interface
+
+	location             *Location
+	groupsLocations      []Location
+	namedGroupsLocations map[string]Location
+}
+
+// Location - location of span in source text, end is exclusive
+type Location struct {
+	Start node.Position
+	End   node.Position
+}
+
+// NewMatch - return match of node, it's used by outputs
+func NewMatch(
+	n node.Node,
+	subString string,
+	sp quantity.Interface,
+	groups []quantity.Interface,
+	namedGroups map[string]quantity.Interface,
+	locator node.Locator,
+) *Match {
+	m := &Match{
+		subString: subString,
+		span:      sp,
+		// TODO : how to avoid copies / allocations?
+		// maybe just save pointer to current expression
+		// and swap nodes expressions by new collection only
+		// when new expressions add (or any other mutations)?
+		expressions: n.GetExpressions().Clone(),
+		groups:      groups,
+		namedGroups: namedGroups,
+	}
+
+	if locator == nil {
+		return m
+	}
+
+	location := locate(locator, sp)
+	m.location = &location
+
+	m.groupsLocations = make([]Location, len(groups))
+	for i, g := range groups {
+		m.groupsLocations[i] = locate(locator, g)
+	}
+
+	m.namedGroupsLocations = make(map[string]Location, len(namedGroups))
+	for name, g := range namedGroups {
+		m.namedGroupsLocations[name] = locate(locator, g)
+	}
+
+	return m
+}
+
+func locate(locator node.Locator, sp quantity.Interface) Location {
+	start := locator.Locate(sp.From())
+
+	if sp.Empty() {
+		return Location{Start: start, End: start}
+	}
+
+	return Location{
+		Start: start,
+		End:   locator.Locate(sp.To() + 1),
+	}
 }
 
 func (m *Match) SubString() string {
@@ -34,8 +99,28 @@ func (m *Match) Key() string {
 	return s
 }
 
+// Expressions - return sources of matched expressions
 func (m *Match) Expressions() []string {
-	return m.expressions.Slice()
+	sources := structs.NewMapSet[string]()
+
+	for _, x := range m.expressions.Slice() {
+		sources.Add(x.Source)
+	}
+
+	return sources.Slice()
+}
+
+// Payloads - return payloads attached to matched expressions
+func (m *Match) Payloads() []any {
+	payloads := make([]any, 0, m.expressions.Size())
+
+	for _, x := range m.expressions.Slice() {
+		if x.Payload != nil {
+			payloads = append(payloads, x.Payload)
+		}
+	}
+
+	return payloads
 }
 
 func (m *Match) NamedGroups() map[string]quantity.Interface {
@@ -46,6 +131,26 @@ func (m *Match) Groups() []quantity.Interface {
 	return m.groups
 }
 
+// Location - return location of match in source text,
+// only if match is scanned with node.ScanOptionLocations
+func (m *Match) Location() (Location, bool) {
+	if m.location == nil {
+		return Location{}, false
+	}
+
+	return *m.location, true
+}
+
+// GroupsLocations - return locations of groups in the same order as Groups
+func (m *Match) GroupsLocations() []Location {
+	return m.groupsLocations
+}
+
+// NamedGroupsLocations - return locations of named groups
+func (m *Match) NamedGroupsLocations() map[string]Location {
+	return m.namedGroupsLocations
+}
+
 func (m *Match) Clone() *Match {
 	return &Match{
 		subString:   m.subString,
@@ -53,6 +158,10 @@ func (m *Match) Clone() *Match {
 		expressions: m.expressions.Clone(),
 		groups:      m.groups,      // clone it too?
 		namedGroups: m.namedGroups, // clone it too?
+
+		location:             m.location,
+		groupsLocations:      m.groupsLocations,
+		namedGroupsLocations: m.namedGroupsLocations,
 	}
 }
 
@@ -61,7 +170,7 @@ func (m *Match) String() string {
 		"Match{%s, '%s', /%s/ [%s] {%s}}",
 		m.span.String(),
 		m.subString,
-		strings.Join(m.expressions.Slice(), ", "),
+		strings.Join(m.Expressions(), ", "),
 		m.groupsToString(),
 		m.namedGroupsToString(),
 	)
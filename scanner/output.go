@@ -41,18 +41,9 @@ func (out *Output) Yield(
 	sp quantity.Interface,
 	groups []quantity.Interface,
 	namedGroups map[string]quantity.Interface,
+	locator node.Locator,
 ) {
-	m := &Match{
-		subString: subString,
-		span:      sp,
-		// TODO : how to avoid copies / allocations?
-		// maybe just save pointer to current expression
-		// and swap nodes expressions by new collection only
-		// when new expressions add (or any other mutations)?
-		expressions: n.GetExpressions().Clone(),
-		groups:      groups,
-		namedGroups: namedGroups,
-	}
+	m := NewMatch(n, subString, sp, groups, namedGroups, locator)
 
 	list, exists := out.matches[n]
 	if !exists {
@@ -77,6 +68,12 @@ func (out *Output) LastPosOf(n node.Node) (int, bool) {
 	return match.Span().To(), true
 }
 
+func (out *Output) Flush() {}
+
+func (out *Output) Done() bool {
+	return false
+}
+
 func (out *Output) Slice() []*Match {
 	size := 0
 	for _, v := range out.matches {
@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/okneniz/cliche/buf"
@@ -10,6 +12,12 @@ import (
 )
 
 type (
+	// subStringer - input which can slice substring from source text
+	// instead of encoding read runes
+	subStringer interface {
+		SubString(from, to int) string
+	}
+
 	FullScanner struct {
 		input       node.Input
 		output      node.Output
@@ -17,8 +25,33 @@ type (
 		groups      Captures
 		namedGroups NamedCaptures
 		holes       *structs.TruncatedList[quantity.Interface]
+		lazy        *structs.TruncatedList[int]
+		lazyMatches []lazyMatch
 		roots       map[string]node.Node
 		options     ScanOptionsHistory
+		locator     node.Locator
+		window      node.Window // only for ScanWindow
+
+		ctx      context.Context
+		maxSteps int
+		steps    int
+		err      error
+	}
+
+	// lazyMatch - match of path with lazy quantifiers, it's yielded after visit
+	// of root node, when the preferred sizes of lazy quantifiers are known
+	lazyMatch struct {
+		node        node.Node
+		subString   string
+		span        quantity.Interface
+		groups      []quantity.Interface
+		namedGroups map[string]quantity.Interface
+		lazy        []int
+	}
+
+	// interruption - panic value used to unwind recursive visits of nodes
+	interruption struct {
+		scanner *FullScanner
 	}
 
 	Captures interface {
@@ -52,9 +85,26 @@ type (
 	}
 )
 
+var (
+	// ErrStepsLimit - scanning made more steps than allowed
+	ErrStepsLimit = errors.New("steps limit exceeded")
+
+	// ErrWindowOverflow - match could be longer than window of input
+	// or look-behind could need runes before the beginning of window
+	ErrWindowOverflow = errors.New("match needs runes out of window of input")
+)
+
+// contextCheckInterval - count of steps between checks of context
+const contextCheckInterval = 1 << 10
+
 var (
 	_ node.Scanner       = new(FullScanner)
 	_ node.Input         = buf.NewRunesBuffer("")
+	_ node.Window        = buf.NewReaderBuffer(nil, 0, 0)
+	_ node.Input         = buf.NewBytesBuffer(nil, false)
+	_ node.Locator       = buf.NewRunesBuffer("")
+	_ node.Locator       = buf.NewBytesBuffer(nil, false)
+	_ node.Locator       = buf.NewReaderBuffer(nil, 0, 0)
 	_ node.Output        = NewOutput()
 	_ Captures           = structs.NewTruncatedList[quantity.Interface](0)
 	_ NamedCaptures      = structs.NewOrderedMap[string, quantity.Interface](0)
@@ -82,6 +132,9 @@ func NewFullScanner(
 	// TODO : capacity = max count of assertions / lookaheads / lookbehins in expression
 	s.holes = structs.NewTruncatedList[quantity.Interface](3)
 
+	// TODO : capacity = max count of lazy quantifiers in expression
+	s.lazy = structs.NewTruncatedList[int](3)
+
 	// TODO : what about default options?
 
 	s.options = structs.NewOrderedMap[node.ScanOption, bool](10)
@@ -89,9 +142,76 @@ func NewFullScanner(
 		s.options.Put(x, true)
 	}
 
+	if s.OptionsInclude(node.ScanOptionLocations) {
+		s.locator, _ = input.(node.Locator)
+	}
+
 	return s
 }
 
+// Limit - interrupt scanning when context is done
+// or after maxSteps visits and matches of nodes (if maxSteps > 0)
+func (s *FullScanner) Limit(ctx context.Context, maxSteps int) {
+	s.ctx = ctx
+	s.maxSteps = maxSteps
+}
+
+// Err - return ErrStepsLimit, ErrWindowOverflow or error of context if scanning was interrupted
+func (s *FullScanner) Err() error {
+	return s.err
+}
+
+// Steps - return count of steps made by scanner
+func (s *FullScanner) Steps() int {
+	return s.steps
+}
+
+func (s *FullScanner) step() {
+	s.steps++
+
+	if s.maxSteps > 0 && s.steps > s.maxSteps {
+		s.interrupt(ErrStepsLimit)
+	}
+
+	if s.steps%contextCheckInterval == 0 {
+		s.checkContext()
+	}
+}
+
+// checkContext - interrupt scanning if context is done
+func (s *FullScanner) checkContext() {
+	if s.ctx == nil {
+		return
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		s.interrupt(err)
+	}
+}
+
+// interrupt - stop scanning, err is nil when output is done
+func (s *FullScanner) interrupt(err error) {
+	s.err = err
+	panic(interruption{scanner: s})
+}
+
+// recoverInterruption - stop unwinding of interrupted scanning, must be deferred
+func (s *FullScanner) recoverInterruption() {
+	if x := recover(); x != nil {
+		if i, ok := x.(interruption); !ok || i.scanner != s {
+			panic(x)
+		}
+
+		s.Rewind(0)
+		s.RewindLazy(0)
+
+		// visit is unfinished, so preferred lazy matches are unknown
+		s.lazyMatches = s.lazyMatches[:0]
+
+		s.output.Flush()
+	}
+}
+
 func (s *FullScanner) String() string {
 	return fmt.Sprintf(
 		"Scanner(\n\toutput=%s,\n\texpression=%v,\n\tgroups=%v,\n\tholes=%v\n)",
@@ -137,10 +257,21 @@ func (s *FullScanner) Rewind(pos int) {
 }
 
 func (s *FullScanner) Scan(from, to int) {
+	defer s.recoverInterruption()
+
+	// context can be done before the first check in steps
+	s.checkContext()
+
 	for _, root := range s.roots {
 		nextFrom := from
 
 		for nextFrom <= to {
+			if s.output.Done() {
+				return
+			}
+
+			s.step()
+
 			lastFrom := nextFrom
 			root.Visit(s, s.input, nextFrom, to, func(n node.Node, from, to int, empty bool) {
 				// fmt.Println("before", s)
@@ -150,6 +281,8 @@ func (s *FullScanner) Scan(from, to int) {
 				// fmt.Println("")
 			})
 
+			s.yieldLazy()
+
 			if pos, ok := s.output.LastPosOf(root); ok && pos >= nextFrom {
 				nextFrom = pos
 			}
@@ -160,10 +293,72 @@ func (s *FullScanner) Scan(from, to int) {
 
 			s.Rewind(0)
 		}
+
+		s.output.Flush()
+	}
+}
+
+// ScanWindow - scan input position by position and slide window of input,
+// if input isn't Window whole input is scanned
+func (s *FullScanner) ScanWindow() {
+	window, ok := s.input.(node.Window)
+	if !ok {
+		s.Scan(0, s.input.Size())
+		return
+	}
+
+	defer s.recoverInterruption()
+
+	s.checkContext()
+
+	s.window = window
+	nextFrom := make(map[node.Node]int, len(s.roots))
+
+	for pos := 0; window.Slide(pos); pos++ {
+		for _, root := range s.roots {
+			if s.output.Done() {
+				return
+			}
+
+			if next, exists := nextFrom[root]; exists && next > pos {
+				continue
+			}
+
+			s.step()
+
+			root.Visit(s, s.input, pos, window.Size(), s.Match)
+			s.checkWindow()
+			s.yieldLazy()
+
+			next := pos
+			if last, ok := s.output.LastPosOf(root); ok && last >= pos {
+				next = last
+			}
+
+			if next == pos {
+				next++
+			}
+
+			nextFrom[root] = next
+
+			s.Rewind(0)
+		}
+	}
+
+	s.output.Flush()
+}
+
+// checkWindow - interrupt scanning if visit reached the bounds of window,
+// because result of it can be different for whole input
+func (s *FullScanner) checkWindow() {
+	if s.window != nil && s.window.Overflow() {
+		s.interrupt(ErrWindowOverflow)
 	}
 }
 
 func (s *FullScanner) Match(n node.Node, from, to int, empty bool) {
+	s.step()
+
 	x := nodeMatch{node: n}
 
 	if empty {
@@ -183,16 +378,72 @@ func (s *FullScanner) Match(n node.Node, from, to int, empty bool) {
 		return
 	}
 
+	s.checkWindow()
+
 	sp = quantity.Get(sp, s.holes)
 	subString := s.getSubString(sp)
 
+	if s.lazy.Size() > 0 {
+		s.pushLazy(lazyMatch{
+			node:        n,
+			subString:   subString,
+			span:        sp,
+			groups:      s.groups.Slice(),
+			namedGroups: s.namedGroups.Map(),
+			lazy:        s.lazy.Slice(),
+		})
+
+		return
+	}
+
 	s.output.Yield(
 		n,
 		subString,
 		sp,
 		s.groups.Slice(),
 		s.namedGroups.Map(),
+		s.locator,
 	)
+
+	// stop current visit too, it can be very long
+	if s.output.Done() {
+		s.interrupt(nil)
+	}
+}
+
+// pushLazy - keep only matches of node with the preferred sizes of lazy quantifiers
+func (s *FullScanner) pushLazy(m lazyMatch) {
+	for _, x := range s.lazyMatches {
+		if x.node == m.node && node.CompareLazy(x.lazy, m.lazy) > 0 {
+			return
+		}
+	}
+
+	kept := s.lazyMatches[:0]
+
+	for _, x := range s.lazyMatches {
+		if x.node != m.node || node.CompareLazy(m.lazy, x.lazy) <= 0 {
+			kept = append(kept, x)
+		}
+	}
+
+	s.lazyMatches = append(kept, m)
+}
+
+// yieldLazy - yield matches with lazy quantifiers after visit of root node
+func (s *FullScanner) yieldLazy() {
+	for _, m := range s.lazyMatches {
+		s.output.Yield(
+			m.node,
+			m.subString,
+			m.span,
+			m.groups,
+			m.namedGroups,
+			s.locator,
+		)
+	}
+
+	s.lazyMatches = s.lazyMatches[:0]
 }
 
 func (s *FullScanner) getSubString(sp quantity.Interface) string {
@@ -205,6 +456,10 @@ func (s *FullScanner) getSubString(sp quantity.Interface) string {
 		return ""
 	}
 
+	if x, ok := s.input.(subStringer); ok {
+		return x.SubString(sp.From(), sp.To())
+	}
+
 	size := sp.Size()
 	subString := make([]rune, 0, size)
 
@@ -279,6 +534,11 @@ func (s *FullScanner) RewindGroups(pos int) {
 }
 
 func (s *FullScanner) MatchNamedGroup(name string, from int, to int) {
+	if to < from {
+		s.namedGroups.Put(name, quantity.Empty(from))
+		return
+	}
+
 	g := quantity.Get(quantity.Pair(from, to), s.holes)
 	s.namedGroups.Put(name, g)
 }
@@ -306,3 +566,19 @@ func (s *FullScanner) HolesPosition() int {
 func (s *FullScanner) RewindHoles(pos int) {
 	s.holes.Truncate(pos)
 }
+
+func (s *FullScanner) MatchLazy(size int) {
+	s.lazy.Append(size)
+}
+
+func (s *FullScanner) LazyPosition() int {
+	return s.lazy.Size()
+}
+
+func (s *FullScanner) GetLazy(pos int) (int, bool) {
+	return s.lazy.At(pos)
+}
+
+func (s *FullScanner) RewindLazy(pos int) {
+	s.lazy.Truncate(pos)
+}
@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/okneniz/cliche/node"
+	"github.com/okneniz/cliche/quantity"
+)
+
+// StreamOutput - output which pass matches to callback as soon as possible
+// without collecting all matches of text.
+//
+// Match is passed when scanner can't find longer match of the same node
+// (next match don't overlap it or scanning of root node finished).
+// Unlike Output, matches of different nodes with the same bounds aren't merged.
+type StreamOutput struct {
+	matches map[node.Node]*matchesList
+	yield   func(*Match) bool
+	done    bool
+}
+
+var _ node.Output = NewStreamOutput(nil)
+
+// NewStreamOutput - return output which pass matches to f,
+// scanning is stopped when f return false
+func NewStreamOutput(f func(*Match) bool) *StreamOutput {
+	out := new(StreamOutput)
+	out.matches = make(map[node.Node]*matchesList)
+	out.yield = f
+	return out
+}
+
+func (out *StreamOutput) String() string {
+	return fmt.Sprintf(
+		"StreamOutput(pending=%d, done=%v)",
+		len(out.matches),
+		out.done,
+	)
+}
+
+func (out *StreamOutput) Yield(
+	n node.Node,
+	subString string,
+	sp quantity.Interface,
+	groups []quantity.Interface,
+	namedGroups map[string]quantity.Interface,
+	locator node.Locator,
+) {
+	if out.done {
+		return
+	}
+
+	m := NewMatch(n, subString, sp, groups, namedGroups, locator)
+
+	list, exists := out.matches[n]
+	if !exists {
+		list = newMatchesList()
+		out.matches[n] = list
+	}
+
+	list.Push(m)
+
+	if list.Size() < 2 {
+		return
+	}
+
+	// previous match can't be replaced by longer one anymore
+	ms := list.Slice()
+	last := ms[len(ms)-1]
+
+	for _, x := range ms[:len(ms)-1] {
+		out.emit(x)
+	}
+
+	list = newMatchesList()
+	list.Push(last)
+	out.matches[n] = list
+}
+
+func (out *StreamOutput) LastPosOf(n node.Node) (int, bool) {
+	m, exists := out.matches[n]
+	if !exists {
+		return -1, false
+	}
+
+	match, exists := m.Maximum()
+	if !exists {
+		return -1, false
+	}
+
+	return match.Span().To(), true
+}
+
+// Flush - pass all pending matches to callback
+func (out *StreamOutput) Flush() {
+	pending := make([]*Match, 0, len(out.matches))
+
+	for _, list := range out.matches {
+		pending = append(pending, list.Slice()...)
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].span.From() == pending[j].span.From() {
+			return pending[i].span.To() < pending[j].span.To()
+		}
+
+		return pending[i].span.From() < pending[j].span.From()
+	})
+
+	for _, x := range pending {
+		out.emit(x)
+	}
+
+	out.matches = make(map[node.Node]*matchesList)
+}
+
+func (out *StreamOutput) Done() bool {
+	return out.done
+}
+
+func (out *StreamOutput) emit(m *Match) {
+	if out.done {
+		return
+	}
+
+	if !out.yield(m) {
+		out.done = true
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 type Set[T comparable] interface {
 	Add(item ...T)
+	Delete(item ...T)
 	AddTo(other Set[T])
 	Size() int
 	Slice() []T
@@ -36,6 +37,12 @@ func (s *mapSet[T]) Add(items ...T) {
 	}
 }
 
+func (s *mapSet[T]) Delete(items ...T) {
+	for _, item := range items {
+		delete(s.items, item)
+	}
+}
+
 func (s *mapSet[T]) AddTo(other Set[T]) {
 	for item := range s.items {
 		other.Add(item)
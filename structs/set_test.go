@@ -193,3 +193,44 @@ func TestSet_Clone(t *testing.T) {
 		require.Equal(t, len(set.Slice()), len(set.Clone().Slice()))
 	}
 }
+
+func TestSet_Delete(t *testing.T) {
+	t.Parallel()
+
+	type example struct {
+		init   []string
+		delete []string
+		output []string
+	}
+
+	examples := []example{
+		{
+			init:   []string{"1", "2", "3"},
+			delete: []string{"2"},
+			output: []string{"1", "3"},
+		},
+		{
+			init:   []string{},
+			delete: []string{"4"},
+			output: []string{},
+		},
+		{
+			init:   []string{"1", "2", "3"},
+			delete: []string{},
+			output: []string{"1", "2", "3"},
+		},
+		{
+			init:   []string{"1", "2", "3"},
+			delete: []string{"1", "3", "4"},
+			output: []string{"2"},
+		},
+	}
+
+	for _, example := range examples {
+		set := NewMapSet[string](example.init...)
+		set.Delete(example.delete...)
+
+		require.Subset(t, example.output, set.Slice())
+		require.Equal(t, len(example.output), len(set.Slice()))
+	}
+}
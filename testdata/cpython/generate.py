@@ -0,0 +1,86 @@
+#!/usr/bin/env python3
+"""Generate re_tests.json from CPython test suite (Lib/test/re_tests.py).
+
+Each pattern of the suite is compiled by CPython and searched in the test string,
+so expectations are results of re.search, not expected values of the suite:
+
+    python3 testdata/cpython/generate.py > testdata/cpython/re_tests.json
+
+Python 3.11 or newer is required (atomic groups and possessive quantifiers).
+"""
+
+import ast
+import inspect
+import json
+import re
+import sys
+import warnings
+
+from test import re_tests
+
+
+def lines():
+    """Line numbers of test cases in re_tests.py."""
+    tree = ast.parse(inspect.getsource(re_tests))
+    result = []
+
+    for stmt in tree.body:
+        # tests = [...]
+        if isinstance(stmt, ast.Assign) and getattr(stmt.targets[0], "id", None) == "tests":
+            result.extend(x.lineno for x in stmt.value.elts)
+
+        # tests.extend([...])
+        if isinstance(stmt, ast.Expr) and isinstance(stmt.value, ast.Call):
+            call = stmt.value
+            if ast.unparse(call.func) == "tests.extend":
+                result.extend(x.lineno for x in call.args[0].elts)
+
+    if len(result) != len(re_tests.tests):
+        raise ValueError("tests are not found")
+
+    return result
+
+
+def case(line, pattern, string):
+    result = {"Line": line, "Pattern": pattern, "Input": string}
+
+    try:
+        with warnings.catch_warnings():
+            warnings.simplefilter("ignore")
+            expression = re.compile(pattern)
+    except re.error:
+        result["Error"] = True
+        return result
+
+    match = expression.search(string)
+    if match is None:
+        result["NoMatch"] = True
+        return result
+
+    result["Groups"] = [
+        None if match.start(i) < 0 else [match.start(i), match.end(i)]
+        for i in range(expression.groups + 1)
+    ]
+
+    if expression.groupindex:
+        result["Names"] = dict(expression.groupindex)
+
+    return result
+
+
+def main():
+    if sys.version_info < (3, 11):
+        sys.exit("python 3.11 or newer is required")
+
+    cases = [
+        case(line, test[0], test[1])
+        for line, test in zip(lines(), re_tests.tests)
+        if isinstance(test[0], str) and isinstance(test[1], str)
+    ]
+
+    json.dump(cases, sys.stdout, indent=2, ensure_ascii=False)
+    print()
+
+
+if __name__ == "__main__":
+    main()
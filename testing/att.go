@@ -0,0 +1,267 @@
+package testing
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type (
+	// ATTTest - test case of AT&T POSIX test files (testregex format)
+	ATTTest struct {
+		Line    int
+		Flags   string
+		Pattern string
+		Input   string
+		NoMatch bool
+		Error   string    // name of regcomp error without REG_ prefix, like BADBR
+		Want    []ATTSpan // match and subexpressions, only listed ones are expected
+	}
+
+	// ATTSpan - span of match with exclusive end, unset is (?,?)
+	ATTSpan struct {
+		From  int
+		To    int
+		Unset bool
+	}
+)
+
+func (s ATTSpan) String() string {
+	if s.Unset {
+		return "(?,?)"
+	}
+
+	return fmt.Sprintf("(%d,%d)", s.From, s.To)
+}
+
+// HasFlag - check flag of test, like B for basic or E for extended expressions
+func (x *ATTTest) HasFlag(flag rune) bool {
+	return strings.ContainsRune(x.Flags, flag)
+}
+
+func (x *ATTTest) String() string {
+	return fmt.Sprintf("%d:%s:%q:%q", x.Line, x.Flags, x.Pattern, x.Input)
+}
+
+// LoadATTFile - load tests from AT&T POSIX test file,
+// test cases edited to RE2/Go behavior are replaced by commented originals
+func LoadATTFile(t testing.TB, path string) ([]*ATTTest, error) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		tests    []*ATTTest
+		previous string // previous pattern for SAME
+		comment  string // commented line before edited one
+	)
+
+	lines := bufio.NewScanner(file)
+	number := 0
+
+	for lines.Scan() {
+		number++
+		line := lines.Text()
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			comment = strings.TrimPrefix(line, "#")
+			continue
+		case strings.HasSuffix(line, "RE2/Go"):
+			line = comment
+		}
+
+		comment = ""
+
+		fields := splitATTLine(line)
+		if len(fields) < 3 || fields[0] == "NOTE" {
+			continue
+		}
+
+		test, err := parseATTFields(fields, previous)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+
+		if test == nil {
+			continue
+		}
+
+		test.Line = number
+		previous = test.Pattern
+		tests = append(tests, test)
+	}
+
+	return tests, lines.Err()
+}
+
+// splitATTLine - split line by tabs, test strings can contain spaces
+func splitATTLine(line string) []string {
+	fields := make([]string, 0, 4)
+
+	for _, x := range strings.Split(line, "\t") {
+		if x != "" {
+			fields = append(fields, x)
+		}
+	}
+
+	return fields
+}
+
+func parseATTFields(fields []string, previous string) (*ATTTest, error) {
+	flags := strings.TrimPrefix(fields[0], "{")
+
+	// label like :HA#100:
+	if strings.HasPrefix(flags, ":") {
+		if end := strings.LastIndex(flags, ":"); end > 0 {
+			flags = flags[end+1:]
+		}
+	}
+
+	if !strings.ContainsAny(flags, "BE") {
+		return nil, nil
+	}
+
+	test := &ATTTest{
+		Flags:   flags,
+		Pattern: fields[1],
+		Input:   fields[2],
+	}
+
+	if test.Pattern == "SAME" {
+		test.Pattern = previous
+	}
+
+	if test.Input == "NULL" {
+		test.Input = ""
+	}
+
+	if strings.Contains(flags, "$") {
+		var err error
+
+		test.Pattern, err = unescapeATT(test.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		test.Input, err = unescapeATT(test.Input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(fields) < 4 {
+		return nil, nil
+	}
+
+	expected := fields[3]
+
+	switch {
+	case expected == "NOMATCH":
+		test.NoMatch = true
+	case !strings.HasPrefix(expected, "("):
+		test.Error = expected
+	default:
+		spans, err := parseATTSpans(expected)
+		if err != nil {
+			return nil, err
+		}
+
+		test.Want = spans
+	}
+
+	return test, nil
+}
+
+func parseATTSpans(s string) ([]ATTSpan, error) {
+	spans := make([]ATTSpan, 0)
+
+	for len(s) > 0 {
+		end := strings.Index(s, ")")
+		if !strings.HasPrefix(s, "(") || end < 0 {
+			return nil, fmt.Errorf("invalid spans: %q", s)
+		}
+
+		bounds := strings.Split(s[1:end], ",")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid span: %q", s[:end+1])
+		}
+
+		s = s[end+1:]
+
+		if bounds[0] == "?" && bounds[1] == "?" {
+			spans = append(spans, ATTSpan{Unset: true})
+			continue
+		}
+
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := strconv.Atoi(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+
+		spans = append(spans, ATTSpan{From: from, To: to})
+	}
+
+	return spans, nil
+}
+
+// unescapeATT - expand C escapes like \n or \x01, \xHH is decoded as rune U+00HH
+func unescapeATT(s string) (string, error) {
+	var b strings.Builder
+
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '\\' || i+1 == len(runes) {
+			b.WriteRune(runes[i])
+			continue
+		}
+
+		i++
+
+		switch runes[i] {
+		case 'n':
+			b.WriteRune('\n')
+		case 't':
+			b.WriteRune('\t')
+		case 'r':
+			b.WriteRune('\r')
+		case 'f':
+			b.WriteRune('\f')
+		case 'v':
+			b.WriteRune('\v')
+		case 'a':
+			b.WriteRune('\a')
+		case 'e':
+			b.WriteRune(0x1B)
+		case 'x':
+			if i+2 >= len(runes) {
+				return "", fmt.Errorf("invalid escape in %q", s)
+			}
+
+			x, err := strconv.ParseUint(string(runes[i+1:i+3]), 16, 8)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteRune(rune(x))
+			i += 2
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,56 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+type (
+	// CPythonTest - test case of CPython test suite (Lib/test/re_tests.py),
+	// results are made by re.search (see testdata/cpython/generate.py)
+	CPythonTest struct {
+		Line    int
+		Pattern string
+		Input   string
+		Error   bool
+		NoMatch bool
+		Groups  []*CPythonSpan // match and groups, unset groups are nil
+		Names   map[string]int // numbers of named groups
+	}
+
+	// CPythonSpan - span of match with exclusive end
+	CPythonSpan [2]int
+)
+
+func (s *CPythonSpan) String() string {
+	if s == nil {
+		return "None"
+	}
+
+	return fmt.Sprintf("(%d, %d)", s[0], s[1])
+}
+
+func (x *CPythonTest) String() string {
+	return fmt.Sprintf("%d:%q:%q", x.Line, x.Pattern, x.Input)
+}
+
+// LoadCPythonFile - load tests generated from CPython test suite
+func LoadCPythonFile(t testing.TB, path string) ([]*CPythonTest, error) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tests []*CPythonTest
+
+	err = json.Unmarshal(data, &tests)
+	if err != nil {
+		return nil, err
+	}
+
+	return tests, nil
+}
@@ -2,26 +2,88 @@ package cliche
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/okneniz/cliche/buf"
+	unicodeEncoding "github.com/okneniz/cliche/encoding/unicode"
 	"github.com/okneniz/cliche/node"
 	"github.com/okneniz/cliche/scanner"
 )
 
+// Tree - trie of regular expressions, it's safe for concurrent use (see New)
 type Tree interface {
-	// Add - add regular expressions to tree
+	// Add - add regular expressions to tree,
+	// return *ExpressionError with index of expression which can't be parsed
 	Add(...string) error
+	// AddWithPayload - add regular expressions to tree with payload,
+	// payload returned in matches of this expressions and must be comparable
+	AddWithPayload(payload any, expressions ...string) error
+	// AddAll - add valid regular expressions to tree and return *AddAllError
+	// with errors of other expressions, in transactional mode
+	// expressions are added only if all of them are valid
+	AddAll(transactional bool, expressions ...string) error
+	// AddParsed - add already parsed regular expression to tree
+	// (like result of Parser.Parse or re2.FromSyntax),
+	// leafs of raw must contain expression
+	AddParsed(expression string, raw node.Alternation) error
+	// Remove - remove regular expressions from tree (with any payloads)
+	Remove(...string) error
 	// Size - return count of nodes in tree
 	Size() int
 	// String - dump tree to string
 	String() string
+	// MarshalBinary - serialize nodes of tree with expressions (see Load)
+	MarshalBinary() ([]byte, error)
 	// Match - scan text and return matches
 	Match(text string, options ...node.ScanOption) []*scanner.Match
+	// MatchFunc - scan text and pass matches to f, stop scanning when f return false
+	MatchFunc(text string, f func(*scanner.Match) bool, options ...node.ScanOption)
+	// MatchTo - scan text and pass matches to custom output
+	MatchTo(text string, output node.Output, options ...node.ScanOption)
+	// MatchContext - scan text until context is done or scanner made maxSteps steps
+	// (visits and matches of nodes, unlimited if maxSteps <= 0),
+	// return matches found before interruption and *InterruptError
+	MatchContext(
+		ctx context.Context,
+		text string,
+		maxSteps int,
+		options ...node.ScanOption,
+	) ([]*scanner.Match, error)
+	// MatchBytes - scan UTF-8 text without conversion to string,
+	// invalid bytes are decoded as U+FFFD or as raw bytes with node.ScanOptionRawBytes,
+	// substrings of matches are kept as in source bytes
+	MatchBytes(data []byte, options ...node.ScanOption) []*scanner.Match
+	// MatchBytesTo - scan UTF-8 text and pass matches to custom output
+	MatchBytesTo(data []byte, output node.Output, options ...node.ScanOption)
+	// MatchReader - scan text from reader without loading it whole to memory,
+	// match can't be longer than ReaderLookahead runes and look-behinds of any length
+	// can't see more than node.AnyLengthLookBehindSize runes before position,
+	// otherwise scanning is stopped with scanner.ErrWindowOverflow
+	MatchReader(r io.Reader, options ...node.ScanOption) ([]*scanner.Match, error)
+	// MatchReaderTo - scan text from reader and pass matches to custom output,
+	// on scanner.ErrWindowOverflow output can contain matches cut by the end of window
+	MatchReaderTo(r io.Reader, output node.Output, options ...node.ScanOption) error
 }
 
+const (
+	// ReaderLookahead - count of runes after current position
+	// which kept in memory by MatchReader
+	ReaderLookahead = 1 << 16
+
+	// minReaderHistory - count of runes before current position
+	// which kept in memory by MatchReader for anchors and word boundaries
+	minReaderHistory = 2
+)
+
 type Parser interface {
 	// Parse - parse regular expression and return node.Alternation as base type for it
 	Parse(string) (node.Alternation, error)
@@ -32,62 +94,260 @@ var (
 )
 
 type tree struct {
-	nodes  map[string]node.Node
-	parser Parser
+	// nodes - roots of tree, published map and its nodes are never changed,
+	// Add and Remove publish changed copy (see writer)
+	nodes      atomic.Pointer[map[string]node.Node]
+	mu         sync.Mutex // serializes changes of tree
+	parser     Parser
+	validators []node.Validator
 }
 
-// New - return Tree
-func New(parser Parser) Tree {
+// New - return Tree, validators are called for each added expression
+// after default checks, check of backtracking is opt-in (pass node.CheckBacktracking).
+//
+// Tree is safe for concurrent use: matching methods could be called concurrently
+// with each other and with Add, Remove and etc. Changes are made on copy of changed nodes
+// and published atomically after each call, so scanning which is already started
+// doesn't see them, next scanning sees all of them.
+func New(parser Parser, validators ...node.Validator) Tree {
 	tr := new(tree)
-	tr.nodes = make(map[string]node.Node)
+	tr.nodes.Store(&map[string]node.Node{})
 	tr.parser = parser
+	tr.validators = validators
 	return tr
 }
 
+// Load - return Tree with nodes serialized by Tree.MarshalBinary without parsing of expressions,
+// parser and validators are used for next changes of tree like in New
+func Load(r io.Reader, parser Parser, validators ...node.Validator) (Tree, error) {
+	nodes, err := node.UnmarshalNodes(r, unicodeEncoding.FromRangeTable)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make(map[string]node.Node, len(nodes))
+	for _, x := range nodes {
+		roots[x.GetKey()] = x
+	}
+
+	tr := New(parser, validators...).(*tree)
+	tr.nodes.Store(&roots)
+
+	return tr, nil
+}
+
+// Analyze - parse expression and find sub-expressions
+// with catastrophic backtracking (see node.Analyze)
+func Analyze(parser Parser, expression string) ([]node.Risk, error) {
+	raw, err := parser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Analyze(expression, foldCase(raw)), nil
+}
+
 // Add - add regular expressions to tree
 func (t *tree) Add(expressions ...string) error {
-	for _, expression := range expressions {
-		raw, err := t.parser.Parse(expression)
-		if err != nil {
-			return err
+	return t.update(func(w *writer) error {
+		for i, expression := range expressions {
+			if err := t.add(w, i, expression, nil); err != nil {
+				return err
+			}
 		}
 
-		if err := node.Validate(expression, raw); err != nil {
-			return err
+		return nil
+	})
+}
+
+// AddWithPayload - add regular expressions to tree with payload
+func (t *tree) AddWithPayload(payload any, expressions ...string) error {
+	if payload != nil && !reflect.ValueOf(payload).Comparable() {
+		return fmt.Errorf("payload must be comparable, actual %T", payload)
+	}
+
+	return t.update(func(w *writer) error {
+		for i, expression := range expressions {
+			if err := t.add(w, i, expression, payload); err != nil {
+				return err
+			}
 		}
 
-		for _, newNode := range node.Unify(raw) {
-			key := newNode.GetKey()
+		return nil
+	})
+}
+
+// AddParsed - add already parsed regular expression to tree
+func (t *tree) AddParsed(expression string, raw node.Alternation) error {
+	return t.update(func(w *writer) error {
+		return t.insert(w, expression, foldCase(raw), nil)
+	})
+}
+
+// AddAll - add valid regular expressions to tree
+func (t *tree) AddAll(transactional bool, expressions ...string) error {
+	parsed := make([]node.Alternation, len(expressions))
+	errs := make([]*ExpressionError, 0)
 
-			if oldNode, exists := t.nodes[key]; exists {
-				t.merge(oldNode, newNode)
-			} else {
-				t.nodes[key] = newNode
+	for i, expression := range expressions {
+		raw, err := t.parse(i, expression)
+		if err == nil {
+			err = t.validate(i, expression, raw)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		parsed[i] = raw
+	}
+
+	if len(errs) > 0 && transactional {
+		return &AddAllError{Errors: errs}
+	}
+
+	_ = t.update(func(w *writer) error {
+		for _, raw := range parsed {
+			if raw != nil {
+				w.put(raw)
 			}
 		}
+
+		return nil
+	})
+
+	if len(errs) > 0 {
+		return &AddAllError{Errors: errs}
+	}
+
+	return nil
+}
+
+func (t *tree) add(w *writer, index int, expression string, payload any) error {
+	raw, err := t.parse(index, expression)
+	if err != nil {
+		return err
+	}
+
+	return t.insert(w, expression, raw, payload)
+}
+
+// update - change copy of tree and publish it (even if f returns error,
+// so expressions added before error are kept like in previous versions)
+func (t *tree) update(f func(w *writer) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := newWriter(t.roots())
+	err := f(w)
+	t.nodes.Store(&w.nodes)
+
+	return err
+}
+
+// roots - published roots of tree, they are never changed
+func (t *tree) roots() map[string]node.Node {
+	return *t.nodes.Load()
+}
+
+func (t *tree) parse(index int, expression string) (node.Alternation, *ExpressionError) {
+	raw, err := t.parser.Parse(expression)
+	if err != nil {
+		return nil, &ExpressionError{
+			Index:      index,
+			Expression: expression,
+			Err:        err,
+		}
+	}
+
+	return foldCase(raw), nil
+}
+
+// foldCase - replace tables of classes under (?i) by tables closed under case folding,
+// so they are unified with the same classes and don't fold runes on scanning (see node.FoldCase)
+func foldCase(raw node.Alternation) node.Alternation {
+	return node.FoldCase(raw, unicodeEncoding.FoldTable)
+}
+
+func (t *tree) validate(index int, expression string, raw node.Alternation) *ExpressionError {
+	if err := t.check(expression, raw); err != nil {
+		return &ExpressionError{
+			Index:      index,
+			Expression: expression,
+			Err:        err,
+		}
+	}
+
+	return nil
+}
+
+// insert - validate parsed expression and merge it to tree
+func (t *tree) insert(w *writer, expression string, raw node.Alternation, payload any) error {
+	if err := t.check(expression, raw); err != nil {
+		return err
+	}
+
+	if payload != nil {
+		attachPayload(raw, expression, payload)
 	}
 
+	w.put(raw)
+
 	return nil
 }
 
-func (t *tree) merge(oldNode, newNode node.Node) {
-	for key, newNestedNode := range newNode.GetNestedNodes() {
-		if oldNestedNode, exists := oldNode.GetNestedNodes()[key]; exists {
-			t.merge(oldNestedNode, newNestedNode)
-		} else {
-			oldNode.GetNestedNodes()[key] = newNestedNode
+// check - call default checks and validators of tree
+func (t *tree) check(expression string, raw node.Alternation) error {
+	if err := node.Validate(expression, raw); err != nil {
+		return err
+	}
+
+	for _, validate := range t.validators {
+		if err := validate(expression, raw); err != nil {
+			return err
 		}
 	}
 
-	// only if oldNode.isLeaf()
-	newNode.GetExpressions().AddTo(oldNode.GetExpressions())
+	return nil
+}
+
+// attachPayload - replace expression added by parser to the same expression with payload
+func attachPayload(raw node.Node, expression string, payload any) {
+	plain := node.NewExpression(expression)
+	withPayload := node.NewExpressionWithPayload(expression, payload)
+
+	node.Traverse(raw, func(x node.Node) bool {
+		if x.IsLeaf() {
+			x.GetExpressions().Delete(plain)
+			x.AddExpression(withPayload)
+		}
+
+		return false
+	})
+}
+
+// Remove - remove regular expressions from tree
+func (t *tree) Remove(expressions ...string) error {
+	return t.update(func(w *writer) error {
+		for _, expression := range expressions {
+			raw, err := t.parser.Parse(expression)
+			if err != nil {
+				return err
+			}
+
+			w.delete(foldCase(raw), expression)
+		}
+
+		return nil
+	})
 }
 
 // Size - return count of nodes in tree
 func (t *tree) Size() int {
 	size := 0
 
-	for _, x := range t.nodes {
+	for _, x := range t.roots() {
 		node.Traverse(x, func(n node.Node) bool {
 			size++
 			return false
@@ -99,15 +359,128 @@ func (t *tree) Size() int {
 
 // Match - scan text and return matches
 func (t *tree) Match(text string, options ...node.ScanOption) []*scanner.Match {
+	output := scanner.NewOutput()
+	t.MatchTo(text, output, options...)
+	return output.Slice()
+}
+
+// MatchFunc - scan text and pass matches to f, stop scanning when f return false
+func (t *tree) MatchFunc(
+	text string,
+	f func(*scanner.Match) bool,
+	options ...node.ScanOption,
+) {
+	t.MatchTo(text, scanner.NewStreamOutput(f), options...)
+}
+
+// MatchTo - scan text and pass matches to custom output
+func (t *tree) MatchTo(
+	text string,
+	output node.Output,
+	options ...node.ScanOption,
+) {
 	input := buf.NewRunesBuffer(text)
+	scanner := scanner.NewFullScanner(input, output, t.roots(), options...)
+
+	scanner.Scan(0, input.Size())
+}
+
+// MatchContext - scan text until context is done or scanner made maxSteps steps
+func (t *tree) MatchContext(
+	ctx context.Context,
+	text string,
+	maxSteps int,
+	options ...node.ScanOption,
+) ([]*scanner.Match, error) {
 	output := scanner.NewOutput()
-	scanner := scanner.NewFullScanner(input, output, t.nodes, options...)
+	input := buf.NewRunesBuffer(text)
+	scanner := scanner.NewFullScanner(input, output, t.roots(), options...)
 
+	scanner.Limit(ctx, maxSteps)
 	scanner.Scan(0, input.Size())
 
+	matches := output.Slice()
+
+	if err := scanner.Err(); err != nil {
+		return matches, &InterruptError{
+			Err:     err,
+			Steps:   scanner.Steps(),
+			Matches: len(matches),
+		}
+	}
+
+	return matches, nil
+}
+
+// MatchBytes - scan UTF-8 text without conversion to string
+func (t *tree) MatchBytes(data []byte, options ...node.ScanOption) []*scanner.Match {
+	output := scanner.NewOutput()
+	t.MatchBytesTo(data, output, options...)
 	return output.Slice()
 }
 
+// MatchBytesTo - scan UTF-8 text and pass matches to custom output
+func (t *tree) MatchBytesTo(
+	data []byte,
+	output node.Output,
+	options ...node.ScanOption,
+) {
+	raw := slices.Contains(options, node.ScanOptionRawBytes)
+	input := buf.NewBytesBuffer(data, raw)
+	scanner := scanner.NewFullScanner(input, output, t.roots(), options...)
+
+	scanner.Scan(0, input.Size())
+}
+
+// MatchReader - scan text from reader without loading it whole to memory
+func (t *tree) MatchReader(
+	r io.Reader,
+	options ...node.ScanOption,
+) ([]*scanner.Match, error) {
+	output := scanner.NewOutput()
+
+	if err := t.MatchReaderTo(r, output, options...); err != nil {
+		return nil, err
+	}
+
+	return output.Slice(), nil
+}
+
+// MatchReaderTo - scan text from reader and pass matches to custom output
+func (t *tree) MatchReaderTo(
+	r io.Reader,
+	output node.Output,
+	options ...node.ScanOption,
+) error {
+	return t.matchReaderTo(r, output, ReaderLookahead, options...)
+}
+
+func (t *tree) matchReaderTo(
+	r io.Reader,
+	output node.Output,
+	lookahead int,
+	options ...node.ScanOption,
+) error {
+	// the same roots for size of history and scanning
+	roots := t.roots()
+
+	history := minReaderHistory
+	for _, x := range roots {
+		history = max(history, node.LookBehindSize(x)+minReaderHistory)
+	}
+
+	input := buf.NewReaderBuffer(r, history, lookahead)
+	scanner := scanner.NewFullScanner(input, output, roots, options...)
+
+	scanner.ScanWindow()
+
+	if err := input.Err(); err != nil {
+		return err
+	}
+
+	return scanner.Err()
+}
+
 func (t *tree) marshalJSON() ([]byte, error) {
 	data := bytes.NewBuffer(nil)
 
@@ -115,7 +488,7 @@ func (t *tree) marshalJSON() ([]byte, error) {
 	encoder.SetEscapeHTML(false)
 	encoder.SetIndent("", " ")
 
-	x := newViewsList(maps.Values(t.nodes))
+	x := newViewsList(maps.Values(t.roots()))
 
 	if err := encoder.Encode(x); err != nil {
 		return nil, err
@@ -124,6 +497,17 @@ func (t *tree) marshalJSON() ([]byte, error) {
 	return data.Bytes(), nil
 }
 
+// MarshalBinary - serialize nodes of tree with expressions (see Load)
+func (t *tree) MarshalBinary() ([]byte, error) {
+	data := bytes.NewBuffer(nil)
+
+	if err := node.MarshalNodes(data, maps.Values(t.roots())); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
 // String - dump tree to string
 func (t *tree) String() string {
 	data, err := t.marshalJSON()
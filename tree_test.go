@@ -1,18 +1,52 @@
 package cliche
 
 import (
+	"bytes"
+	"context"
+	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 
+	"github.com/okneniz/cliche/ecmascript"
+	"github.com/okneniz/cliche/node"
 	"github.com/okneniz/cliche/onigmo"
+	"github.com/okneniz/cliche/parser"
+	"github.com/okneniz/cliche/pcre"
+	"github.com/okneniz/cliche/posix"
+	"github.com/okneniz/cliche/python"
 	"github.com/okneniz/cliche/re2"
+	"github.com/okneniz/cliche/scanner"
 	tableTests "github.com/okneniz/cliche/testing"
 )
 
+func newECMAScriptParser(t *testing.T, flags string) Parser {
+	t.Helper()
+
+	p, err := ecmascript.New(flags)
+	require.NoError(t, err)
+
+	return p
+}
+
+func newPythonParser(t *testing.T, flags string) Parser {
+	t.Helper()
+
+	p, err := python.New(flags)
+	require.NoError(t, err)
+
+	return p
+}
+
 func TestTree_Match(t *testing.T) {
 	t.Parallel()
 
@@ -33,6 +67,81 @@ func TestTree_Match(t *testing.T) {
 			path:   "./testdata/re2/",
 			parser: re2.Parser,
 		},
+		{
+			name:   "posix",
+			path:   "./testdata/posix/",
+			parser: posix.ExtendedParser,
+		},
+		{
+			name:   "pcre",
+			path:   "./testdata/pcre/",
+			parser: pcre.Parser,
+		},
+		{
+			name:   "ecmascript",
+			path:   "./testdata/ecmascript/default/",
+			parser: ecmascript.DefaultParser,
+		},
+		{
+			name:   "ecmascript (u)",
+			path:   "./testdata/ecmascript/unicode/",
+			parser: newECMAScriptParser(t, "u"),
+		},
+		{
+			name:   "ecmascript (v)",
+			path:   "./testdata/ecmascript/sets/",
+			parser: newECMAScriptParser(t, "v"),
+		},
+		{
+			name:   "ecmascript (s)",
+			path:   "./testdata/ecmascript/dot_all/",
+			parser: newECMAScriptParser(t, "s"),
+		},
+		{
+			name:   "ecmascript (m)",
+			path:   "./testdata/ecmascript/multiline/",
+			parser: newECMAScriptParser(t, "m"),
+		},
+		{
+			name:   "ecmascript (i)",
+			path:   "./testdata/ecmascript/ignore_case/",
+			parser: newECMAScriptParser(t, "i"),
+		},
+		{
+			name:   "ecmascript (y)",
+			path:   "./testdata/ecmascript/sticky/",
+			parser: newECMAScriptParser(t, "y"),
+		},
+		{
+			name:   "python",
+			path:   "./testdata/python/default/",
+			parser: python.DefaultParser,
+		},
+		{
+			name:   "python (i)",
+			path:   "./testdata/python/ignore_case/",
+			parser: newPythonParser(t, "i"),
+		},
+		{
+			name:   "python (m)",
+			path:   "./testdata/python/multiline/",
+			parser: newPythonParser(t, "m"),
+		},
+		{
+			name:   "python (s)",
+			path:   "./testdata/python/dot_all/",
+			parser: newPythonParser(t, "s"),
+		},
+		{
+			name:   "python (a)",
+			path:   "./testdata/python/ascii/",
+			parser: newPythonParser(t, "a"),
+		},
+		{
+			name:   "python (x)",
+			path:   "./testdata/python/verbose/",
+			parser: newPythonParser(t, "x"),
+		},
 	}
 
 	for _, test := range tests {
@@ -203,3 +312,1184 @@ func TestTree_MatchErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestTree_Remove(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		name   string
+		add    []string
+		remove []string
+		input  string
+	}
+
+	tests := []test{
+		{
+			name:   "remove all",
+			add:    []string{"a[0-9]+", "b"},
+			remove: []string{"a[0-9]+", "b"},
+			input:  "a1 b",
+		},
+		{
+			name:   "remove branch with common prefix",
+			add:    []string{"abc", "abd", "ab"},
+			remove: []string{"abd"},
+			input:  "abc abd ab",
+		},
+		{
+			name:   "remove expression from shared leaf",
+			add:    []string{"a[0123-9]+", "a[01-5[67-9]]{1,}"},
+			remove: []string{"a[0123-9]+"},
+			input:  "a1 a23",
+		},
+		{
+			name:   "remove prefix of another expression",
+			add:    []string{"abc", "ab"},
+			remove: []string{"ab"},
+			input:  "abc ab",
+		},
+		{
+			name:   "remove alternation",
+			add:    []string{"(a|b)c", "x|y", "ac"},
+			remove: []string{"x|y", "(a|b)c"},
+			input:  "ac bc x y",
+		},
+		{
+			name:   "remove expression with folded class",
+			add:    []string{"(?i)[a-z]+", "(?i)[A-Za-z]+0", "x"},
+			remove: []string{"(?i)[a-z]+"},
+			input:  "Ab0 x",
+		},
+		{
+			name:   "remove not added expression",
+			add:    []string{"abc"},
+			remove: []string{"abd", "xyz"},
+			input:  "abc abd",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			removed := make(map[string]struct{}, len(test.remove))
+			for _, x := range test.remove {
+				removed[x] = struct{}{}
+			}
+
+			rest := make([]string, 0, len(test.add))
+			for _, x := range test.add {
+				if _, exists := removed[x]; !exists {
+					rest = append(rest, x)
+				}
+			}
+
+			tr := New(DefaultParser)
+			err := tr.Add(test.add...)
+			require.NoError(t, err)
+
+			err = tr.Remove(test.remove...)
+			require.NoError(t, err)
+
+			t.Logf("tree: %s", tr)
+
+			expected := New(DefaultParser)
+			err = expected.Add(rest...)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.Size(), tr.Size())
+
+			actualMatches := tableTests.TestMatchesToExpectations(tr.Match(test.input)...)
+			expectedMatches := tableTests.TestMatchesToExpectations(expected.Match(test.input)...)
+
+			for _, xs := range [][]*tableTests.Expectation{actualMatches, expectedMatches} {
+				for _, x := range xs {
+					x.Normalize()
+				}
+
+				sort.Slice(xs, func(i, j int) bool {
+					return xs[i].String() < xs[j].String()
+				})
+			}
+
+			require.Equal(t, expectedMatches, actualMatches)
+		})
+	}
+}
+
+func TestTree_AddWithPayload(t *testing.T) {
+	t.Parallel()
+
+	type rule struct {
+		ID       int
+		Severity string
+		Meta     any
+	}
+
+	tr := New(DefaultParser)
+
+	err := tr.AddWithPayload(rule{ID: 1, Severity: "low"}, "a[0-9]+")
+	require.NoError(t, err)
+
+	err = tr.AddWithPayload(rule{ID: 2, Severity: "high"}, "a[0-9]+", "b")
+	require.NoError(t, err)
+
+	err = tr.Add("a[0123-9]{1,}")
+	require.NoError(t, err)
+
+	err = tr.AddWithPayload([]int{1}, "c")
+	require.Error(t, err)
+
+	// comparable type with not comparable value
+	err = tr.AddWithPayload(rule{ID: 3, Meta: []string{"x"}}, "c")
+	require.Error(t, err)
+
+	t.Logf("tree: %s", tr)
+
+	matches := tr.Match("a1 b")
+	require.Len(t, matches, 2)
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Span().From() < matches[j].Span().From()
+	})
+
+	require.ElementsMatch(t, []string{"a[0-9]+", "a[0123-9]{1,}"}, matches[0].Expressions())
+	require.ElementsMatch(t, []any{rule{ID: 1, Severity: "low"}, rule{ID: 2, Severity: "high"}}, matches[0].Payloads())
+
+	require.ElementsMatch(t, []string{"b"}, matches[1].Expressions())
+	require.ElementsMatch(t, []any{rule{ID: 2, Severity: "high"}}, matches[1].Payloads())
+
+	err = tr.Remove("a[0-9]+")
+	require.NoError(t, err)
+
+	matches = tr.Match("a1")
+	require.Len(t, matches, 1)
+	require.ElementsMatch(t, []string{"a[0123-9]{1,}"}, matches[0].Expressions())
+	require.Empty(t, matches[0].Payloads())
+}
+
+func TestTree_AddParsed(t *testing.T) {
+	t.Parallel()
+
+	errForbidden := errors.New("forbidden")
+
+	tr := New(DefaultParser, func(expression string, _ node.Node) error {
+		if expression == "c" {
+			return errForbidden
+		}
+
+		return nil
+	})
+
+	raw, err := DefaultParser.Parse("a[0-9]+")
+	require.NoError(t, err)
+
+	err = tr.AddParsed("a[0-9]+", raw)
+	require.NoError(t, err)
+
+	err = tr.Add("a[0-9]+", "b")
+	require.NoError(t, err)
+
+	size := tr.Size()
+
+	raw, err = DefaultParser.Parse("a[0-9]+")
+	require.NoError(t, err)
+
+	err = tr.AddParsed("a[0-9]+", raw)
+	require.NoError(t, err)
+	require.Equal(t, size, tr.Size())
+
+	matches := tr.Match("a1 b")
+	require.Len(t, matches, 2)
+
+	// validators are called like for parsed expressions
+	raw, err = DefaultParser.Parse("c")
+	require.NoError(t, err)
+
+	err = tr.AddParsed("c", raw)
+	require.ErrorIs(t, err, errForbidden)
+
+	err = tr.Remove("a[0-9]+")
+	require.NoError(t, err)
+
+	matches = tr.Match("a1 b")
+	require.Len(t, matches, 1)
+	require.ElementsMatch(t, []string{"b"}, matches[0].Expressions())
+}
+
+func TestTree_AddParseError(t *testing.T) {
+	t.Parallel()
+
+	tr := New(DefaultParser)
+
+	err := tr.Add("a", "b|c", "é||d")
+	require.Error(t, err)
+
+	var exprErr *ExpressionError
+	require.ErrorAs(t, err, &exprErr)
+	require.Equal(t, 2, exprErr.Index)
+	require.Equal(t, "é||d", exprErr.Expression)
+
+	var parseErr *parser.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "é||d", parseErr.Expression)
+	require.Equal(t, 2, parseErr.Offset)
+	require.Equal(t, 3, parseErr.ByteOffset)
+	require.Equal(t, "é||d\n  ^", parseErr.Snippet())
+
+	require.Equal(t,
+		"expression 2: can't parse /é||d/ at 2: unexpected '|', expected group, class, character",
+		err.Error(),
+	)
+
+	// expressions before invalid one are added
+	require.Len(t, tr.Match("a b"), 2)
+}
+
+func TestTree_AddAll(t *testing.T) {
+	t.Parallel()
+
+	errForbidden := errors.New("forbidden")
+
+	newTree := func() Tree {
+		return New(DefaultParser, func(expression string, _ node.Node) error {
+			if expression == "c" {
+				return errForbidden
+			}
+
+			return nil
+		})
+	}
+
+	expressions := []string{"a", "b||", "c", "[]", "d+"}
+
+	type test struct {
+		name          string
+		transactional bool
+		matches       []string
+	}
+
+	tests := []test{
+		{
+			name:          "all valid expressions are added",
+			transactional: false,
+			matches:       []string{"a", "dd"},
+		},
+		{
+			name:          "nothing is added in transactional mode",
+			transactional: true,
+			matches:       []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tr := newTree()
+
+			err := tr.AddAll(test.transactional, expressions...)
+			require.Error(t, err)
+
+			var addErr *AddAllError
+			require.ErrorAs(t, err, &addErr)
+			require.Len(t, addErr.Errors, 3)
+
+			failed := make(map[int]string)
+			for _, x := range addErr.Errors {
+				failed[x.Index] = x.Expression
+			}
+
+			require.Equal(t, map[int]string{1: "b||", 2: "c", 3: "[]"}, failed)
+
+			var parseErr *parser.ParseError
+			require.ErrorAs(t, addErr.Errors[0], &parseErr)
+			require.Equal(t, 2, parseErr.Offset)
+
+			require.ErrorIs(t, err, errForbidden)
+			require.ErrorIs(t, addErr.Errors[1], errForbidden)
+			require.EqualError(t, addErr.Errors[2].Err, "empty char-class: /[]/")
+
+			matched := make([]string, 0)
+			for _, m := range tr.Match("a b c dd") {
+				matched = append(matched, m.SubString())
+			}
+
+			require.ElementsMatch(t, test.matches, matched)
+		})
+	}
+
+	tr := newTree()
+
+	err := tr.AddAll(true, "a", "d+")
+	require.NoError(t, err)
+	require.Len(t, tr.Match("a dd"), 2)
+}
+
+func TestTree_ConcurrentMatch(t *testing.T) {
+	t.Parallel()
+
+	tr := New(DefaultParser)
+
+	err := tr.Add(
+		`a[0-9]+`,
+		`(?<x>b+)\k<x>`,
+		`(?i)c(?=d)`,
+		`(?<=e)f*?`,
+		`(g|h)\1`,
+	)
+	require.NoError(t, err)
+
+	text := "a12 bbbb Cd ef gg hh"
+	expected := tr.Match(text)
+	require.NotEmpty(t, expected)
+
+	errs := make(chan error, 8)
+
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			for j := 0; j < 50; j++ {
+				actual := tr.Match(text)
+
+				if len(actual) != len(expected) {
+					errs <- fmt.Errorf("expected %d matches, actual %d", len(expected), len(actual))
+					return
+				}
+			}
+
+			errs <- nil
+		}()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+}
+
+func TestTree_ConcurrentAdd(t *testing.T) {
+	t.Parallel()
+
+	tr := New(DefaultParser)
+
+	base := []string{`a[0-9]+`, `(b|c)d`}
+	require.NoError(t, tr.Add(base...))
+
+	text := "a1 bd w1 w2 w3"
+	done := make(chan struct{})
+	errs := make(chan error, 4)
+
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			for {
+				select {
+				case <-done:
+					errs <- nil
+					return
+				default:
+				}
+
+				matched := make(map[string]bool)
+				for _, m := range tr.Match(text) {
+					for _, x := range m.Expressions() {
+						matched[x] = true
+					}
+				}
+
+				// expressions which are not changed are matched during updates
+				for _, x := range base {
+					if !matched[x] {
+						errs <- fmt.Errorf("expression %s isn't matched", x)
+						return
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		expression := fmt.Sprintf("w%d", i%4)
+
+		require.NoError(t, tr.Add(expression, "a[0-9]"))
+		require.NoError(t, tr.AddWithPayload(i, `(b|c)d`))
+		require.NoError(t, tr.Remove(expression, "a[0-9]"))
+	}
+
+	close(done)
+
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+
+	matched := make([]string, 0)
+	for _, m := range tr.Match(text) {
+		matched = append(matched, m.Expressions()...)
+	}
+
+	require.ElementsMatch(t, base, matched)
+}
+
+func TestTree_AddCopyOnWrite(t *testing.T) {
+	t.Parallel()
+
+	tr := New(DefaultParser)
+	require.NoError(t, tr.Add("ab", "(x|y)z"))
+
+	before := tr.String()
+
+	// matches found by scanning of the old tree aren't changed by next changes
+	var matches []*scanner.Match
+
+	tr.MatchFunc("abc xz", func(m *scanner.Match) bool {
+		matches = append(matches, m)
+
+		require.NoError(t, tr.Add("abc", "(x|y)zz"))
+		require.NoError(t, tr.Remove("ab"))
+
+		return true
+	})
+
+	require.Len(t, matches, 2)
+	require.NotEqual(t, before, tr.String())
+
+	actual := make([]string, 0)
+	for _, m := range tr.Match("abc xz") {
+		actual = append(actual, m.Expressions()...)
+	}
+
+	require.ElementsMatch(t, []string{"abc", "(x|y)z"}, actual)
+}
+
+type binaryPayload struct {
+	ID int
+}
+
+func TestTree_MarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	gob.Register(binaryPayload{})
+
+	type test struct {
+		name        string
+		parser      Parser
+		expressions []string
+		text        string
+	}
+
+	tests := []test{
+		{
+			name:   "onigmo",
+			parser: onigmo.Parser,
+			expressions: []string{
+				`a[0-9]+?`,
+				`\p{Greek}+`,
+				`(?<x>b)\k<x>|(c)\1`,
+				`(?i)d(?=e)`,
+				`(?<=f)g*+`,
+				`(h)?(?(1)i|j)`,
+				`(?>k|kl)m`,
+				`\Ao\b|p$\z`,
+				`q(?#comment)r{2,3}`,
+				`[^s-u]\K.`,
+				`(?i)[t-u]+[^a]`,
+			},
+			text: "a12 αβγ bb cc DE fggg hi j klm op qrrr vw TUb",
+		},
+		{
+			name:        "pcre",
+			parser:      pcre.Parser,
+			expressions: []string{`(?<x>a)?(?(<x>)b|c)`, `(d)(?(1)e)`, `(?<!f)g`},
+			text:        "ab c de g fg",
+		},
+		{
+			name:        "ecmascript",
+			parser:      newECMAScriptParser(t, "u"),
+			expressions: []string{`(?<=a+)b`, `(?<!c.*)d`, `\p{Lu}+`},
+			text:        "aab cxd d ABC",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tr := New(test.parser)
+			require.NoError(t, tr.Add(test.expressions...))
+			require.NoError(t, tr.AddWithPayload(binaryPayload{ID: 1}, test.expressions[0]))
+
+			data, err := tr.MarshalBinary()
+			require.NoError(t, err)
+
+			loaded, err := Load(bytes.NewReader(data), test.parser)
+			require.NoError(t, err)
+
+			require.Equal(t, treePaths(tr), treePaths(loaded))
+			require.Equal(t, tr.Size(), loaded.Size())
+
+			expected := tr.Match(test.text)
+			require.NotEmpty(t, expected)
+			require.ElementsMatch(t, matchViews(expected), matchViews(loaded.Match(test.text)))
+
+			// loaded tree can be changed by parser
+			require.NoError(t, loaded.Add("xyz"))
+			require.Contains(t, matchViews(loaded.Match("xyz")), "xyz [0-2] [xyz] [] []")
+		})
+	}
+}
+
+func TestTree_MarshalBinaryErrors(t *testing.T) {
+	t.Parallel()
+
+	tr := New(DefaultParser)
+
+	custom := node.NewGuard(
+		node.NewPredicate("custom", func(node.Scanner) bool { return true }),
+		node.NewDot(),
+	)
+	custom.AddExpression(node.NewExpression("custom"))
+
+	err := tr.AddParsed("custom", node.NewAlternation([]node.Node{custom}))
+	require.NoError(t, err)
+
+	_, err = tr.MarshalBinary()
+	require.ErrorIs(t, err, node.ErrNoBinary)
+
+	tr = New(DefaultParser)
+	require.NoError(t, tr.Add("a"))
+
+	data, err := tr.MarshalBinary()
+	require.NoError(t, err)
+
+	data[len("cliche")] = node.BinaryVersion + 1
+
+	_, err = Load(bytes.NewReader(data), DefaultParser)
+	require.ErrorIs(t, err, node.ErrBinaryVersion)
+
+	_, err = Load(strings.NewReader("text"), DefaultParser)
+	require.Error(t, err)
+}
+
+// treePaths - sorted paths of keys from roots to leafs with expressions
+func treePaths(tr Tree) []string {
+	paths := make([]string, 0)
+
+	var walk func(path string, n node.Node)
+	walk = func(path string, n node.Node) {
+		path += "/" + n.GetKey()
+
+		if n.IsLeaf() {
+			expressions := make([]string, 0)
+			for _, x := range n.GetExpressions().Slice() {
+				expressions = append(expressions, fmt.Sprintf("%s:%v", x.Source, x.Payload))
+			}
+
+			sort.Strings(expressions)
+			paths = append(paths, fmt.Sprintf("%s %v", path, expressions))
+		}
+
+		for _, nested := range n.GetNestedNodes() {
+			walk(path, nested)
+		}
+	}
+
+	for _, x := range tr.(*tree).roots() {
+		walk("", x)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// matchViews - comparable representation of matches
+func matchViews(matches []*scanner.Match) []string {
+	views := make([]string, 0, len(matches))
+
+	for _, m := range matches {
+		expressions := m.Expressions()
+		sort.Strings(expressions)
+
+		views = append(views, fmt.Sprintf(
+			"%s %v %v %v %v",
+			m.SubString(),
+			m.Span(),
+			expressions,
+			m.Groups(),
+			m.Payloads(),
+		))
+	}
+
+	return views
+}
+
+func TestTree_MatchFunc(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		name        string
+		expressions []string
+		input       string
+	}
+
+	tests := []test{
+		{
+			name:        "quantifiers",
+			expressions: []string{"a[0-9]+", "a[01-5[67-9]]{1,}", "[0-9]"},
+			input:       "Text with a1, b, c32, a100.",
+		},
+		{
+			name:        "common prefix",
+			expressions: []string{"ab", "abc", "b.?"},
+			input:       "abc abd ab",
+		},
+		{
+			name:        "groups",
+			expressions: []string{"(a|b)+c", "(?<x>c)"},
+			input:       "abc bc ac",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tr := New(DefaultParser)
+			err := tr.Add(test.expressions...)
+			require.NoError(t, err)
+
+			streamed := make([]*scanner.Match, 0)
+			tr.MatchFunc(test.input, func(m *scanner.Match) bool {
+				streamed = append(streamed, m)
+				return true
+			})
+
+			require.Equal(t, normalizeMatches(tr.Match(test.input)), normalizeMatches(streamed))
+
+			count := 0
+			tr.MatchFunc(test.input, func(m *scanner.Match) bool {
+				count++
+				return false
+			})
+
+			require.Equal(t, 1, count)
+		})
+	}
+
+	t.Run("stop inside of visit", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(DefaultParser)
+		err := tr.Add("a", "a(a|aa)*b")
+		require.NoError(t, err)
+
+		input := "a-" + strings.Repeat("a", 50)
+		done := make(chan int)
+
+		go func() {
+			count := 0
+			tr.MatchFunc(input, func(m *scanner.Match) bool {
+				count++
+				return false
+			})
+			done <- count
+		}()
+
+		select {
+		case count := <-done:
+			require.Equal(t, 1, count)
+		case <-time.After(10 * time.Second):
+			require.Fail(t, "scanning isn't stopped after the first match")
+		}
+	})
+}
+
+func TestTree_MatchReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("testdata", func(t *testing.T) {
+		t.Parallel()
+
+		requireSameAsMatch(t, func(tr Tree, input string, options ...node.ScanOption) ([]*scanner.Match, error) {
+			return tr.MatchReader(strings.NewReader(input), options...)
+		})
+	})
+
+	t.Run("small window", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(onigmo.Parser)
+		err := tr.Add(
+			`(?<=\d)[a-z]+`,
+			`\b\w+\b`,
+			`^foo$`,
+			`(a)\1`,
+		)
+		require.NoError(t, err)
+
+		text := strings.Repeat("1abc aa foo\nfoo\n22 zz\n", 100)
+
+		output := scanner.NewOutput()
+		err = tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8)
+		require.NoError(t, err)
+
+		require.Equal(t, normalizeMatches(tr.Match(text)), normalizeMatches(output.Slice()))
+	})
+
+	t.Run("window boundary", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(onigmo.Parser)
+		err := tr.Add(`a\z`, `a$`, `x\w+`)
+		require.NoError(t, err)
+
+		// anchors don't match at the end of window
+		text := strings.Repeat("a", 30)
+
+		output := scanner.NewOutput()
+		err = tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8)
+		require.NoError(t, err)
+
+		require.Equal(t, normalizeMatches(tr.Match(text)), normalizeMatches(output.Slice()))
+
+		// the end of window is the end of reader
+		text = "xabcdefg"
+
+		output = scanner.NewOutput()
+		err = tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8)
+		require.NoError(t, err)
+
+		require.Equal(t, normalizeMatches(tr.Match(text)), normalizeMatches(output.Slice()))
+
+		// match is longer than window
+		text = "aa x" + strings.Repeat("b", 20) + " xb"
+
+		output = scanner.NewOutput()
+		err = tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8)
+		require.ErrorIs(t, err, scanner.ErrWindowOverflow)
+	})
+
+	t.Run("look-behind of any length", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(ecmascript.DefaultParser)
+		err := tr.Add(`(?<=a\w*)b`)
+		require.NoError(t, err)
+
+		// text before position is kept in window
+		text := strings.Repeat("ab ", node.AnyLengthLookBehindSize/4)
+
+		output := scanner.NewOutput()
+		err = tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8)
+		require.NoError(t, err)
+
+		require.Equal(t, normalizeMatches(tr.Match(text)), normalizeMatches(output.Slice()))
+
+		// look-behind needs runes before window
+		text = "a" + strings.Repeat("x", 2*node.AnyLengthLookBehindSize) + "b"
+
+		output = scanner.NewOutput()
+		err = tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8)
+		require.ErrorIs(t, err, scanner.ErrWindowOverflow)
+	})
+
+	t.Run("reader error", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(onigmo.Parser)
+		err := tr.Add("a")
+		require.NoError(t, err)
+
+		r := io.MultiReader(strings.NewReader("aaa"), iotest.ErrReader(io.ErrUnexpectedEOF))
+
+		_, err = tr.MatchReader(r)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestTree_MatchLocations(t *testing.T) {
+	t.Parallel()
+
+	tr := New(DefaultParser)
+	err := tr.Add(`(?<word>[а-яa-z]+)(\d)`, `\n`)
+	require.NoError(t, err)
+
+	text := "эж1 \xffab2\nпривет3 x"
+
+	check := func(t *testing.T, matches []*scanner.Match) {
+		t.Helper()
+
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].Span().From() < matches[j].Span().From()
+		})
+
+		type position struct {
+			subString string
+			start     node.Position
+			end       node.Position
+		}
+
+		expected := []position{
+			{"эж1", node.Position{Offset: 0, Line: 1, Column: 1}, node.Position{Offset: 5, Line: 1, Column: 4}},
+			{"ab2", node.Position{Offset: 7, Line: 1, Column: 6}, node.Position{Offset: 10, Line: 1, Column: 9}},
+			{"\n", node.Position{Offset: 10, Line: 1, Column: 9}, node.Position{Offset: 11, Line: 2, Column: 1}},
+			{"привет3", node.Position{Offset: 11, Line: 2, Column: 1}, node.Position{Offset: 24, Line: 2, Column: 8}},
+		}
+
+		require.Len(t, matches, len(expected))
+
+		for i, m := range matches {
+			location, ok := m.Location()
+			require.True(t, ok)
+
+			require.Equal(t, expected[i].subString, m.SubString())
+			require.Equal(t, expected[i].start, location.Start)
+			require.Equal(t, expected[i].end, location.End)
+			require.Equal(t, m.SubString(), text[location.Start.Offset:location.End.Offset])
+
+			require.Len(t, m.GroupsLocations(), len(m.Groups()))
+
+			for _, g := range m.GroupsLocations() {
+				require.LessOrEqual(t, location.Start.Offset, g.Start.Offset)
+				require.GreaterOrEqual(t, location.End.Offset, g.End.Offset)
+			}
+
+			if word, ok := m.NamedGroupsLocations()["word"]; ok {
+				require.Equal(t, location.Start, word.Start)
+				require.Equal(t, m.SubString()[:len(m.SubString())-1], text[word.Start.Offset:word.End.Offset])
+			}
+		}
+	}
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+
+		check(t, tr.Match(text, node.ScanOptionLocations))
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+
+		output := scanner.NewOutput()
+		err := tr.(*tree).matchReaderTo(strings.NewReader(text), output, 8, node.ScanOptionLocations)
+		require.NoError(t, err)
+
+		check(t, output.Slice())
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		t.Parallel()
+
+		check(t, tr.MatchBytes([]byte(text), node.ScanOptionLocations))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		for _, m := range tr.Match(text) {
+			_, ok := m.Location()
+			require.False(t, ok)
+		}
+	})
+}
+
+func TestTree_MatchBytes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("testdata", func(t *testing.T) {
+		t.Parallel()
+
+		requireSameAsMatch(t, func(tr Tree, input string, options ...node.ScanOption) ([]*scanner.Match, error) {
+			return tr.MatchBytes([]byte(input), options...), nil
+		})
+	})
+
+	t.Run("long multibyte text", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(onigmo.Parser)
+		err := tr.Add(
+			`(?<=\d)[а-я]+`,
+			`\b\w+\b`,
+			`^тест$`,
+			`(ж)\1`,
+		)
+		require.NoError(t, err)
+
+		text := strings.Repeat("1абв жж тест\nтест\n22 zz\n", 100)
+
+		expected := tr.Match(text)
+		actual := tr.MatchBytes([]byte(text))
+
+		require.Equal(t, normalizeMatches(expected), normalizeMatches(actual))
+	})
+
+	t.Run("invalid utf-8", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(onigmo.Parser)
+		err := tr.Add(`a.b`, `\uFFFD`, `[\uDCFE\uDCFF]+`)
+		require.NoError(t, err)
+
+		data := []byte("a\xffb \xfe\xff")
+
+		subStrings := func(ms []*scanner.Match) []string {
+			xs := make([]string, 0, len(ms))
+			for _, m := range ms {
+				x := fmt.Sprintf(
+					"%s:%d-%d:%q",
+					m.Expressions()[0],
+					m.Span().From(),
+					m.Span().To(),
+					m.SubString(),
+				)
+
+				xs = append(xs, x)
+			}
+
+			sort.Strings(xs)
+			return xs
+		}
+
+		require.Equal(t, []string{
+			`\uFFFD:1-1:"\xff"`,
+			`\uFFFD:4-4:"\xfe"`,
+			`\uFFFD:5-5:"\xff"`,
+			`a.b:0-2:"a\xffb"`,
+		}, subStrings(tr.MatchBytes(data)))
+
+		require.Equal(t, []string{
+			`[\uDCFE\uDCFF]+:1-1:"\xff"`,
+			`[\uDCFE\uDCFF]+:4-5:"\xfe\xff"`,
+			`a.b:0-2:"a\xffb"`,
+		}, subStrings(tr.MatchBytes(data, node.ScanOptionRawBytes)))
+	})
+}
+
+func TestTree_MatchContext(t *testing.T) {
+	t.Parallel()
+
+	tr := New(onigmo.Parser)
+	err := tr.Add(`(a+)+b`)
+	require.NoError(t, err)
+
+	text := "ab aab " + strings.Repeat("a", 40)
+
+	t.Run("steps limit", func(t *testing.T) {
+		t.Parallel()
+
+		matches, err := tr.MatchContext(context.Background(), text, 100_000)
+		require.ErrorIs(t, err, scanner.ErrStepsLimit)
+
+		var interrupt *InterruptError
+		require.ErrorAs(t, err, &interrupt)
+		require.Equal(t, 2, interrupt.Matches)
+		require.Greater(t, interrupt.Steps, 100_000)
+
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].Span().From() < matches[j].Span().From()
+		})
+
+		require.Len(t, matches, 2)
+		require.Equal(t, "ab", matches[0].SubString())
+		require.Equal(t, "aab", matches[1].SubString())
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := tr.MatchContext(ctx, text, 0)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("canceled context with short text", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		matches, err := tr.MatchContext(ctx, "ab", 0)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Empty(t, matches)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := tr.MatchContext(ctx, text, 0)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("not interrupted", func(t *testing.T) {
+		t.Parallel()
+
+		text := "ab aab aaab"
+
+		matches, err := tr.MatchContext(context.Background(), text, 100_000)
+		require.NoError(t, err)
+		require.ElementsMatch(t, tr.Match(text), matches)
+	})
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		expression string
+		risks      []node.RiskKind
+	}
+
+	tests := []test{
+		{expression: `(a+)+b`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `(a*)*b`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `(\w+\s?)+$`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `(a?){1,}`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `(a|aa)+`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `(\w|\d)*x`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `(?:[a-z]+[0-9]*)+`, risks: []node.RiskKind{node.RiskExponential}},
+		{expression: `\d+\d+`, risks: []node.RiskKind{node.RiskPolynomial}},
+		{expression: `.*.*=.*`, risks: []node.RiskKind{node.RiskPolynomial}},
+		{expression: `(a+b)+`},
+		{expression: `(ab+)+c`},
+		{expression: `(a{2})+`},
+		{expression: `(?>a+)+`},
+		{expression: `(a|b)+`},
+		{expression: `\d+-\d+`},
+		{expression: `[a-z]+\d+`},
+		{expression: `.*foo.*`},
+		{expression: `(a+){3}`, risks: []node.RiskKind{node.RiskPolynomial}},
+		{expression: `(?:\w+\s?){2,4}`, risks: []node.RiskKind{node.RiskPolynomial}},
+		{expression: `(a+b){3}`},
+		{expression: `(a{2,5}){3}`},
+		{expression: `(?>a+){3}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			t.Parallel()
+
+			risks, err := Analyze(onigmo.Parser, test.expression)
+			require.NoError(t, err)
+
+			kinds := make([]node.RiskKind, 0, len(risks))
+			for _, risk := range risks {
+				require.Equal(t, test.expression, risk.Expression)
+				require.NotEmpty(t, risk.SubExpression)
+
+				if !slices.Contains(kinds, risk.Kind) {
+					kinds = append(kinds, risk.Kind)
+				}
+			}
+
+			require.ElementsMatch(t, test.risks, kinds)
+		})
+	}
+
+	t.Run("sub-expressions", func(t *testing.T) {
+		t.Parallel()
+
+		tests := map[string]string{
+			`(a+)+b`:            `a+`,
+			`(a|aa)+`:           `(?:a|aa)`,
+			`(?:[a-z]+[0-9]*)+`: `[a-z]+`,
+			`.*.*=.*`:           `.*`,
+			`(a+){3}`:           `a+`,
+			`(\w+\s?)+$`:        `[0-9A-Z_a-z…]+`,
+		}
+
+		for expression, expected := range tests {
+			risks, err := Analyze(onigmo.Parser, expression)
+			require.NoError(t, err)
+			require.NotEmpty(t, risks, expression)
+			require.Equal(t, expected, risks[0].SubExpression, expression)
+		}
+	})
+
+	t.Run("reject on add", func(t *testing.T) {
+		t.Parallel()
+
+		tr := New(onigmo.Parser, node.CheckBacktracking)
+
+		err := tr.Add(`(a+b)+`, `(x+x+)+y`)
+
+		var risk node.Risk
+		require.ErrorAs(t, err, &risk)
+		require.Equal(t, node.RiskExponential, risk.Kind)
+		require.Equal(t, `(x+x+)+y`, risk.Expression)
+		require.Equal(t, `x+`, risk.SubExpression)
+
+		require.Len(t, tr.Match("aab"), 1)
+	})
+}
+
+// normalizeMatches - convert matches to sorted expectations,
+// expressions of matches with the same bounds are merged
+func normalizeMatches(ms []*scanner.Match) []*tableTests.Expectation {
+	byKey := make(map[string]*tableTests.Expectation)
+
+	for _, x := range tableTests.TestMatchesToExpectations(ms...) {
+		if prev, exists := byKey[x.String()]; exists {
+			prev.Expressions = append(prev.Expressions, x.Expressions...)
+		} else {
+			byKey[x.String()] = x
+		}
+	}
+
+	result := make([]*tableTests.Expectation, 0, len(byKey))
+	for _, x := range byKey {
+		x.Normalize()
+		result = append(result, x)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+
+	return result
+}
+
+// requireSameAsMatch - check that match returns the same matches
+// as Tree.Match for examples from onigmo testdata
+func requireSameAsMatch(
+	t *testing.T,
+	match func(tr Tree, input string, options ...node.ScanOption) ([]*scanner.Match, error),
+) {
+	t.Helper()
+
+	files, err := tableTests.LoadAllTestFiles(t, "./testdata/onigmo/")
+	require.NoError(t, err)
+
+	for _, file := range files {
+		for i, example := range file.Tests {
+			name := fmt.Sprintf("%s_%d_%s", file.Name, i, example.Name)
+
+			tr := New(onigmo.Parser)
+			err := tr.Add(example.Expressions...)
+			require.NoError(t, err, name)
+
+			options, err := tableTests.ToScanOptions(example.Options...)
+			require.NoError(t, err, name)
+
+			actual, err := match(tr, example.Input, options...)
+			require.NoError(t, err, name)
+
+			expected := tr.Match(example.Input, options...)
+
+			require.Equal(t, normalizeMatches(expected), normalizeMatches(actual), name)
+		}
+	}
+}
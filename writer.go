@@ -0,0 +1,120 @@
+package cliche
+
+import (
+	"github.com/okneniz/cliche/node"
+)
+
+// writer - copy-on-write changes of tree: nodes which could be visited by scanners
+// are copied before changes, copies and new nodes are changed in place
+type writer struct {
+	nodes map[string]node.Node
+	owned map[node.Node]struct{}
+}
+
+func newWriter(roots map[string]node.Node) *writer {
+	w := new(writer)
+	w.nodes = make(map[string]node.Node, len(roots))
+	w.owned = make(map[node.Node]struct{})
+
+	for key, x := range roots {
+		w.nodes[key] = x
+	}
+
+	return w
+}
+
+// own - return node which could be changed by writer
+func (w *writer) own(n node.Node) node.Node {
+	if _, exists := w.owned[n]; exists {
+		return n
+	}
+
+	x := n.Copy()
+
+	for key, nested := range n.GetNestedNodes() {
+		x.GetNestedNodes()[key] = nested
+	}
+
+	n.GetExpressions().AddTo(x.GetExpressions())
+
+	w.owned[x] = struct{}{}
+
+	return x
+}
+
+// put - merge parsed expression to tree
+func (w *writer) put(raw node.Alternation) {
+	for _, newNode := range node.Unify(raw) {
+		key := newNode.GetKey()
+
+		if oldNode, exists := w.nodes[key]; exists {
+			w.nodes[key] = w.merge(oldNode, newNode)
+		} else {
+			w.owned[newNode] = struct{}{}
+			w.nodes[key] = newNode
+		}
+	}
+}
+
+// merge - return oldNode (or its copy) with nested nodes and expressions of newNode
+func (w *writer) merge(oldNode, newNode node.Node) node.Node {
+	result := w.own(oldNode)
+
+	for key, newNestedNode := range newNode.GetNestedNodes() {
+		if oldNestedNode, exists := result.GetNestedNodes()[key]; exists {
+			result.GetNestedNodes()[key] = w.merge(oldNestedNode, newNestedNode)
+		} else {
+			result.GetNestedNodes()[key] = newNestedNode
+		}
+	}
+
+	// only if oldNode.isLeaf()
+	newNode.GetExpressions().AddTo(result.GetExpressions())
+
+	return result
+}
+
+// delete - remove expression from tree by path of parsed expression
+func (w *writer) delete(raw node.Alternation, expression string) {
+	for _, newNode := range node.Unify(raw) {
+		key := newNode.GetKey()
+
+		oldNode, exists := w.nodes[key]
+		if !exists {
+			continue
+		}
+
+		if x, empty := w.remove(oldNode, newNode, expression); empty {
+			delete(w.nodes, key)
+		} else {
+			w.nodes[key] = x
+		}
+	}
+}
+
+// remove - remove expression from oldNode (or its copy) by path of newNode,
+// return true if node has no expressions and nested nodes anymore
+func (w *writer) remove(oldNode, newNode node.Node, expression string) (node.Node, bool) {
+	result := w.own(oldNode)
+
+	for key, newNestedNode := range newNode.GetNestedNodes() {
+		oldNestedNode, exists := result.GetNestedNodes()[key]
+		if !exists {
+			continue
+		}
+
+		if x, empty := w.remove(oldNestedNode, newNestedNode, expression); empty {
+			delete(result.GetNestedNodes(), key)
+		} else {
+			result.GetNestedNodes()[key] = x
+		}
+	}
+
+	for _, x := range result.GetExpressions().Slice() {
+		if x.Source == expression {
+			result.GetExpressions().Delete(x)
+		}
+	}
+
+	return result, !result.IsLeaf() && len(result.GetNestedNodes()) == 0
+}